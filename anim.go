@@ -5,11 +5,15 @@ package ultima
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"image"
+	"io"
 	"iter"
+	"slices"
 
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
 	"github.com/kelindar/ultima-sdk/internal/uofile"
 )
 
@@ -54,133 +58,290 @@ func (a *Animation) Frames() iter.Seq[AnimationFrame] {
 }
 
 // Animation loads animation frames for a given body, action, direction, and hue.
+// The hue is an index as accepted by Hue (0 leaving the frames unchanged), with the
+// 0x8000 bit set to only recolor grayscale pixels, as the client does. Bodies which
+// are substituted through body.def take the hue of their substitute, unless a hue is
+// given or preserveHue is set.
 func (s *SDK) Animation(body, action, direction, hue int, preserveHue, firstFrame bool) (*Animation, error) {
-	// Defensive checks for invalid indices using switch { case }
-	switch {
-	case body < 0 || body > 10000:
-		return nil, fmt.Errorf("Animation: invalid body index: %d", body)
-	case action < 0 || action > 1000:
-		return nil, fmt.Errorf("Animation: invalid action index: %d", action)
-	case direction < 0 || direction > 7:
-		return nil, fmt.Errorf("Animation: invalid direction index: %d", direction)
+	if err := checkAnimation(body, action, direction); err != nil {
+		return nil, fmt.Errorf("Animation: %w", err)
 	}
 
+	// Substitute the body through body.def, then read the frames from the UOP files of
+	// modern clients, falling back to the animX.mul file the body is stored in
+	fileBody, fileHue := s.translateBody(body, hue, preserveHue)
+	tint, partial, err := s.animationHue(fileHue)
+	if err != nil {
+		return nil, fmt.Errorf("Animation: %w", err)
+	}
+
+	frames, err := s.animationFrames(fileBody, action, direction, tint, partial)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newAnimation(body, frames)
+}
+
+// newAnimation returns an animation of the body with the frames, along with its name
+// and metadata
+func (s *SDK) newAnimation(body int, frames []AnimationFrame) (*Animation, error) {
 	animdataFile, err := s.loadAnimdata()
 	if err != nil {
 		return nil, fmt.Errorf("Animation: failed loading animdata: %w", err)
 	}
 
-	// Select animX.mul based on body - match the C# implementation
-	// FileType in C# ranges from 1-5, with 1 being the default
-	fileType := 1 // Default to 1 (which is anim.mul)
+	// For animdata.mul, extract the correct entry from the chunk using body ID
+	meta, err := readAnimdata(animdataFile, body)
+	if err != nil {
+		return nil, fmt.Errorf("Animation: %w", err)
+	}
+
+	// Lookup the animation name using the embedded lookup
+	name := "Unknown"
+	if n := uofile.AnimationNameByBody(body); n != "" {
+		name = n
+	}
+	return &Animation{
+		Name:          name,
+		AnimdataEntry: meta,
+		frames:        frames,
+	}, nil
+}
+
+// animationHue returns the hue to recolor the frames with (nil if none) and whether
+// only grayscale pixels are recolored, from a hue as given to Animation
+func (s *SDK) animationHue(hue int) (*Hue, bool, error) {
+	index := hue & 0x3FFF
+	if index == 0 {
+		return nil, false, nil
+	}
 
-	// Note: This is a simplified mapping - C# uses a more complex logic via BodyConverter
-	// For now, we'll use a simple mapping based on the body range
-	// In C#, both fileType and offset calculation have specific ranges
-	animFile, err := s.loadAnim(fileType - 1) // Convert 1-based to 0-based for our loadAnim
+	tint, err := s.Hue(index)
 	if err != nil {
-		return nil, fmt.Errorf("load animation body=%d file=%d: %w", body, fileType, err)
+		return nil, false, err
 	}
+	return tint, hue&0x8000 != 0, nil
+}
 
-	// Calculate index based on C# logic
-	var index uint32
-	if body < 200 {
-		index = uint32(body * 110)
-	} else if body < 400 {
-		index = 22000 + uint32((body-200)*65)
-	} else {
-		index = 35000 + uint32((body-400)*175)
+// AnimationExists returns whether the client has the animation of the body, action and
+// direction, resolving the body as Animation does. Only the indices of the animation
+// files are looked up, no frame being read.
+func (s *SDK) AnimationExists(body, action, direction int) bool {
+	if checkAnimation(body, action, direction) != nil {
+		return false
 	}
 
-	// Add action and direction offsets
-	index += uint32(action * 5)
+	fileBody, _ := s.translateBody(body, 0, true)
+	entry, _, err := s.animationEntry(fileBody, action, direction)
+	return err == nil && entry != nil && entry.Len() > 0
+}
 
-	// Handle direction offset like in C#
-	if direction <= 4 {
-		index += uint32(direction)
-	} else {
-		index += uint32(direction - ((direction - 4) * 2))
+// AnimationActions returns the actions of the body which have frames in at least one of
+// their directions, in ascending order. Only the frame tables of the animations are
+// read, as AnimationFrameCount does.
+func (s *SDK) AnimationActions(body int) []int {
+	var actions []int
+	for action := 0; action < animFrameActions; action++ {
+		for direction := 0; direction < 5; direction++ {
+			if count, err := s.AnimationFrameCount(body, action, direction); err == nil && count > 0 {
+				actions = append(actions, action)
+				break
+			}
+		}
 	}
+	return actions
+}
 
-	// For animdata.mul, extract the correct entry from the chunk using body ID
-	chunkIndex := body / 8
-	entryOffset := body % 8
-	chunk, err := animdataFile.ReadFull(uint32(chunkIndex))
+// AnimationFrameCount returns the number of frames of the animation of the body, action
+// and direction, resolving the body as Animation does. Only the frame table of the
+// animation is read, without decoding any frame.
+func (s *SDK) AnimationFrameCount(body, action, direction int) (int, error) {
+	if err := checkAnimation(body, action, direction); err != nil {
+		return 0, fmt.Errorf("AnimationFrameCount: %w", err)
+	}
+
+	fileBody, _ := s.translateBody(body, 0, true)
+	entry, fromUOP, err := s.animationEntry(fileBody, action, direction)
 	switch {
 	case err != nil:
-		return nil, fmt.Errorf("Animation: failed reading animdata chunk for body %d: %w", body, err)
-	case len(chunk) < 4+(entryOffset+1)*68:
-		return nil, fmt.Errorf("Animation: animdata chunk too small for body %d", body)
+		return 0, fmt.Errorf("AnimationFrameCount: %w", err)
+	case entry == nil:
+		return 0, nil
+	case fromUOP:
+		header, err := animFrameHeader(entry)
+		if err != nil {
+			return 0, fmt.Errorf("AnimationFrameCount: %w", err)
+		}
+
+		offsets, err := animFrameOffsets(header, direction)
+		if err != nil {
+			return 0, fmt.Errorf("AnimationFrameCount: %w", err)
+		}
+
+		count := 0
+		for _, offset := range offsets {
+			if offset > 0 {
+				count++
+			}
+		}
+		return count, nil
 	}
 
-	entry := chunk[4+entryOffset*68 : 4+(entryOffset+1)*68]
-	meta, err := decodeAnimdata(entry)
+	_, offsets, err := readAnimLookup(entry, entry.Len())
 	if err != nil {
-		return nil, fmt.Errorf("Animation: failed decoding animdata entry: %w", err)
+		return 0, fmt.Errorf("AnimationFrameCount: %w", err)
 	}
+	return len(offsets), nil
+}
 
-	frameData, err := animFile.ReadFull(index)
+// checkAnimation validates the body, action and direction of an animation
+func checkAnimation(body, action, direction int) error {
+	switch {
+	case body < 0 || body > 10000:
+		return fmt.Errorf("invalid body index: %d", body)
+	case action < 0 || action > 1000:
+		return fmt.Errorf("invalid action index: %d", action)
+	case direction < 0 || direction > 7:
+		return fmt.Errorf("invalid direction index: %d", direction)
+	default:
+		return nil
+	}
+}
+
+// animationFrames reads and decodes the frames of the (already translated) body. The
+// palette of the frames is recolored if a hue is given.
+func (s *SDK) animationFrames(body, action, direction int, hue *Hue, partial bool) ([]AnimationFrame, error) {
+	entry, fromUOP, err := s.animationEntry(body, action, direction)
 	if err != nil {
-		return nil, fmt.Errorf("LoadAnimation: failed to read anim.mul entry: %w", err)
+		return nil, err
 	}
 
-	// Palette: first 512 bytes (256 colors, 2 bytes each)
-	const paletteSize = 512
-	const frameCountSize = 4
-	if len(frameData) < paletteSize+frameCountSize {
-		return nil, fmt.Errorf("invalid frame data length: %d", len(frameData))
+	if !fromUOP {
+		if entry == nil {
+			return decodeAnimMUL(nil, 0, direction, hue, partial)
+		}
+		return decodeAnimMUL(entry, entry.Len(), direction, hue, partial)
+	}
+
+	// UOP entries hold every direction and are often compressed, so are read at once
+	frameData := make([]byte, entry.Len())
+	if _, err := entry.ReadAt(frameData, 0); err != nil {
+		return nil, fmt.Errorf("LoadAnimation: failed to read animation entry: %w", err)
+	}
+	return decodeAnimUOP(frameData, direction, hue, partial)
+}
+
+// animationEntry looks up the entry of the (already translated) body, action and
+// direction, in AnimationFrameN.uop if the client has it (fromUOP being set), or in the
+// animX.mul file selected through bodyconv.def otherwise. The entry is nil if the
+// animation is not present in the file.
+func (s *SDK) animationEntry(body, action, direction int) (entry uofile.Entry, fromUOP bool, err error) {
+	if entry, ok := s.animFrameEntry(body, action); ok {
+		return entry, true, nil
+	}
+
+	fileType, _, offset, actions := s.bodyLayout(body)
+	animFile, err := s.loadAnim(fileType)
+	if err != nil {
+		return nil, false, fmt.Errorf("load animation body=%d file=%d: %w", body, fileType, err)
+	}
+
+	if action >= actions {
+		return nil, false, fmt.Errorf("Animation: invalid action %d for body %d, which has %d actions", action, body, actions)
+	}
+
+	entry, err = animFile.Entry(animEntry(offset, action, direction))
+	switch {
+	case errors.Is(err, mul.ErrInvalidIndex):
+		return nil, false, nil // Beyond the end of the index
+	case err != nil:
+		return nil, false, fmt.Errorf("LoadAnimation: failed to read anim.mul entry: %w", err)
+	default:
+		return entry, false, nil
+	}
+}
+
+// decodeAnimMUL decodes the frames of an entry of anim.mul of the given size: a palette
+// of 256 colors, the number of frames and their offsets (relative to the end of the
+// palette), then the frames themselves. The entry is read on demand, the palette and
+// lookup table first and then one frame at a time, so that large entries are never
+// held in memory as a whole. The palette is recolored if a hue is given.
+func decodeAnimMUL(entry io.ReaderAt, size, direction int, hue *Hue, partial bool) ([]AnimationFrame, error) {
+	header, offsets, err := readAnimLookup(entry, size)
+	if err != nil || len(offsets) == 0 {
+		return nil, err
 	}
 
 	palette := make([]uint16, 256)
 	for i := 0; i < 256; i++ {
 		// C# does: palette[i] = (ushort)(bin.ReadUInt16() ^ 0x8000)
 		// This XORs with the high bit which controls transparency
-		color := uint16(frameData[i*2]) | uint16(frameData[i*2+1])<<8
+		color := uint16(header[i*2]) | uint16(header[i*2+1])<<8
 		palette[i] = color ^ 0x8000 // XOR with 0x8000 to match C# implementation
 	}
 
-	// Frame count and lookup table.
-	frameCount := int(int32(binary.LittleEndian.Uint32(frameData[paletteSize : paletteSize+frameCountSize])))
-	if frameCount <= 0 {
-		return &Animation{
-			AnimdataEntry: meta,
-			frames:        nil,
-		}, nil
-	}
-	// Lookup table starts immediately after the frame count.
-	const lookupStart = paletteSize + frameCountSize
-	frames := make([]AnimationFrame, 0, frameCount)
-	for i := 0; i < frameCount; i++ {
-		entry := lookupStart + i*4
-		if entry+4 > len(frameData) {
-			break
-		}
-		rel := int(int32(binary.LittleEndian.Uint32(frameData[entry : entry+4])))
-		if rel <= 0 {
-			continue
-		}
-		offset := paletteSize + rel
-		if offset < 0 || offset >= len(frameData) {
-			continue
+	if hue != nil {
+		applyHuePalette(palette, hue, partial)
+	}
+
+	// Each frame ends where the next one in the entry begins
+	sorted := slices.Clone(offsets)
+	slices.Sort(sorted)
+	sorted = slices.Compact(append(sorted, size))
+
+	var buffer []byte
+	frames := make([]AnimationFrame, 0, len(offsets))
+	for _, offset := range offsets {
+		next, _ := slices.BinarySearch(sorted, offset)
+		length := sorted[next+1] - offset
+		buffer = slices.Grow(buffer[:0], length)[:length]
+		if n, err := entry.ReadAt(buffer, int64(offset)); n < length {
+			return nil, fmt.Errorf("failed to read animation frame: %w", err)
 		}
-		frameSlice := frameData[offset:]
-		flip := direction > 4
-		center, img, err := decodeFrame(palette, frameSlice, flip)
+
+		center, img, err := decodeFrame(palette, buffer, direction > 4)
 		if err != nil || img == nil {
 			continue
 		}
 		frames = append(frames, AnimationFrame{Center: center, Bitmap: img})
 	}
-	// Lookup the animation name using the embedded lookup
-	name := "Unknown"
-	if n := uofile.AnimationNameByBody(body); n != "" {
-		name = n
+	return frames, nil
+}
+
+// readAnimLookup reads the palette and frame count of an entry of anim.mul, followed by
+// its lookup table, returning them along with the offsets of the frames within the
+// entry, in the order of the lookup table. Frames outside of the entry are skipped.
+func readAnimLookup(entry io.ReaderAt, size int) (header []byte, offsets []int, err error) {
+	const paletteSize = 512
+	const lookupStart = paletteSize + 4
+	if entry == nil || size < lookupStart {
+		return nil, nil, fmt.Errorf("invalid frame data length: %d", size)
 	}
-	return &Animation{
-		Name:          name,
-		AnimdataEntry: meta,
-		frames:        frames,
-	}, nil
+
+	header = make([]byte, lookupStart)
+	if n, err := entry.ReadAt(header, 0); n < len(header) {
+		return nil, nil, fmt.Errorf("failed to read animation header: %w", err)
+	}
+
+	frameCount := int(int32(binary.LittleEndian.Uint32(header[paletteSize:])))
+	if frameCount <= 0 {
+		return header, nil, nil
+	}
+
+	lookup := make([]byte, min(frameCount*4, (size-lookupStart)/4*4))
+	if n, err := entry.ReadAt(lookup, lookupStart); n < len(lookup) {
+		return nil, nil, fmt.Errorf("failed to read animation lookup table: %w", err)
+	}
+
+	offsets = make([]int, 0, len(lookup)/4)
+	for i := 0; i < len(lookup); i += 4 {
+		rel := int(int32(binary.LittleEndian.Uint32(lookup[i:])))
+		if offset := paletteSize + rel; rel > 0 && offset < size {
+			offsets = append(offsets, offset)
+		}
+	}
+	return header, offsets, nil
 }
 
 // AnimationNames provides canonical names for humanoid animation actions by index
@@ -206,6 +367,75 @@ func AnimationName(action int) string {
 	return "Unknown"
 }
 
+// AnimData returns the animdata.mul entry with the given ID, which is the ID of an
+// animated static item (see ItemAnimation) or the body of an animation.
+func (s *SDK) AnimData(id int) (*AnimdataEntry, error) {
+	if id < 0 {
+		return nil, fmt.Errorf("AnimData: invalid ID: %d", id)
+	}
+
+	file, err := s.loadAnimdata()
+	if err != nil {
+		return nil, fmt.Errorf("AnimData: %w", err)
+	}
+
+	entry, err := readAnimdata(file, id)
+	if err != nil {
+		return nil, fmt.Errorf("AnimData: %w", err)
+	}
+	return entry, nil
+}
+
+// AnimDatas returns an iterator over all animdata.mul entries which have frames, keyed
+// by their ID. Each chunk of 8 entries is read once.
+func (s *SDK) AnimDatas() iter.Seq2[int, *AnimdataEntry] {
+	return func(yield func(int, *AnimdataEntry) bool) {
+		file, err := s.loadAnimdata()
+		if err != nil {
+			return
+		}
+
+		for chunkIndex := range file.Entries() {
+			chunk, err := file.ReadFull(chunkIndex)
+			if err != nil {
+				continue
+			}
+
+			for i := 0; 4+(i+1)*68 <= len(chunk); i++ {
+				entry, err := decodeAnimdata(chunk[4+i*68 : 4+(i+1)*68])
+				if err != nil || entry.FrameCount == 0 {
+					continue
+				}
+
+				if !yield(int(chunkIndex)*8+i, entry) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// readAnimdata reads the animdata entry with the given ID. Entries are stored in
+// chunks of 8, each chunk starting with a 4-byte header.
+func readAnimdata(file *uofile.File, id int) (*AnimdataEntry, error) {
+	chunkIndex := id / 8
+	entryOffset := id % 8
+	chunk, err := file.ReadFull(uint32(chunkIndex))
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("failed reading animdata chunk for %d: %w", id, err)
+	case len(chunk) < 4+(entryOffset+1)*68:
+		return nil, fmt.Errorf("animdata chunk too small for %d", id)
+	}
+
+	entry, err := decodeAnimdata(chunk[4+entryOffset*68 : 4+(entryOffset+1)*68])
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding animdata entry: %w", err)
+	}
+
+	return entry, nil
+}
+
 // decodeAnimdata parses the animation metadata from the provided binary data.
 // The data should be exactly 68 bytes long (64 bytes of frame data + 4 bytes of metadata).
 // Format:
@@ -0,0 +1,225 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/go-mmap/mmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
+)
+
+// decodeBodyconvFile loads all entries of bodyconv.def into mul.Entry3D, keyed by the
+// original body, with the body to use in anim2.mul to anim5.mul as little-endian int32
+// (-1 if the body is not stored in that file).
+//
+// The bodyconv.def file format is line-based text:
+//   - Lines starting with '#' (or empty lines) are ignored
+//   - Each other line has the form "body anim2 anim3 anim4 anim5", trailing columns
+//     being optional
+func decodeBodyconvFile(file *mmap.File, add mul.AddFn) error {
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		body, err := parseDefInt(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid bodyconv entry on line %d: %w", line, err)
+		}
+
+		entry := make([]byte, 16)
+		for i := 0; i < 4; i++ {
+			target := -1
+			if i+1 < len(fields) {
+				if target, err = parseDefInt(fields[i+1]); err != nil {
+					return fmt.Errorf("invalid bodyconv entry on line %d: %w", line, err)
+				}
+			}
+
+			// Body 68 of anim2.mul is stored as 122, as in the client
+			if i == 0 && target == 68 {
+				target = 122
+			}
+
+			binary.LittleEndian.PutUint32(entry[i*4:], uint32(int32(target)))
+		}
+
+		add(uint32(body), uint32(body), uint32(len(entry)), 0, entry)
+	}
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read bodyconv file: %w", err)
+	}
+	return nil
+}
+
+// translateBody replaces the body by its substitute from body.def, if it has one. The
+// hue of the substitute is used unless preserveHue is set or a valid hue is given, hues
+// being indices as accepted by Hue with 0 for none.
+func (s *SDK) translateBody(body, hue int, preserveHue bool) (int, int) {
+	def, err := s.loadDef("body.def")
+	if err != nil {
+		return body, hue
+	}
+
+	data, err := def.ReadFull(uint32(body))
+	if err != nil || len(data) < 8 {
+		return body, hue
+	}
+
+	// The hues of body.def are client hue numbers, N being the entry N-1 of hues.mul
+	entry := defEntry(data)
+	if index := hue & 0x3FFF; !preserveHue && (index == 0 || index >= 3000) {
+		hue = max(entry.Hue()-1, 0)
+	}
+	return entry.Targets()[0], hue
+}
+
+// convertBody returns the animation file type (1 for anim.mul, 2 to 5 for anim2.mul to
+// anim5.mul) the body is stored in, according to bodyconv.def, along with the body to
+// use within that file. Files which are not present in the client are skipped.
+func (s *SDK) convertBody(body int) (fileType, converted int) {
+	conv, err := s.loadBodyconv()
+	if err != nil {
+		return 1, body
+	}
+
+	data, err := conv.ReadFull(uint32(body))
+	if err != nil || len(data) < 16 {
+		return 1, body
+	}
+
+	for i := 0; i < 4; i++ {
+		target := int(int32(binary.LittleEndian.Uint32(data[i*4:])))
+		if target < 0 {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(s.basePath, animFileNames(i + 2)[0])); err == nil {
+			return i + 2, target
+		}
+	}
+
+	return 1, body
+}
+
+// BodyType describes how a body is animated and where its animations are stored
+type BodyType struct {
+	Body       int           // Body as given, before its substitution through body.def
+	Type       AnimationType // Kind of creature, from mobtypes.txt or the range of the body
+	Flags      MobFlag       // Animation properties from mobtypes.txt, if listed there
+	HighDetail bool          // Whether the body is a high detail creature, with 22 actions
+	Actions    int           // Number of actions stored for the body
+	FileType   int           // Animation file, 1 for anim.mul and 2 to 5 for anim2.mul to anim5.mul
+	FileBody   int           // Body within the animation file
+}
+
+// BodyType classifies the body as Animation resolves it: substituted through body.def,
+// converted to its animation file through bodyconv.def and laid out by mobtypes.txt
+// or by its range within the file. Without mobtypes.txt, bodies laid out as humans are
+// reported as such, since equipment is laid out in the same way.
+func (s *SDK) BodyType(body int) (BodyType, error) {
+	if body < 0 || body >= 0x10000 {
+		return BodyType{}, fmt.Errorf("BodyType: invalid body %d", body)
+	}
+
+	fileBody, _ := s.translateBody(body, 0, false)
+	out := BodyType{Body: body}
+	out.FileType, out.FileBody, _, out.Actions = s.bodyLayout(fileBody)
+	out.HighDetail = out.Actions == 22
+
+	switch mob, err := s.MobType(fileBody); {
+	case err == nil:
+		out.Type, out.Flags = mob.Type, mob.Flags
+	case out.Actions == 22:
+		out.Type = AnimationTypeMonster
+	case out.Actions == 13:
+		out.Type = AnimationTypeAnimal
+	default:
+		out.Type = AnimationTypeHuman
+	}
+	return out, nil
+}
+
+// bodyLayout returns the animation file the (already translated) body is stored in and
+// the body within it, along with the offset of its first entry and its number of
+// actions. The layout of the bodies of anim.mul is defined by mobtypes.txt, if present.
+func (s *SDK) bodyLayout(body int) (fileType, fileBody, offset, actions int) {
+	fileType, fileBody = s.convertBody(body)
+	offset, actions = animLayout(fileType, fileBody)
+	if fileType == 1 {
+		if mob, err := s.MobType(fileBody); err == nil {
+			if o, n := mob.layout(); o >= 0 {
+				offset, actions = o, n
+			}
+		}
+	}
+	return
+}
+
+// animLayout returns the offset of the first entry of the body within the index of its
+// file type, along with the number of actions stored for the body
+func animLayout(fileType, body int) (offset, actions int) {
+	switch fileType {
+	case 2:
+		if body < 200 {
+			return body * 110, 22
+		}
+		return 22000 + (body-200)*65, 13
+	case 3:
+		switch {
+		case body < 300:
+			return body * 65, 13
+		case body < 400:
+			return 33000 + (body-300)*110, 22
+		default:
+			return 35000 + (body-400)*175, 35
+		}
+	case 5:
+		switch {
+		case body < 200 && body != 34:
+			return body * 110, 22
+		case body < 400:
+			return 22000 + (body-200)*65, 13
+		default:
+			return 35000 + (body-400)*175, 35
+		}
+	default:
+		switch {
+		case body < 200:
+			return body * 110, 22
+		case body < 400:
+			return 22000 + (body-200)*65, 13
+		default:
+			return 35000 + (body-400)*175, 35
+		}
+	}
+}
+
+// animEntry returns the index of the entry of the action and direction, given the
+// offset of the first entry of the body
+func animEntry(offset, action, direction int) uint32 {
+	// Only 5 directions are stored, the others being mirrored
+	index := offset + action*5
+	if direction <= 4 {
+		index += direction
+	} else {
+		index += direction - (direction-4)*2
+	}
+	return uint32(index)
+}
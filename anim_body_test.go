@@ -0,0 +1,122 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimIndex(t *testing.T) {
+	tests := []struct {
+		fileType, body, action, direction int
+		index                             uint32
+	}{
+		{1, 1, 0, 0, 110},
+		{1, 200, 1, 4, 22000 + 5 + 4},
+		{1, 400, 0, 0, 35000},
+		{1, 1, 0, 5, 110 + 3}, // Mirrored from direction 3
+		{1, 1, 0, 7, 110 + 1}, // Mirrored from direction 1
+		{2, 250, 0, 0, 22000 + 50*65},
+		{3, 250, 0, 0, 250 * 65},
+		{3, 350, 0, 0, 33000 + 50*110},
+		{4, 100, 2, 0, 100*110 + 10},
+		{5, 34, 0, 0, 22000 + (34-200)*65},
+		{5, 35, 0, 0, 35 * 110},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.index, animIndex(tc.fileType, tc.body, tc.action, tc.direction), "%+v", tc)
+	}
+}
+
+func TestSDK_Animation_BodyConversion(t *testing.T) {
+	dir := t.TempDir()
+	var palette [256]uint16
+	palette[1] = 0xFC00
+
+	writeTestAnims(t, dir, 1, map[uint32][]byte{
+		animIndex(1, 1, 0, 0): encodeTestAnim(palette, testAnimFrame{width: 2, height: 2, color: 1}),
+	})
+	writeTestAnims(t, dir, 2, map[uint32][]byte{
+		animIndex(2, 5, 0, 0): encodeTestAnim(palette, testAnimFrame{width: 3, height: 4, color: 1}),
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bodyconv.def"), []byte(
+		"# body anim2 anim3 anim4 anim5\n300\t5\t-1\t-1\t-1\n301\t-1\t-1\t7\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "body.def"), []byte(
+		"900 {300} 33\n"), 0644))
+	writeTestHues(t, dir, map[int]uint16{32: 0x03E0})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	size := func(body int) image.Point {
+		anim, err := sdk.Animation(body, 0, 0, 0, false, false)
+		require.NoError(t, err)
+		for frame := range anim.Frames() {
+			return frame.Bitmap.Bounds().Size()
+		}
+		return image.Point{}
+	}
+
+	assert.Equal(t, image.Pt(2, 2), size(1), "body stored in anim.mul")
+	assert.Equal(t, image.Pt(3, 4), size(300), "body converted to anim2.mul")
+	assert.Equal(t, image.Pt(3, 4), size(900), "body translated through body.def")
+
+	// Files which are not in the client are skipped
+	fileType, body := sdk.convertBody(301)
+	assert.Equal(t, 1, fileType)
+	assert.Equal(t, 301, body)
+
+	// The hue of the substitute, the client hue 33 being the entry 32 of hues.mul, applies
+	// unless a valid hue is given or preserved
+	body, hue := sdk.translateBody(900, 0, false)
+	assert.Equal(t, 300, body)
+	assert.Equal(t, 32, hue)
+	_, hue = sdk.translateBody(900, 0, true)
+	assert.Equal(t, 0, hue)
+	_, hue = sdk.translateBody(900, 1153, false)
+	assert.Equal(t, 1153, hue)
+}
+
+func TestSDK_BodyType(t *testing.T) {
+	dir := t.TempDir()
+	writeTestAnims(t, dir, 1, nil)
+	writeTestAnims(t, dir, 2, nil)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bodyconv.def"), []byte("300\t5\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "body.def"), []byte("900 {300} 0\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mobtypes.txt"), []byte("970\tEQUIPMENT\t0\n250\tMONSTER\t8\n"), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	tests := []struct {
+		body   int
+		expect BodyType
+	}{
+		{1, BodyType{Body: 1, Type: AnimationTypeMonster, HighDetail: true, Actions: 22, FileType: 1, FileBody: 1}},
+		{200, BodyType{Body: 200, Type: AnimationTypeAnimal, Actions: 13, FileType: 1, FileBody: 200}},
+		{400, BodyType{Body: 400, Type: AnimationTypeHuman, Actions: 35, FileType: 1, FileBody: 400}},
+		{970, BodyType{Body: 970, Type: AnimationTypeEquipment, Actions: 35, FileType: 1, FileBody: 970}},
+		{250, BodyType{Body: 250, Type: AnimationTypeMonster, Flags: MobFlagCanFly, HighDetail: true, Actions: 22, FileType: 1, FileBody: 250}},
+		{300, BodyType{Body: 300, Type: AnimationTypeMonster, HighDetail: true, Actions: 22, FileType: 2, FileBody: 5}},
+		{900, BodyType{Body: 900, Type: AnimationTypeMonster, HighDetail: true, Actions: 22, FileType: 2, FileBody: 5}},
+	}
+
+	for _, tc := range tests {
+		out, err := sdk.BodyType(tc.body)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expect, out, "body %d", tc.body)
+	}
+
+	_, err = sdk.BodyType(-1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+)
+
+// AnimationLayer describes one of the animations overlaid by ComposeAnimation, such as
+// a mount, the body of a mobile or a piece of equipment
+type AnimationLayer struct {
+	Body   int         // Body of the layer, as given to Animation
+	Action int         // Action of the layer, as mounts and their riders play different actions
+	Hue    int         // Hue of the layer, as given to Animation
+	Offset image.Point // Offset of the layer, such as the elevation of a rider on its mount
+}
+
+// ComposeAnimation loads the animation of every layer for the direction and overlays
+// them frame by frame into a single animation, such as a character riding a mount and
+// wearing equipment. Layers are drawn in the given order, the first one being at the
+// bottom; the composed animation takes the name and metadata of the first layer.
+func (s *SDK) ComposeAnimation(direction int, layers ...AnimationLayer) (*Animation, error) {
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("ComposeAnimation: no layers to compose")
+	}
+
+	anims := make([]*Animation, 0, len(layers))
+	offsets := make([]image.Point, 0, len(layers))
+	for _, layer := range layers {
+		anim, err := s.Animation(layer.Body, layer.Action, direction, layer.Hue, false, false)
+		if err != nil {
+			return nil, fmt.Errorf("ComposeAnimation: body %d: %w", layer.Body, err)
+		}
+
+		anims = append(anims, anim)
+		offsets = append(offsets, layer.Offset)
+	}
+
+	return composeAnimations(anims, offsets), nil
+}
+
+// ComposeAnimations overlays the animations frame by frame into a single animation,
+// aligned on the centers of their frames. Animations are drawn in the given order, the
+// first one being at the bottom. Animations with fewer frames than the others loop.
+func ComposeAnimations(anims ...*Animation) *Animation {
+	return composeAnimations(anims, make([]image.Point, len(anims)))
+}
+
+// composeAnimations overlays the animations, each one being moved by its offset
+func composeAnimations(anims []*Animation, offsets []image.Point) *Animation {
+	out := &Animation{}
+	frameCount := 0
+	for i, anim := range anims {
+		if i == 0 {
+			out.Name, out.AnimdataEntry = anim.Name, anim.AnimdataEntry
+		}
+		frameCount = max(frameCount, len(anim.frames))
+	}
+
+	for i := 0; i < frameCount; i++ {
+		layers := make([]AnimationFrame, 0, len(anims))
+		rects := make([]image.Rectangle, 0, len(anims))
+		var canvas image.Rectangle
+		for j, anim := range anims {
+			if len(anim.frames) == 0 {
+				continue
+			}
+
+			frame := anim.frames[i%len(anim.frames)]
+			if frame.Bitmap == nil {
+				continue
+			}
+
+			rect := frameRect(frame).Add(offsets[j])
+			layers = append(layers, frame)
+			rects = append(rects, rect)
+			canvas = canvas.Union(rect)
+		}
+
+		if len(layers) == 0 {
+			out.frames = append(out.frames, AnimationFrame{})
+			continue
+		}
+
+		// Copy the opaque pixels of every layer over the previous ones
+		dst := bitmap.NewARGB1555(image.Rect(0, 0, canvas.Dx(), canvas.Dy()))
+		for j, frame := range layers {
+			src, at := frame.Bitmap, rects[j].Min.Sub(canvas.Min)
+			for y := 0; y < src.Rect.Dy(); y++ {
+				for x := 0; x < src.Rect.Dx(); x++ {
+					from := y*src.Stride + x*2
+					if src.Pix[from+1]&0x80 == 0 {
+						continue // Transparent
+					}
+
+					to := (at.Y+y)*dst.Stride + (at.X+x)*2
+					dst.Pix[to], dst.Pix[to+1] = src.Pix[from], src.Pix[from+1]
+				}
+			}
+		}
+
+		// The center is placed so that the canvas is drawn where the layers would be
+		out.frames = append(out.frames, AnimationFrame{
+			Center: image.Pt(-canvas.Min.X, -canvas.Max.Y),
+			Bitmap: dst,
+		})
+	}
+	return out
+}
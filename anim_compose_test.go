@@ -0,0 +1,76 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeAnimations(t *testing.T) {
+	base := newTestAnimation() // Red 4x6 standing on its bottom, then green 2x2 above
+	overlay := &Animation{
+		Name: "Overlay",
+		frames: []AnimationFrame{
+			newTestFrame(2, 2, image.Pt(1, -1), 0x801F), // Blue, across the point
+		},
+	}
+
+	out := ComposeAnimations(base, overlay)
+	assert.Equal(t, base.AnimdataEntry, out.AnimdataEntry)
+	require.Len(t, out.frames, 2)
+
+	// The first frame covers both layers, the overlay being drawn on top
+	first := out.frames[0]
+	assert.Equal(t, image.Rect(-2, -6, 2, 1), frameRect(first))
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), first.Bitmap.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), first.Bitmap.At(2, 5))
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), first.Bitmap.At(1, 6))
+	assert.Equal(t, bitmap.ARGB1555Color(0), first.Bitmap.At(0, 6), "transparent")
+
+	// The overlay loops, having fewer frames
+	second := out.frames[1]
+	assert.Equal(t, image.Rect(-1, -4, 2, 1), frameRect(second))
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), second.Bitmap.At(1, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), second.Bitmap.At(0, 4))
+}
+
+func TestSDK_ComposeAnimation(t *testing.T) {
+	dir := t.TempDir()
+	var palette [256]uint16
+	palette[1] = 0xFC00
+	palette[2] = 0x801F
+
+	writeTestAnims(t, dir, 1, map[uint32][]byte{
+		animIndex(1, 200, 0, 0): encodeTestAnim(palette, testAnimFrame{center: image.Pt(2, 0), width: 4, height: 2, color: 1}),
+		animIndex(1, 400, 3, 0): encodeTestAnim(palette, testAnimFrame{center: image.Pt(1, 0), width: 2, height: 2, color: 2}),
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	// A rider sitting on top of its mount
+	anim, err := sdk.ComposeAnimation(0,
+		AnimationLayer{Body: 200, Action: 0},
+		AnimationLayer{Body: 400, Action: 3, Offset: image.Pt(0, -2)},
+	)
+	require.NoError(t, err)
+	require.Len(t, anim.frames, 1)
+
+	frame := anim.frames[0]
+	assert.Equal(t, image.Rect(-2, -4, 2, 0), frameRect(frame))
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), frame.Bitmap.At(1, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0), frame.Bitmap.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), frame.Bitmap.At(0, 3))
+
+	_, err = sdk.ComposeAnimation(0)
+	assert.Error(t, err)
+	_, err = sdk.ComposeAnimation(0, AnimationLayer{Body: -1})
+	assert.Error(t, err)
+}
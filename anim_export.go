@@ -0,0 +1,356 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"cmp"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+)
+
+// Interval returns the delay between two frames of the animation, from the frame
+// interval of its animdata entry (in tenths of a second), or 100ms if it has none.
+func (a *Animation) Interval() time.Duration {
+	if a.AnimdataEntry == nil || a.AnimdataEntry.FrameInterval == 0 {
+		return 100 * time.Millisecond
+	}
+	return time.Duration(a.AnimdataEntry.FrameInterval) * 100 * time.Millisecond
+}
+
+// ExportGIF writes the animation as an animated GIF, looping forever. Frames are laid
+// out on a canvas large enough for all of them, aligned on their centers, and shown
+// for the Interval of the animation.
+func (a *Animation) ExportGIF(w io.Writer) error {
+	frames, err := a.composeFrames()
+	if err != nil {
+		return fmt.Errorf("ExportGIF: %w", err)
+	}
+
+	colors := gifPalette(frames)
+	delay := max(1, int(a.Interval()/(10*time.Millisecond)))
+	out := &gif.GIF{}
+	for _, frame := range frames {
+		img := image.NewPaletted(frame.Bounds(), colors)
+		index := make(map[color.NRGBA]uint8)
+		for y := 0; y < frame.Rect.Dy(); y++ {
+			for x := 0; x < frame.Rect.Dx(); x++ {
+				c := frame.NRGBAAt(x, y)
+				if c.A == 0 {
+					continue // Index 0 is transparent
+				}
+
+				i, ok := index[c]
+				if !ok {
+					i = uint8(colors[1:].Index(c) + 1)
+					index[c] = i
+				}
+				img.SetColorIndex(x, y, i)
+			}
+		}
+
+		out.Image = append(out.Image, img)
+		out.Delay = append(out.Delay, delay)
+		out.Disposal = append(out.Disposal, gif.DisposalBackground)
+	}
+
+	if err := gif.EncodeAll(w, out); err != nil {
+		return fmt.Errorf("ExportGIF: %w", err)
+	}
+	return nil
+}
+
+// ExportAPNG writes the animation as an animated PNG, looping forever. Unlike the GIF
+// export, colors are not quantized. Frames are laid out as in ExportGIF.
+func (a *Animation) ExportAPNG(w io.Writer) error {
+	frames, err := a.composeFrames()
+	if err != nil {
+		return fmt.Errorf("ExportAPNG: %w", err)
+	}
+
+	// Every frame is stored as 8-bit RGBA, so that they all match the header
+	canvas := frames[0].Rect
+	header := binary.BigEndian.AppendUint32(nil, uint32(canvas.Dx()))
+	header = binary.BigEndian.AppendUint32(header, uint32(canvas.Dy()))
+	header = append(header, 8, 6, 0, 0, 0) // Bit depth, RGBA, compression, filter, interlace
+
+	out := &apngWriter{w: w}
+	out.write([]byte("\x89PNG\r\n\x1a\n"))
+	out.chunk("IHDR", header)
+	out.chunk("acTL", binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, uint32(len(frames))), 0))
+	delay := uint16(min(a.Interval().Milliseconds(), 0xFFFF))
+	for i, frame := range frames {
+		data, err := apngData(frame)
+		if err != nil {
+			return fmt.Errorf("ExportAPNG: %w", err)
+		}
+
+		out.frameControl(frame.Rect, delay)
+		if i == 0 {
+			out.chunk("IDAT", data)
+		} else {
+			out.chunk("fdAT", append(out.sequence(), data...))
+		}
+	}
+
+	out.chunk("IEND", nil)
+	if out.err != nil {
+		return fmt.Errorf("ExportAPNG: %w", out.err)
+	}
+	return nil
+}
+
+// animationSheet is the JSON manifest written by ExportSheet
+type animationSheet struct {
+	Width  int                  `json:"width"`  // Width of the sheet
+	Height int                  `json:"height"` // Height of the sheet
+	Frames []animationSheetItem `json:"frames"` // Frames of the animation, in order
+}
+
+// animationSheetItem describes a frame within the sheet written by ExportSheet
+type animationSheetItem struct {
+	X        int        `json:"x"`        // Left position within the sheet
+	Y        int        `json:"y"`        // Top position within the sheet
+	Width    int        `json:"width"`    // Width of the frame, 0 if the frame is empty
+	Height   int        `json:"height"`   // Height of the frame, 0 if the frame is empty
+	Center   sheetPoint `json:"center"`   // Center of the frame, as stored by the client
+	Anchor   sheetPoint `json:"anchor"`   // Point the mobile stands on, relative to the frame
+	Duration int        `json:"duration"` // Duration of the frame, in milliseconds
+}
+
+// sheetPoint is a point of the manifest written by ExportSheet
+type sheetPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// ExportSheet packs the frames of the animation into a single PNG sprite sheet, written
+// to sheet, and writes a JSON manifest describing the location, center and duration of
+// every frame to manifest. The anchor of a frame is the point the mobile stands on
+// within it, where game engines usually pivot sprites.
+func (a *Animation) ExportSheet(sheet, manifest io.Writer) error {
+	images := make(map[int]image.Image, len(a.frames))
+	size, area := 1, 0
+	for i, frame := range a.frames {
+		if frame.Bitmap != nil && !frame.Bitmap.Bounds().Empty() {
+			bounds := frame.Bitmap.Bounds()
+			images[i] = frame.Bitmap
+			size = max(size, bounds.Dx(), bounds.Dy())
+			area += bounds.Dx() * bounds.Dy()
+		}
+	}
+
+	if len(images) == 0 {
+		return fmt.Errorf("ExportSheet: animation has no frames")
+	}
+
+	// Grow the sheet until every frame fits in it, starting from a square of their area
+	for size*size < area {
+		size *= 2
+	}
+
+	atlas, err := PackAtlas(images, size)
+	for err == nil && len(atlas.Sheets) > 1 {
+		size *= 2
+		atlas, err = PackAtlas(images, size)
+	}
+	if err != nil {
+		return fmt.Errorf("ExportSheet: %w", err)
+	}
+
+	bounds := atlas.Sheets[0].Bounds()
+	out := animationSheet{Width: bounds.Dx(), Height: bounds.Dy()}
+	duration := int(a.Interval().Milliseconds())
+	for i, frame := range a.frames {
+		item := animationSheetItem{Center: sheetPoint{frame.Center.X, frame.Center.Y}, Duration: duration}
+		if j, ok := slices.BinarySearchFunc(atlas.Sprites, i, func(s AtlasSprite, id int) int {
+			return cmp.Compare(s.ID, id)
+		}); ok {
+			sprite := atlas.Sprites[j]
+			item.X, item.Y, item.Width, item.Height = sprite.X, sprite.Y, sprite.Width, sprite.Height
+			item.Anchor = sheetPoint{frame.Center.X, frame.Center.Y + sprite.Height}
+		}
+		out.Frames = append(out.Frames, item)
+	}
+
+	if err := png.Encode(sheet, atlas.Sheets[0]); err != nil {
+		return fmt.Errorf("ExportSheet: %w", err)
+	}
+
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("ExportSheet: %w", err)
+	}
+	return nil
+}
+
+// Composite returns the frames of the animation drawn on a canvas of the given size, so
+// that they all have the same size and stay aligned, as needed by sprite sheets or
+// video exports. The point the mobile stands on is at the bottom center of the canvas,
+// which is also the Center of the returned frames; pixels outside of it are clipped.
+func (a *Animation) Composite(size image.Point) []AnimationFrame {
+	if size.X <= 0 || size.Y <= 0 {
+		return nil
+	}
+
+	canvas := image.Rectangle{Max: size}
+	anchor := image.Pt(size.X/2, size.Y)
+	out := make([]AnimationFrame, 0, len(a.frames))
+	for _, frame := range a.frames {
+		dst := bitmap.NewARGB1555(canvas)
+		out = append(out, AnimationFrame{Center: image.Pt(anchor.X, 0), Bitmap: dst})
+		if frame.Bitmap == nil {
+			continue
+		}
+
+		src := frame.Bitmap
+		rect := frameRect(frame).Add(anchor)
+		clip := rect.Intersect(canvas)
+		for y := clip.Min.Y; y < clip.Max.Y; y++ {
+			from := (y-rect.Min.Y)*src.Stride + (clip.Min.X-rect.Min.X)*2
+			to := y*dst.Stride + clip.Min.X*2
+			copy(dst.Pix[to:to+clip.Dx()*2], src.Pix[from:])
+		}
+	}
+	return out
+}
+
+// composeFrames draws every frame of the animation on a canvas covering all of them,
+// the center of the frames being at the same location
+func (a *Animation) composeFrames() ([]*image.NRGBA, error) {
+	var canvas image.Rectangle
+	for _, frame := range a.frames {
+		if frame.Bitmap != nil {
+			canvas = canvas.Union(frameRect(frame))
+		}
+	}
+
+	if canvas.Empty() {
+		return nil, fmt.Errorf("animation has no frames")
+	}
+
+	out := make([]*image.NRGBA, 0, len(a.frames))
+	for _, frame := range a.frames {
+		img := image.NewNRGBA(image.Rect(0, 0, canvas.Dx(), canvas.Dy()))
+		if frame.Bitmap != nil {
+			dst := frameRect(frame).Sub(canvas.Min)
+			draw.Draw(img, dst, frame.Bitmap, frame.Bitmap.Bounds().Min, draw.Src)
+		}
+		out = append(out, img)
+	}
+	return out, nil
+}
+
+// frameRect returns the area covered by the frame, relative to the point the mobile
+// stands on, as drawn by the client
+func frameRect(frame AnimationFrame) image.Rectangle {
+	size := frame.Bitmap.Bounds().Size()
+	origin := image.Pt(-frame.Center.X, -frame.Center.Y-size.Y)
+	return image.Rectangle{Min: origin, Max: origin.Add(size)}
+}
+
+// gifPalette returns the palette of the frames, with the transparent color first. If
+// the frames use too many colors, a generic palette is used instead.
+func gifPalette(frames []*image.NRGBA) color.Palette {
+	colors := color.Palette{color.NRGBA{}}
+	seen := make(map[color.NRGBA]struct{})
+	for _, frame := range frames {
+		for y := 0; y < frame.Rect.Dy(); y++ {
+			for x := 0; x < frame.Rect.Dx(); x++ {
+				c := frame.NRGBAAt(x, y)
+				if _, ok := seen[c]; ok || c.A == 0 {
+					continue
+				}
+
+				if len(colors) == 256 {
+					return append(color.Palette{color.NRGBA{}}, palette.Plan9[:255]...)
+				}
+
+				seen[c] = struct{}{}
+				colors = append(colors, c)
+			}
+		}
+	}
+	return colors
+}
+
+// apngWriter writes the chunks of an animated PNG, keeping track of the sequence
+// numbers of the frame chunks and of the first error
+type apngWriter struct {
+	w   io.Writer
+	seq uint32
+	err error
+}
+
+// write writes raw bytes, unless an error occurred before
+func (w *apngWriter) write(data []byte) {
+	if w.err == nil {
+		_, w.err = w.w.Write(data)
+	}
+}
+
+// chunk writes a chunk with its length and checksum
+func (w *apngWriter) chunk(kind string, body []byte) {
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(body)
+
+	w.write(binary.BigEndian.AppendUint32(nil, uint32(len(body))))
+	w.write([]byte(kind))
+	w.write(body)
+	w.write(binary.BigEndian.AppendUint32(nil, crc.Sum32()))
+}
+
+// sequence returns the next sequence number, encoded
+func (w *apngWriter) sequence() []byte {
+	w.seq++
+	return binary.BigEndian.AppendUint32(nil, w.seq-1)
+}
+
+// apngData compresses the pixels of a frame into PNG image data, every row of 8-bit
+// RGBA pixels being stored without filtering
+func apngData(img *image.NRGBA) ([]byte, error) {
+	var buffer bytes.Buffer
+	z := zlib.NewWriter(&buffer)
+	row := img.Rect.Dx() * 4
+	for y := 0; y < img.Rect.Dy(); y++ {
+		offset := y * img.Stride
+		z.Write([]byte{0}) // No filter
+		z.Write(img.Pix[offset : offset+row])
+	}
+
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// frameControl writes the fcTL chunk of a frame covering the whole canvas, cleared
+// before the next frame is drawn
+func (w *apngWriter) frameControl(rect image.Rectangle, delay uint16) {
+	body := w.sequence()
+	body = binary.BigEndian.AppendUint32(body, uint32(rect.Dx()))
+	body = binary.BigEndian.AppendUint32(body, uint32(rect.Dy()))
+	body = binary.BigEndian.AppendUint32(body, 0) // X offset
+	body = binary.BigEndian.AppendUint32(body, 0) // Y offset
+	body = binary.BigEndian.AppendUint16(body, delay)
+	body = binary.BigEndian.AppendUint16(body, 1000) // Delay in milliseconds
+	body = append(body, 1, 0)                        // Dispose to background, no blending
+	w.chunk("fcTL", body)
+}
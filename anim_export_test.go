@@ -0,0 +1,208 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFrame returns a frame of a single color
+func newTestFrame(w, h int, center image.Point, c uint16) AnimationFrame {
+	img := bitmap.NewARGB1555(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, bitmap.ARGB1555Color(c))
+		}
+	}
+	return AnimationFrame{Center: center, Bitmap: img}
+}
+
+// newTestAnimation returns an animation with two frames of different sizes and centers
+func newTestAnimation() *Animation {
+	return &Animation{
+		AnimdataEntry: &AnimdataEntry{FrameInterval: 2},
+		frames: []AnimationFrame{
+			newTestFrame(4, 6, image.Pt(2, 0), 0xFC00), // Red, standing on its bottom
+			newTestFrame(2, 2, image.Pt(0, 2), 0x83E0), // Green, above the point
+		},
+	}
+}
+
+func TestAnimation_ExportGIF(t *testing.T) {
+	anim := newTestAnimation()
+	assert.Equal(t, 200*time.Millisecond, anim.Interval())
+
+	var buffer bytes.Buffer
+	require.NoError(t, anim.ExportGIF(&buffer))
+
+	out, err := gif.DecodeAll(&buffer)
+	require.NoError(t, err)
+	require.Len(t, out.Image, 2)
+	assert.Equal(t, []int{20, 20}, out.Delay)
+
+	// Both frames share the canvas, aligned on their centers
+	for _, img := range out.Image {
+		assert.Equal(t, image.Rect(0, 0, 4, 6), img.Bounds())
+	}
+
+	r, _, _, _ := out.Image[0].At(0, 5).RGBA()
+	assert.NotZero(t, r)
+	assert.Equal(t, uint8(0), out.Image[1].ColorIndexAt(0, 5), "transparent")
+	_, g, _, _ := out.Image[1].At(2, 2).RGBA()
+	assert.NotZero(t, g)
+}
+
+func TestAnimation_ExportAPNG(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, newTestAnimation().ExportAPNG(&buffer))
+
+	// Decoders without animation support see the first frame
+	img, err := png.Decode(bytes.NewReader(buffer.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 4, 6), img.Bounds())
+	assert.Equal(t, color.NRGBA{R: 255, A: 255}, color.NRGBAModel.Convert(img.At(0, 5)))
+
+	// Every frame has its frame control, with sequence numbers in order
+	chunks := map[string]int{}
+	var sequence []uint32
+	var header, second []byte
+	for data := buffer.Bytes()[8:]; len(data) >= 12; {
+		size := binary.BigEndian.Uint32(data)
+		kind := string(data[4:8])
+		if kind == "fcTL" || kind == "fdAT" {
+			sequence = append(sequence, binary.BigEndian.Uint32(data[8:]))
+		}
+		switch kind {
+		case "IHDR":
+			header = data[8 : 8+size]
+		case "fdAT":
+			second = data[12 : 8+size]
+		}
+		if kind == "fcTL" {
+			assert.Equal(t, uint16(200), binary.BigEndian.Uint16(data[8+20:]))
+		}
+
+		chunks[kind]++
+		data = data[12+size:]
+	}
+
+	assert.Equal(t, 1, chunks["acTL"])
+	assert.Equal(t, 2, chunks["fcTL"])
+	assert.Equal(t, 1, chunks["fdAT"])
+	assert.Equal(t, []uint32{0, 1, 2}, sequence)
+
+	// The second frame, with transparent pixels unlike the first, is encoded as the header
+	// declares, which a standalone PNG made of both decodes
+	var frame bytes.Buffer
+	frame.WriteString("\x89PNG\r\n\x1a\n")
+	for _, chunk := range []struct {
+		kind string
+		body []byte
+	}{{"IHDR", header}, {"IDAT", second}, {"IEND", nil}} {
+		frame.Write(binary.BigEndian.AppendUint32(nil, uint32(len(chunk.body))))
+		frame.WriteString(chunk.kind)
+		frame.Write(chunk.body)
+		frame.Write(binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(append([]byte(chunk.kind), chunk.body...))))
+	}
+
+	img, err = png.Decode(&frame)
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{G: 255, A: 255}, color.NRGBAModel.Convert(img.At(2, 2)))
+	assert.Equal(t, color.NRGBA{}, color.NRGBAModel.Convert(img.At(0, 5)))
+}
+
+func TestAnimation_ExportEmpty(t *testing.T) {
+	anim := &Animation{}
+	assert.Error(t, anim.ExportGIF(&bytes.Buffer{}))
+	assert.Error(t, anim.ExportAPNG(&bytes.Buffer{}))
+}
+
+func TestAnimation_Composite(t *testing.T) {
+	anim := newTestAnimation()
+	frames := anim.Composite(image.Pt(6, 8))
+	require.Len(t, frames, 2)
+
+	// Every frame has the size of the canvas, standing on its bottom center
+	for _, frame := range frames {
+		assert.Equal(t, image.Rect(0, 0, 6, 8), frame.Bitmap.Bounds())
+		assert.Equal(t, image.Rect(-3, -8, 3, 0), frameRect(frame))
+	}
+
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), frames[0].Bitmap.At(1, 2))
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), frames[0].Bitmap.At(4, 7))
+	assert.Equal(t, bitmap.ARGB1555Color(0), frames[0].Bitmap.At(0, 7))
+	assert.Equal(t, bitmap.ARGB1555Color(0), frames[0].Bitmap.At(5, 7))
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), frames[1].Bitmap.At(3, 4))
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), frames[1].Bitmap.At(4, 5))
+	assert.Equal(t, bitmap.ARGB1555Color(0), frames[1].Bitmap.At(3, 6))
+
+	// Frames larger than the canvas are clipped
+	frames = anim.Composite(image.Pt(2, 3))
+	require.Len(t, frames, 2)
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), frames[0].Bitmap.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), frames[0].Bitmap.At(1, 2))
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), frames[1].Bitmap.At(1, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0), frames[1].Bitmap.At(0, 0))
+
+	assert.Nil(t, anim.Composite(image.Point{}))
+}
+
+func TestAnimation_ExportSheet(t *testing.T) {
+	anim := newTestAnimation()
+	anim.frames = append(anim.frames, AnimationFrame{Center: image.Pt(1, 1)})
+
+	var sheet, manifest bytes.Buffer
+	require.NoError(t, anim.ExportSheet(&sheet, &manifest))
+
+	var out struct {
+		Width, Height int
+		Frames        []struct {
+			X, Y, Width, Height int
+			Center, Anchor      struct{ X, Y int }
+			Duration            int
+		}
+	}
+	require.NoError(t, json.Unmarshal(manifest.Bytes(), &out))
+	require.Len(t, out.Frames, 3)
+
+	img, err := png.Decode(&sheet)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, out.Width, out.Height), img.Bounds())
+
+	// Every frame is found in the sheet, with the point the mobile stands on
+	for i, expect := range []struct {
+		color  color.NRGBA
+		anchor image.Point
+	}{
+		{color.NRGBA{R: 0xFF, A: 0xFF}, image.Pt(2, 6)},
+		{color.NRGBA{G: 0xFF, A: 0xFF}, image.Pt(0, 4)},
+	} {
+		frame := out.Frames[i]
+		assert.Equal(t, 200, frame.Duration)
+		assert.Equal(t, anim.frames[i].Bitmap.Bounds().Size(), image.Pt(frame.Width, frame.Height))
+		assert.Equal(t, anim.frames[i].Center, image.Pt(frame.Center.X, frame.Center.Y))
+		assert.Equal(t, expect.anchor, image.Pt(frame.Anchor.X, frame.Anchor.Y))
+		assert.Equal(t, expect.color, color.NRGBAModel.Convert(img.At(frame.X, frame.Y)))
+		assert.Equal(t, expect.color, color.NRGBAModel.Convert(img.At(frame.X+frame.Width-1, frame.Y+frame.Height-1)))
+	}
+
+	// Empty frames keep their place, without any area
+	assert.Zero(t, out.Frames[2].Width)
+	assert.Equal(t, 200, out.Frames[2].Duration)
+
+	assert.Error(t, (&Animation{}).ExportSheet(&sheet, &manifest))
+}
@@ -0,0 +1,148 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"codeberg.org/go-mmap/mmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
+)
+
+// AnimationType is the kind of creature a body animates as, which defines the set of
+// actions stored for the body
+type AnimationType uint8
+
+// Animation types, as named in mobtypes.txt
+const (
+	AnimationTypeMonster    AnimationType = iota // High detail creature, with 22 actions
+	AnimationTypeSeaMonster                      // Sea creature, laid out as a monster
+	AnimationTypeAnimal                          // Low detail creature, with 13 actions
+	AnimationTypeHuman                           // Human, with 35 actions
+	AnimationTypeEquipment                       // Equipment worn by humans, laid out as a human
+)
+
+// animationTypes maps the names of mobtypes.txt to the animation types
+var animationTypes = map[string]AnimationType{
+	"MONSTER":     AnimationTypeMonster,
+	"SEA_MONSTER": AnimationTypeSeaMonster,
+	"ANIMAL":      AnimationTypeAnimal,
+	"HUMAN":       AnimationTypeHuman,
+	"EQUIPMENT":   AnimationTypeEquipment,
+}
+
+// MobFlag represents the animation properties of a body, as bit flags of mobtypes.txt
+type MobFlag uint32
+
+// Mob flags, as used by the client
+const (
+	MobFlagNone              MobFlag = 0x00000000
+	MobFlagHitWhileRunning   MobFlag = 0x00000002 // Uses the second hit action while running
+	MobFlagIdleAt8Frames     MobFlag = 0x00000004 // Idles at the eighth frame
+	MobFlagCanFly            MobFlag = 0x00000008 // Has flying actions
+	MobFlagLowGroupExtended  MobFlag = 0x00000020 // Extended set of animal actions
+	MobFlagLowGroupOffset    MobFlag = 0x00000040 // Laid out as an animal, regardless of its type
+	MobFlagPeopleGroupOffset MobFlag = 0x00000400 // Laid out as a human, regardless of its type
+	MobFlagUseUOPAnimation   MobFlag = 0x00010000 // Stored in the AnimationFrame UOP files
+)
+
+// MobType describes how a body animates, as defined in mobtypes.txt
+type MobType struct {
+	Body  int           // Body the entry applies to
+	Type  AnimationType // Kind of creature
+	Flags MobFlag       // Animation properties
+}
+
+// MobType returns the animation type and flags of the body from mobtypes.txt, or an
+// error if the client has no such file or the body is not listed in it.
+func (s *SDK) MobType(body int) (MobType, error) {
+	file, err := s.loadMobtypes()
+	if err != nil {
+		return MobType{}, fmt.Errorf("MobType: %w", err)
+	}
+
+	data, err := file.ReadFull(uint32(body))
+	switch {
+	case err != nil:
+		return MobType{}, fmt.Errorf("MobType: %w", err)
+	case len(data) < 5:
+		return MobType{}, fmt.Errorf("MobType: body %d is not defined", body)
+	}
+
+	return MobType{
+		Body:  body,
+		Type:  AnimationType(data[0]),
+		Flags: MobFlag(binary.LittleEndian.Uint32(data[1:])),
+	}, nil
+}
+
+// layout returns the offset of the first entry of the body within anim.mul, along with
+// the number of actions stored for it. Flags may lay a body out as another type.
+func (m MobType) layout() (offset, actions int) {
+	switch {
+	case m.Flags&MobFlagPeopleGroupOffset != 0:
+		return 35000 + (m.Body-400)*175, 35
+	case m.Flags&MobFlagLowGroupOffset != 0:
+		return 22000 + (m.Body-200)*65, 13
+	case m.Type == AnimationTypeMonster || m.Type == AnimationTypeSeaMonster:
+		return m.Body * 110, 22
+	case m.Type == AnimationTypeAnimal:
+		return 22000 + (m.Body-200)*65, 13
+	default:
+		return 35000 + (m.Body-400)*175, 35
+	}
+}
+
+// decodeMobtypesFile loads all entries of mobtypes.txt into mul.Entry3D, keyed by body,
+// with the animation type as a byte followed by the flags as a little-endian uint32.
+//
+// The mobtypes.txt file format is line-based text:
+//   - Text following a '#' (or empty lines) is ignored
+//   - Each other line has the form "body type flags", the type being one of MONSTER,
+//     SEA_MONSTER, ANIMAL, HUMAN or EQUIPMENT and the flags being hexadecimal
+func decodeMobtypesFile(file *mmap.File, add mul.AddFn) error {
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+
+		fields := strings.Fields(text)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) < 3:
+			return fmt.Errorf("invalid mobtypes entry on line %d: expected 3 fields, got %d", line, len(fields))
+		}
+
+		body, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid mobtypes entry on line %d: %w", line, err)
+		}
+
+		kind, ok := animationTypes[strings.ToUpper(fields[1])]
+		if !ok {
+			return fmt.Errorf("invalid mobtypes entry on line %d: unknown type %q", line, fields[1])
+		}
+
+		flags, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mobtypes entry on line %d: %w", line, err)
+		}
+
+		entry := binary.LittleEndian.AppendUint32([]byte{byte(kind)}, uint32(flags))
+		add(uint32(body), uint32(body), uint32(len(entry)), 0, entry)
+	}
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read mobtypes file: %w", err)
+	}
+	return nil
+}
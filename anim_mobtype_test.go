@@ -0,0 +1,96 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDK_MobType(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mobtypes.txt"), []byte(
+		"# Body\tType\tFlags\n1\tMONSTER\t0\n200\tANIMAL\t10008  # Flying\n400\tHUMAN\t0\n970\tEQUIPMENT\t400\n"), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	mob, err := sdk.MobType(200)
+	require.NoError(t, err)
+	assert.Equal(t, MobType{Body: 200, Type: AnimationTypeAnimal, Flags: MobFlagCanFly | MobFlagUseUOPAnimation}, mob)
+
+	mob, err = sdk.MobType(970)
+	require.NoError(t, err)
+	assert.Equal(t, AnimationTypeEquipment, mob.Type)
+	assert.Equal(t, MobFlagPeopleGroupOffset, mob.Flags)
+
+	_, err = sdk.MobType(2)
+	assert.Error(t, err, "body is not listed")
+}
+
+func TestSDK_MobType_Missing(t *testing.T) {
+	sdk, err := Open(t.TempDir())
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	_, err = sdk.MobType(1)
+	assert.Error(t, err)
+}
+
+func TestMobType_Layout(t *testing.T) {
+	tests := []struct {
+		mob     MobType
+		offset  int
+		actions int
+	}{
+		{MobType{Body: 250, Type: AnimationTypeMonster}, 250 * 110, 22},
+		{MobType{Body: 250, Type: AnimationTypeSeaMonster}, 250 * 110, 22},
+		{MobType{Body: 250, Type: AnimationTypeAnimal}, 22000 + 50*65, 13},
+		{MobType{Body: 450, Type: AnimationTypeHuman}, 35000 + 50*175, 35},
+		{MobType{Body: 450, Type: AnimationTypeMonster, Flags: MobFlagPeopleGroupOffset}, 35000 + 50*175, 35},
+		{MobType{Body: 250, Type: AnimationTypeMonster, Flags: MobFlagLowGroupOffset}, 22000 + 50*65, 13},
+	}
+
+	for _, tc := range tests {
+		offset, actions := tc.mob.layout()
+		assert.Equal(t, tc.offset, offset, "%+v", tc.mob)
+		assert.Equal(t, tc.actions, actions, "%+v", tc.mob)
+	}
+}
+
+func TestSDK_Animation_MobType(t *testing.T) {
+	dir := t.TempDir()
+	var palette [256]uint16
+	palette[1] = 0xFC00
+
+	// Body 250 would be an animal, but is laid out as a monster by mobtypes.txt
+	writeTestAnims(t, dir, 1, map[uint32][]byte{
+		animEntry(250*110, 15, 0): encodeTestAnim(palette, testAnimFrame{width: 3, height: 5, color: 1}),
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	_, err = sdk.Animation(250, 15, 0, 0, false, false)
+	assert.Error(t, err, "animals only have 13 actions")
+	require.NoError(t, sdk.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mobtypes.txt"), []byte("250\tMONSTER\t0\n"), 0644))
+	sdk, err = Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	anim, err := sdk.Animation(250, 15, 0, 0, false, false)
+	require.NoError(t, err)
+	for frame := range anim.Frames() {
+		assert.Equal(t, image.Pt(3, 5), frame.Bitmap.Bounds().Size())
+		return
+	}
+	t.Fatal("expected a frame")
+}
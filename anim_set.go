@@ -0,0 +1,89 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+)
+
+// AnimationSet loads the animations of all 8 directions of a body and action at once,
+// indexed by direction, as Animation does without a hue. Only the 5 stored directions
+// are read and decoded; the other 3 are mirrored from the decoded frames, and UOP
+// entries (which hold every direction) are read a single time.
+func (s *SDK) AnimationSet(body, action int) ([8]*Animation, error) {
+	var set [8]*Animation
+	if err := checkAnimation(body, action, 0); err != nil {
+		return set, fmt.Errorf("AnimationSet: %w", err)
+	}
+
+	fileBody, fileHue := s.translateBody(body, 0, false)
+	tint, partial, err := s.animationHue(fileHue)
+	if err != nil {
+		return set, fmt.Errorf("AnimationSet: %w", err)
+	}
+
+	// Decode the stored directions, from a single entry for the UOP files
+	var stored [5][]AnimationFrame
+	if entry, ok := s.animFrameEntry(fileBody, action); ok {
+		data := make([]byte, entry.Len())
+		if _, err := entry.ReadAt(data, 0); err != nil {
+			return set, fmt.Errorf("AnimationSet: failed to read animation entry: %w", err)
+		}
+
+		for direction := range stored {
+			if stored[direction], err = decodeAnimUOP(data, direction, tint, partial); err != nil {
+				return set, fmt.Errorf("AnimationSet: %w", err)
+			}
+		}
+	} else {
+		for direction := range stored {
+			if stored[direction], err = s.animationFrames(fileBody, action, direction, tint, partial); err != nil {
+				return set, fmt.Errorf("AnimationSet: %w", err)
+			}
+		}
+	}
+
+	for direction := range set {
+		frames := stored[min(direction, 4)]
+		if direction > 4 {
+			frames = mirrorFrames(stored[direction-(direction-4)*2])
+		}
+
+		if set[direction], err = s.newAnimation(body, frames); err != nil {
+			return set, err
+		}
+	}
+	return set, nil
+}
+
+// mirrorFrames returns the frames flipped horizontally, as drawn for the directions
+// which are not stored in the animation files
+func mirrorFrames(frames []AnimationFrame) []AnimationFrame {
+	out := make([]AnimationFrame, 0, len(frames))
+	for _, frame := range frames {
+		src := frame.Bitmap
+		if src == nil {
+			out = append(out, frame)
+			continue
+		}
+
+		width, height := src.Rect.Dx(), src.Rect.Dy()
+		dst := bitmap.NewARGB1555(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				from, to := y*src.Stride+x*2, y*dst.Stride+(width-1-x)*2
+				dst.Pix[to], dst.Pix[to+1] = src.Pix[from], src.Pix[from+1]
+			}
+		}
+
+		out = append(out, AnimationFrame{
+			Center: image.Pt(width-frame.Center.X, frame.Center.Y),
+			Bitmap: dst,
+		})
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/uop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorFrames(t *testing.T) {
+	img := bitmap.NewARGB1555(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, bitmap.ARGB1555Color(0xFC00))
+	img.Set(2, 0, bitmap.ARGB1555Color(0x801F))
+
+	out := mirrorFrames([]AnimationFrame{{Center: image.Pt(1, 4), Bitmap: img}, {}})
+	require.Len(t, out, 2)
+	assert.Equal(t, image.Pt(2, 4), out[0].Center)
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), out[0].Bitmap.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0), out[0].Bitmap.At(1, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), out[0].Bitmap.At(2, 0))
+	assert.Nil(t, out[1].Bitmap)
+}
+
+func TestSDK_AnimationSet(t *testing.T) {
+	dir := t.TempDir()
+	var palette [256]uint16
+	palette[1] = 0xFC00
+
+	// Every stored direction of body 1 in anim.mul, and of body 5 in the UOP files
+	var frames [5]testAnimFrame
+	legacy := map[uint32][]byte{}
+	for i := range frames {
+		frames[i] = testAnimFrame{center: image.Pt(i, 1), width: i + 2, height: 3, color: 1}
+		legacy[animIndex(1, 1, 0, i)] = encodeTestAnim(palette, frames[i], frames[i])
+	}
+	writeTestAnims(t, dir, 1, legacy)
+
+	entries := make([][]byte, animFrameBodies*animFrameActions)
+	entries[animFrameIndex(5, 0)] = encodeTestAnimUOP(palette, frames)
+
+	var buffer bytes.Buffer
+	require.NoError(t, uop.WriteNamed(&buffer, animFrameName, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AnimationFrame1.uop"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	for _, body := range []int{1, 5} {
+		set, err := sdk.AnimationSet(body, 0)
+		require.NoError(t, err)
+
+		// Every direction matches the animation loaded on its own
+		for direction, anim := range set {
+			expect, err := sdk.Animation(body, 0, direction, 0, false, false)
+			require.NoError(t, err)
+			require.NotNil(t, anim)
+			assert.Equal(t, slices.Collect(expect.Frames()), slices.Collect(anim.Frames()), "body %d, direction %d", body, direction)
+		}
+	}
+
+	_, err = sdk.AnimationSet(1, -1)
+	assert.Error(t, err)
+}
@@ -4,12 +4,21 @@
 package ultima
 
 import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
 	uotest "github.com/kelindar/ultima-sdk/internal/testing"
+	"github.com/kelindar/ultima-sdk/internal/uop"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoadAnimation(t *testing.T) {
@@ -77,3 +86,253 @@ func testLoadAnimation(t *testing.T, body, action, direction int) {
 		assert.True(t, called, "Expected at least one frame")
 	})
 }
+
+// animIndex returns the index of the animation entry within the index of its file
+// type, as each file lays out its bodies with a different number of actions
+func animIndex(fileType, body, action, direction int) uint32 {
+	offset, _ := animLayout(fileType, body)
+	return animEntry(offset, action, direction)
+}
+
+// testAnimFrame is a frame of a synthetic animation, filled with a single palette color
+type testAnimFrame struct {
+	center        image.Point
+	width, height int
+	color         byte
+}
+
+// encodeTestAnim encodes an animation entry of anim.mul with the palette (of opaque
+// ARGB1555 colors) and frames
+func encodeTestAnim(palette [256]uint16, frames ...testAnimFrame) []byte {
+	var out []byte
+	for _, color := range palette {
+		out = binary.LittleEndian.AppendUint16(out, color^0x8000)
+	}
+
+	var body []byte
+	lookup := binary.LittleEndian.AppendUint32(nil, uint32(len(frames)))
+	for _, frame := range frames {
+		lookup = binary.LittleEndian.AppendUint32(lookup, uint32(4+len(frames)*4+len(body)))
+		body = append(body, encodeTestFrame(frame)...)
+	}
+
+	return append(append(out, lookup...), body...)
+}
+
+// encodeTestFrame encodes a frame in the format shared by anim.mul and the UOP files,
+// with one run of pixels per line
+func encodeTestFrame(frame testAnimFrame) []byte {
+	const doubleXor = (0x200 << 22) | (0x200 << 12)
+
+	var body []byte
+	body = binary.LittleEndian.AppendUint16(body, uint16(frame.center.X))
+	body = binary.LittleEndian.AppendUint16(body, uint16(frame.center.Y))
+	body = binary.LittleEndian.AppendUint16(body, uint16(frame.width))
+	body = binary.LittleEndian.AppendUint16(body, uint16(frame.height))
+	for y := 0; y < frame.height; y++ {
+		runX := -frame.center.X + 0x200
+		runY := y - (frame.center.Y + frame.height) + 0x200
+		body = binary.LittleEndian.AppendUint32(body, uint32((runX<<22|runY<<12|frame.width)^doubleXor))
+		for x := 0; x < frame.width; x++ {
+			body = append(body, frame.color)
+		}
+	}
+	return binary.LittleEndian.AppendUint32(body, 0x7FFF7FFF)
+}
+
+// writeTestAnims writes the animation files of the file type with the given entries,
+// keyed by their index, along with an empty animdata.mul if there is none yet
+func writeTestAnims(t *testing.T, dir string, fileType int, entries map[uint32][]byte) {
+	var data, index bytes.Buffer
+	writer := mul.NewWriter(&data, &index)
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		require.NoError(t, writer.Write(key, entries[key], 0))
+	}
+
+	names := animFileNames(fileType)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, names[0]), data.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, names[1]), index.Bytes(), 0644))
+
+	if _, err := os.Stat(filepath.Join(dir, "animdata.mul")); err != nil {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "animdata.mul"), make([]byte, 548*256), 0644))
+	}
+}
+
+func TestSDK_Animation_Hue(t *testing.T) {
+	dir := t.TempDir()
+	var palette [256]uint16
+	palette[1] = 0x8000 | 10<<10 | 10<<5 | 10 // Gray
+	palette[2] = 0xFC00                       // Red
+
+	writeTestAnims(t, dir, 1, map[uint32][]byte{
+		animIndex(1, 1, 0, 0): encodeTestAnim(palette,
+			testAnimFrame{width: 1, height: 1, color: 1},
+			testAnimFrame{width: 1, height: 1, color: 2},
+		),
+	})
+	writeTestHues(t, dir, map[int]uint16{5: 0x03E0, 32: 0x001F, 2999: 0x7FE0})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "body.def"), []byte("900 {1} 33\n"), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	colors := func(body, hue int, preserveHue bool) (out []bitmap.ARGB1555Color) {
+		anim, err := sdk.Animation(body, 0, 0, hue, preserveHue, false)
+		require.NoError(t, err)
+		for frame := range anim.Frames() {
+			out = append(out, frame.Bitmap.At(0, 0).(bitmap.ARGB1555Color))
+		}
+		return out
+	}
+
+	gray, red, green, blue := bitmap.ARGB1555Color(palette[1]), bitmap.ARGB1555Color(0xFC00), bitmap.ARGB1555Color(0x83E0), bitmap.ARGB1555Color(0x801F)
+	yellow := bitmap.ARGB1555Color(0xFFE0)
+	assert.Equal(t, []bitmap.ARGB1555Color{gray, red}, colors(1, 0, false))
+	assert.Equal(t, []bitmap.ARGB1555Color{green, green}, colors(1, 5, false))
+	assert.Equal(t, []bitmap.ARGB1555Color{green, red}, colors(1, 5|0x8000, false), "only gray pixels")
+	assert.Equal(t, []bitmap.ARGB1555Color{blue, blue}, colors(900, 0, false), "hue of the substitute, entry 32")
+	assert.Equal(t, []bitmap.ARGB1555Color{gray, red}, colors(900, 0, true), "hue preserved")
+	assert.Equal(t, []bitmap.ARGB1555Color{green, green}, colors(900, 5, false), "hue given")
+	assert.Equal(t, []bitmap.ARGB1555Color{yellow, yellow}, colors(900, 2999, false), "last hue given")
+	assert.Equal(t, []bitmap.ARGB1555Color{blue, blue}, colors(900, 3000, false), "invalid hue given")
+}
+
+func TestSDK_AnimationExists(t *testing.T) {
+	dir := t.TempDir()
+	var palette [256]uint16
+	palette[1] = 0xFC00
+
+	frame := testAnimFrame{width: 2, height: 2, color: 1}
+	writeTestAnims(t, dir, 1, map[uint32][]byte{
+		animIndex(1, 1, 0, 0): encodeTestAnim(palette, frame, frame, frame),
+		animIndex(1, 1, 0, 2): encodeTestAnim(palette),
+	})
+
+	entries := make([][]byte, animFrameBodies*animFrameActions)
+	entries[animFrameIndex(5, 2)] = encodeTestAnimUOP(palette, [5]testAnimFrame{frame, frame, frame, frame, frame})
+
+	var buffer bytes.Buffer
+	require.NoError(t, uop.WriteNamed(&buffer, animFrameName, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AnimationFrame1.uop"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	tests := []struct {
+		body, action, direction int
+		exists                  bool
+		count                   int
+	}{
+		{1, 0, 0, true, 3},
+		{1, 0, 1, false, 0},
+		{1, 0, 2, true, 0}, // Present, without any frame
+		{5, 2, 0, true, 1},
+		{5, 2, 7, true, 1},
+		{5, 3, 0, false, 0},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.exists, sdk.AnimationExists(tc.body, tc.action, tc.direction), "%+v", tc)
+
+		count, err := sdk.AnimationFrameCount(tc.body, tc.action, tc.direction)
+		require.NoError(t, err, "%+v", tc)
+		assert.Equal(t, tc.count, count, "%+v", tc)
+	}
+
+	// Actions without any frame are not listed
+	assert.Equal(t, []int{0}, sdk.AnimationActions(1))
+	assert.Equal(t, []int{2}, sdk.AnimationActions(5))
+	assert.Empty(t, sdk.AnimationActions(2))
+	assert.Empty(t, sdk.AnimationActions(-1))
+
+	assert.False(t, sdk.AnimationExists(-1, 0, 0))
+	_, err = sdk.AnimationFrameCount(1, 0, 8)
+	assert.Error(t, err)
+}
+
+func TestSDK_AnimData(t *testing.T) {
+	dir := t.TempDir()
+	data := make([]byte, 548*2)
+	entry := func(id int, frames []int8, interval, start uint8) {
+		offset := (id/8)*548 + 4 + (id%8)*68
+		for i, frame := range frames {
+			data[offset+i] = byte(frame)
+		}
+		data[offset+65] = uint8(len(frames))
+		data[offset+66] = interval
+		data[offset+67] = start
+	}
+
+	entry(3, []int8{0, 1, 2}, 4, 1)
+	entry(10, []int8{0, -1}, 2, 0)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "animdata.mul"), data, 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	anim, err := sdk.AnimData(10)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(2), anim.FrameCount)
+	assert.Equal(t, uint8(2), anim.FrameInterval)
+	assert.Equal(t, int8(-1), anim.FrameData[1])
+
+	anim, err = sdk.AnimData(4)
+	require.NoError(t, err)
+	assert.Zero(t, anim.FrameCount)
+
+	_, err = sdk.AnimData(16)
+	assert.Error(t, err)
+	_, err = sdk.AnimData(-1)
+	assert.Error(t, err)
+
+	// Only the entries with frames are iterated
+	found := map[int]uint8{}
+	for id, entry := range sdk.AnimDatas() {
+		found[id] = entry.FrameCount
+	}
+	assert.Equal(t, map[int]uint8{3: 3, 10: 2}, found)
+}
+
+// sizedReader is a reader which records the largest read made from it
+type sizedReader struct {
+	*bytes.Reader
+	largest int
+}
+
+func (r *sizedReader) ReadAt(p []byte, off int64) (int, error) {
+	r.largest = max(r.largest, len(p))
+	return r.Reader.ReadAt(p, off)
+}
+
+func TestDecodeAnimMUL(t *testing.T) {
+	var palette [256]uint16
+	palette[1] = 0xFC00
+	palette[2] = 0x801F
+
+	data := encodeTestAnim(palette,
+		testAnimFrame{center: image.Pt(1, 2), width: 20, height: 30, color: 1},
+		testAnimFrame{center: image.Pt(3, 4), width: 10, height: 5, color: 2},
+	)
+
+	reader := &sizedReader{Reader: bytes.NewReader(data)}
+	frames, err := decodeAnimMUL(reader, len(data), 0, nil, false)
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+	assert.Equal(t, image.Pt(1, 2), frames[0].Center)
+	assert.Equal(t, image.Pt(20, 30), frames[0].Bitmap.Bounds().Size())
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), frames[0].Bitmap.At(0, 0))
+	assert.Equal(t, image.Pt(3, 4), frames[1].Center)
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), frames[1].Bitmap.At(9, 4))
+
+	// The entry is read in pieces, never as a whole
+	assert.Equal(t, len(encodeTestFrame(testAnimFrame{width: 20, height: 30})), reader.largest)
+
+	// Truncated entries fail rather than decoding garbage
+	_, err = decodeAnimMUL(bytes.NewReader(data[:len(data)-10]), len(data), 0, nil, false)
+	assert.Error(t, err)
+	_, err = decodeAnimMUL(nil, 0, 0, nil, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/kelindar/ultima-sdk/internal/uofile"
+)
+
+const (
+	animFrameFiles   = 6    // Maximum number of AnimationFrameN.uop files
+	animFrameBodies  = 2048 // Number of bodies which can be stored in the UOP files
+	animFrameActions = 100  // Number of actions which can be stored per body
+	animFrameMin     = 50   // Minimum number of frames of an entry, once padded
+)
+
+// animFrameName returns the name of the entry of the AnimationFrameN.uop files with the
+// given index, as laid out by animFrameIndex
+func animFrameName(index int) string {
+	return fmt.Sprintf("build/animationlegacyframe/%06d/%02d.bin", index/animFrameActions, index%animFrameActions)
+}
+
+// animFrameIndex returns the index of the entry of the body and action within the
+// AnimationFrameN.uop files, which store every direction of an action in one entry
+func animFrameIndex(body, action int) uint32 {
+	return uint32(body*animFrameActions + action)
+}
+
+// animFrameEntry looks up the entry of the body and action in the AnimationFrameN.uop
+// files, returning false if the client has no such files or none of them holds it
+func (s *SDK) animFrameEntry(body, action int) (uofile.Entry, bool) {
+	if body >= animFrameBodies || action >= animFrameActions {
+		return nil, false
+	}
+
+	for n := 1; n <= animFrameFiles; n++ {
+		file, err := s.loadAnimFrames(n)
+		if err != nil {
+			continue
+		}
+
+		if entry, err := file.Entry(animFrameIndex(body, action)); err == nil && entry != nil && entry.Len() > 0 {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// decodeAnimUOP decodes the frames of the direction from an entry of the
+// AnimationFrameN.uop files. The format is as follows:
+//   - A 32-byte header, followed by the number of frames and the offset of their table
+//   - For every frame, a 16-byte table entry with the group, the 1-based frame ID, 8
+//     unknown bytes and the offset of its data, relative to the table entry
+//   - The data of a frame is its own palette of 256 colors (0 being transparent),
+//     followed by the frame in the same format as anim.mul
+//
+// The frames of the 5 stored directions follow each other, the others being mirrored.
+// The palettes are recolored if a hue is given.
+func decodeAnimUOP(data []byte, direction int, hue *Hue, partial bool) ([]AnimationFrame, error) {
+	const paletteSize = 512
+	offsets, err := animFrameOffsets(data, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]AnimationFrame, 0, len(offsets))
+	palette := make([]uint16, 256)
+	for _, offset := range offsets {
+		if offset <= 0 || offset+paletteSize > len(data) {
+			continue
+		}
+
+		for i := range palette {
+			if color := binary.LittleEndian.Uint16(data[offset+i*2:]); color != 0 {
+				palette[i] = color | 0x8000
+			} else {
+				palette[i] = 0
+			}
+		}
+
+		if hue != nil {
+			applyHuePalette(palette, hue, partial)
+		}
+
+		center, img, err := decodeFrame(palette, data[offset+paletteSize:], direction > 4)
+		if err != nil || img == nil {
+			continue
+		}
+		frames = append(frames, AnimationFrame{Center: center, Bitmap: img})
+	}
+	return frames, nil
+}
+
+// animFrameOffsets returns the offsets of the data of the frames of the direction, from
+// the header and frame table of an entry of the AnimationFrameN.uop files (the data of
+// the frames is not needed). Missing frames have an offset of 0.
+func animFrameOffsets(data []byte, direction int) ([]int, error) {
+	const headerSize, tableEntry = 32, 16
+	if len(data) < headerSize+8 {
+		return nil, fmt.Errorf("invalid frame data length: %d", len(data))
+	}
+
+	frameCount := int(binary.LittleEndian.Uint32(data[headerSize:]))
+	dataStart := int(binary.LittleEndian.Uint32(data[headerSize+4:]))
+	if frameCount < 0 || dataStart < 0 || dataStart+frameCount*tableEntry > len(data) {
+		return nil, fmt.Errorf("invalid frame table: %d frames at %d", frameCount, dataStart)
+	}
+
+	// Offsets of the data of every frame, missing frames being left empty
+	offsets := make([]int, 0, max(frameCount, animFrameMin))
+	for i := 0; i < frameCount; i++ {
+		start := dataStart + i*tableEntry
+		frameID := int(int16(binary.LittleEndian.Uint16(data[start+2:])))
+		for len(offsets)+1 < frameID {
+			offsets = append(offsets, 0)
+		}
+
+		offsets = append(offsets, start+int(binary.LittleEndian.Uint32(data[start+12:])))
+	}
+
+	for len(offsets) < animFrameMin {
+		offsets = append(offsets, 0)
+	}
+
+	// Only 5 directions are stored, the others being mirrored
+	stored := direction
+	if direction > 4 {
+		stored = direction - (direction-4)*2
+	}
+
+	perDirection := len(offsets) / 5
+	return offsets[stored*perDirection : (stored+1)*perDirection], nil
+}
+
+// animFrameHeader reads the header and frame table of an entry of the
+// AnimationFrameN.uop files, without the data of the frames
+func animFrameHeader(entry uofile.Entry) ([]byte, error) {
+	const headerSize = 32
+	header := make([]byte, min(headerSize+8, entry.Len()))
+	if _, err := entry.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+
+	if len(header) < headerSize+8 {
+		return header, nil
+	}
+
+	frameCount := int(binary.LittleEndian.Uint32(header[headerSize:]))
+	dataStart := int(binary.LittleEndian.Uint32(header[headerSize+4:]))
+	size := dataStart + frameCount*16
+	if size <= len(header) || size > entry.Len() || frameCount < 0 {
+		return header, nil // Left for animFrameOffsets to reject
+	}
+
+	data := make([]byte, size)
+	if _, err := entry.ReadAt(data, 0); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
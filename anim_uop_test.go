@@ -0,0 +1,90 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/uop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeTestAnimUOP encodes an entry of AnimationFrameN.uop with one frame for each of
+// the 5 stored directions, sharing the palette
+func encodeTestAnimUOP(palette [256]uint16, frames [5]testAnimFrame) []byte {
+	const dataStart, perDirection = 40, 10
+
+	out := make([]byte, dataStart, dataStart+len(frames)*16)
+	binary.LittleEndian.PutUint32(out[32:], uint32(len(frames)))
+	binary.LittleEndian.PutUint32(out[36:], dataStart)
+
+	var body []byte
+	for i, frame := range frames {
+		start := dataStart + i*16
+		entry := make([]byte, 16)
+		binary.LittleEndian.PutUint16(entry[2:], uint16(i*perDirection+1))
+		binary.LittleEndian.PutUint32(entry[12:], uint32(dataStart+len(frames)*16+len(body)-start))
+		out = append(out, entry...)
+
+		for _, color := range palette {
+			body = binary.LittleEndian.AppendUint16(body, color&0x7FFF)
+		}
+		body = append(body, encodeTestFrame(frame)...)
+	}
+
+	return append(out, body...)
+}
+
+func TestSDK_Animation_UOP(t *testing.T) {
+	dir := t.TempDir()
+	var palette [256]uint16
+	palette[1] = 0xFC00
+
+	var frames [5]testAnimFrame
+	for i := range frames {
+		frames[i] = testAnimFrame{width: i + 1, height: 2, color: 1}
+	}
+
+	entries := make([][]byte, animFrameBodies*animFrameActions)
+	entries[animFrameIndex(5, 2)] = encodeTestAnimUOP(palette, frames)
+
+	var buffer bytes.Buffer
+	require.NoError(t, uop.WriteNamed(&buffer, animFrameName, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AnimationFrame2.uop"), buffer.Bytes(), 0644))
+
+	// The legacy file only holds the bodies which are not in the UOP files
+	writeTestAnims(t, dir, 1, map[uint32][]byte{
+		animIndex(1, 1, 0, 0): encodeTestAnim(palette, testAnimFrame{width: 7, height: 7, color: 1}),
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	first := func(body, action, direction int) AnimationFrame {
+		anim, err := sdk.Animation(body, action, direction, 0, false, false)
+		require.NoError(t, err)
+		for frame := range anim.Frames() {
+			return frame
+		}
+		return AnimationFrame{}
+	}
+
+	assert.Equal(t, image.Pt(1, 2), first(5, 2, 0).Bitmap.Bounds().Size())
+	assert.Equal(t, image.Pt(3, 2), first(5, 2, 2).Bitmap.Bounds().Size())
+	assert.Equal(t, image.Pt(5, 2), first(5, 2, 4).Bitmap.Bounds().Size())
+	assert.Equal(t, image.Pt(3, 2), first(5, 2, 6).Bitmap.Bounds().Size(), "mirrored from direction 2")
+	assert.Equal(t, image.Pt(7, 7), first(1, 0, 0).Bitmap.Bounds().Size(), "fallback to anim.mul")
+
+	// The palette of the UOP files is not inverted, 0 being transparent
+	frame := first(5, 2, 0)
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), frame.Bitmap.At(0, 0))
+}
@@ -8,9 +8,20 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
+	"io"
 	"iter"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
 	"github.com/kelindar/ultima-sdk/internal/uofile"
 )
 
@@ -35,10 +46,111 @@ type Art struct {
 	Image image.Image // Decoded image for the tile
 }
 
+// Bitmap returns the decoded ARGB1555 pixel buffer of the art without copying, so
+// renderers can blit its Pix rows directly. The buffer is shared with the tile and
+// must not be modified. Returns nil if the image is missing or is not ARGB1555.
+func (a Art) Bitmap() *bitmap.ARGB1555 {
+	img, _ := a.Image.(*bitmap.ARGB1555)
+	return img
+}
+
+// Bitmap is the ARGB1555 image the SDK decodes art and gumps into. A zero Bitmap may be
+// given to DecodeArtInto or DecodeGumpInto, which grow it as needed.
+type Bitmap = bitmap.ARGB1555
+
+// resetBitmap resizes the bitmap to the given dimensions, reusing its pixel buffer if
+// large enough, and clears it to transparent
+func resetBitmap(dst *bitmap.ARGB1555, width, height int) {
+	size := width * height * 2
+	if cap(dst.Pix) < size {
+		dst.Pix = make([]byte, size)
+	} else {
+		dst.Pix = dst.Pix[:size]
+		clear(dst.Pix)
+	}
+
+	dst.Stride = width * 2
+	dst.Rect = image.Rect(0, 0, width, height)
+}
+
+// ContentBounds returns the bounding box of the opaque pixels of the art image,
+// or an empty rectangle if the image is missing or fully transparent.
+func (a Art) ContentBounds() image.Rectangle {
+	if a.Image == nil {
+		return image.Rectangle{}
+	}
+
+	return opaqueBounds(a.Image)
+}
+
+// Trim returns the art image cropped to its opaque pixels. The returned image
+// shares its pixels with the original one whenever the image supports it.
+func (a Art) Trim() image.Image {
+	if a.Image == nil {
+		return nil
+	}
+
+	bounds := a.ContentBounds()
+	if sub, ok := a.Image.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(bounds)
+	}
+
+	out := bitmap.NewARGB1555(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.Set(x, y, a.Image.At(x, y))
+		}
+	}
+	return out
+}
+
+// opaqueBounds computes the bounding box of all non-transparent pixels
+func opaqueBounds(img image.Image) image.Rectangle {
+	opaque := func(x, y int) bool {
+		_, _, _, a := img.At(x, y).RGBA()
+		return a != 0
+	}
+
+	// Fast path for ARGB1555 images, where a zero value is transparent
+	if src, ok := img.(*bitmap.ARGB1555); ok {
+		opaque = func(x, y int) bool {
+			offset := src.PixOffset(x, y)
+			return src.Pix[offset] != 0 || src.Pix[offset+1] != 0
+		}
+	}
+
+	var out image.Rectangle
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if opaque(x, y) {
+				out = out.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return out
+}
+
 // Land represents a complete land tile with both art and tile data.
 type Land struct {
 	Art
 	*LandInfo
+	sdk *SDK
+}
+
+// Texture loads the texture mapped onto this land tile, as referenced by its
+// TextureID. It returns nil if the land tile has no texture.
+func (l *Land) Texture() (*Texture, error) {
+	switch {
+	case l.sdk == nil:
+		return nil, fmt.Errorf("land tile %d is not attached to an SDK", l.ID)
+	case l.LandInfo == nil || l.TextureID == 0:
+		return nil, nil
+	}
+
+	return l.sdk.Texture(int(l.TextureID))
 }
 
 // Item represents a complete static item with both art and tile data.
@@ -62,17 +174,7 @@ func (s *SDK) Land(id int) (*Land, error) {
 
 	// Read the land tile data
 	info, _ := s.landInfo(id)
-	artTile, err := uofile.Decode(file, uint32(id), func(data []byte, extra uint64) (Art, error) {
-		img, err := decodeLandImage(data)
-		if err != nil {
-			return Art{}, err
-		}
-
-		return Art{
-			ID:    id,
-			Image: img,
-		}, nil
-	})
+	artTile, err := s.decodeArt(file, id)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +182,7 @@ func (s *SDK) Land(id int) (*Land, error) {
 	return &Land{
 		Art:      artTile,
 		LandInfo: info,
+		sdk:      s,
 	}, nil
 }
 
@@ -101,8 +204,160 @@ func (s *SDK) Item(id int) (*Item, error) {
 
 	// Read the static tile data
 	info, _ := s.staticInfo(id)
-	artTile, err := uofile.Decode(file, uint32(artID), func(data []byte, extra uint64) (Art, error) {
-		img, err := decodeStaticImage(data)
+	artTile, err := s.decodeArt(file, artID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Item{
+		Art:      artTile,
+		ItemInfo: info,
+	}, nil
+}
+
+// ItemExists reports whether static art is present for the given item ID. It
+// only consults the index entry and does not follow art.def remapping.
+func (s *SDK) ItemExists(id int) bool {
+	entry, err := s.itemEntry(id)
+	return err == nil && entry != nil && entry.Len() > 0
+}
+
+// ItemSize returns the dimensions of the static art for the given item ID by
+// reading only the art header, without decoding any pixels.
+func (s *SDK) ItemSize(id int) (width, height int, err error) {
+	entry, err := s.itemEntry(id)
+	switch {
+	case err != nil:
+		return 0, 0, err
+	case entry == nil || entry.Len() < 8:
+		return 0, 0, fmt.Errorf("%w: static tile %d", ErrNoArtData, id)
+	}
+
+	var header [8]byte // Unused header (4), width (2), height (2)
+	if _, err := entry.ReadAt(header[:], 0); err != nil {
+		return 0, 0, err
+	}
+
+	width = int(binary.LittleEndian.Uint16(header[4:6]))
+	height = int(binary.LittleEndian.Uint16(header[6:8]))
+	return width, height, nil
+}
+
+// FindArtByName returns the IDs of the land tiles and static items whose tile data
+// name contains the given substring (case-insensitive) and that have art available.
+func (s *SDK) FindArtByName(substr string) (lands, items []int, err error) {
+	file, err := s.loadArt()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	substr = strings.ToLower(substr)
+	for id, info := range s.LandInfos() {
+		if strings.Contains(strings.ToLower(info.Name), substr) && hasArtEntry(file, id) {
+			lands = append(lands, id)
+		}
+	}
+
+	index, err := s.itemIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, id := range index.named(substr) {
+		if id <= maxValidArtIndex-staticTileMinID && hasArtEntry(file, id+staticTileMinID) {
+			items = append(items, id)
+		}
+	}
+
+	return lands, items, nil
+}
+
+// UnusedArt returns an iterator over the art IDs whose index entries are missing or
+// empty, which are free slots for custom art. Land tiles use IDs below 0x4000 and
+// statics are offset by 0x4000, as in art.mul.
+func (s *SDK) UnusedArt() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		file, err := s.loadArt()
+		if err != nil {
+			return
+		}
+
+		for id := 0; id <= maxValidArtIndex; id++ {
+			if !hasArtEntry(file, id) && !yield(id) {
+				return
+			}
+		}
+	}
+}
+
+// hasArtEntry checks whether the art file has a non-empty entry for the art ID
+func hasArtEntry(file *uofile.File, artID int) bool {
+	entry, err := file.Entry(uint32(artID))
+	return err == nil && entry != nil && entry.Len() > 0
+}
+
+// itemEntry returns the raw art file entry for a static item
+func (s *SDK) itemEntry(id int) (uofile.Entry, error) {
+	if id < 0 || id > maxValidArtIndex-staticTileMinID {
+		return nil, fmt.Errorf("%w: static tile ID %d out of range [0-%d]",
+			ErrInvalidTileID, id, maxValidArtIndex-staticTileMinID)
+	}
+
+	file, err := s.loadArt()
+	if err != nil {
+		return nil, err
+	}
+
+	return file.Entry(uint32(id + staticTileMinID))
+}
+
+// decodeArt decodes the art tile with the given ID. If the entry is empty or
+// missing, it falls back through the substitutes listed in art.def (if present),
+// following the remapping chain like UOFiddler does.
+func (s *SDK) decodeArt(file *uofile.File, artID int) (Art, error) {
+	tile, err := decodeArtEntry(file, artID)
+	if tile.Image != nil {
+		return tile, err
+	}
+
+	def, derr := s.loadDef("art.def")
+	if derr != nil {
+		return tile, err // No remapping available
+	}
+
+	visited := map[int]bool{artID: true}
+	for queue := []int{artID}; len(queue) > 0; queue = queue[1:] {
+		data, derr := def.ReadFull(uint32(queue[0]))
+		if derr != nil || len(data) < 8 {
+			continue
+		}
+
+		for _, target := range defEntry(data).Targets() {
+			if target < 0 || target > maxValidArtIndex || visited[target] {
+				continue
+			}
+
+			visited[target] = true
+			if alt, aerr := decodeArtEntry(file, target); aerr == nil && alt.Image != nil {
+				alt.ID = artID
+				return alt, nil
+			}
+			queue = append(queue, target)
+		}
+	}
+
+	return tile, err
+}
+
+// decodeArtEntry decodes a single art entry, as a land tile or static depending on its ID
+func decodeArtEntry(file *uofile.File, artID int) (Art, error) {
+	decode := decodeStaticImage
+	if artID < landTileMax {
+		decode = decodeLandImage
+	}
+
+	return uofile.Decode(file, uint32(artID), func(data []byte, extra uint64) (Art, error) {
+		img, err := decode(data)
 		if err != nil {
 			return Art{}, err
 		}
@@ -112,14 +367,121 @@ func (s *SDK) Item(id int) (*Item, error) {
 			Image: img,
 		}, nil
 	})
+}
+
+// DecodeArtInto decodes the art with the given art ID into the bitmap, resizing it to
+// the dimensions of the art while reusing its pixel buffer, so that batch renderers
+// avoid allocating an image per tile. Land tiles use IDs below 0x4000 and statics are
+// offset by 0x4000, as in art.mul. Unlike Land and Item, art.def is not applied.
+func (s *SDK) DecodeArtInto(dst *Bitmap, artID int) error {
+	if artID < 0 || artID > maxValidArtIndex {
+		return fmt.Errorf("%w: art ID %d out of range [0-%d]", ErrInvalidTileID, artID, maxValidArtIndex)
+	}
+
+	file, err := s.loadArt()
+	if err != nil {
+		return err
+	}
+
+	decode := decodeStaticImageInto
+	if artID < landTileMax {
+		decode = decodeLandImageInto
+	}
+
+	found, err := uofile.Decode(file, uint32(artID), func(data []byte, _ uint64) (bool, error) {
+		return true, decode(dst, data)
+	})
+	switch {
+	case err != nil:
+		return err
+	case !found:
+		return fmt.Errorf("%w: art ID %d", ErrNoArtData, artID)
+	default:
+		return nil
+	}
+}
+
+// ItemWithHue retrieves a static art tile by its ID, recolored through the hue
+// with the given index (as accepted by Hue). A hue of 0 leaves the art unchanged.
+// Only grayscale pixels are recolored if partial is set or if the item has the
+// PartialHue tile flag, matching how the client hues such items.
+func (s *SDK) ItemWithHue(id, hue int, partial bool) (*Item, error) {
+	item, err := s.Item(id)
+	if err != nil || hue == 0 || item.Image == nil {
+		return item, err
+	}
+
+	h, err := s.Hue(hue)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Item{
-		Art:      artTile,
-		ItemInfo: info,
-	}, nil
+	if item.ItemInfo != nil && item.Flags&TileFlagPartialHue != 0 {
+		partial = true
+	}
+
+	item.Image = ApplyHue(item.Image, h, partial)
+	return item, nil
+}
+
+// ItemAnimation describes the animation of a static item, as defined in animdata.mul.
+// Each frame is a separate static art tile, shown for Interval before the next one.
+type ItemAnimation struct {
+	ID       int           // Item ID of the animated static
+	Start    int           // Frame index the animation starts at
+	Interval time.Duration // Delay between two frames
+	Frames   []ItemFrame   // Frames of the animation, in playback order
+}
+
+// ItemFrame represents a single frame of an animated static item.
+type ItemFrame struct {
+	ID    int         // Item ID of the art shown for this frame
+	Image image.Image // Decoded art of the frame
+}
+
+// ItemAnimation retrieves the animation of a static item (e.g. torches or fountains),
+// along with the decoded art of every frame.
+func (s *SDK) ItemAnimation(id int) (*ItemAnimation, error) {
+	if id < 0 || id+staticTileMinID > maxValidArtIndex {
+		return nil, fmt.Errorf("%w: item ID %d out of range", ErrInvalidTileID, id)
+	}
+
+	file, err := s.loadAnimdata()
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := readAnimdata(file, id)
+	switch {
+	case err != nil:
+		return nil, err
+	case entry.FrameCount == 0:
+		return nil, fmt.Errorf("item %d is not animated", id)
+	}
+
+	frameCount := min(int(entry.FrameCount), len(entry.FrameData))
+	anim := &ItemAnimation{
+		ID:       id,
+		Start:    int(entry.FrameStart),
+		Interval: time.Duration(entry.FrameInterval) * 100 * time.Millisecond,
+		Frames:   make([]ItemFrame, 0, frameCount),
+	}
+
+	// Each frame is stored as a signed offset from the animated item ID
+	for _, offset := range entry.FrameData[:frameCount] {
+		frameID := id + int(offset)
+		item, err := s.Item(frameID)
+		if err != nil {
+			return nil, fmt.Errorf("item %d animation frame %d: %w", id, frameID, err)
+		}
+
+		anim.Frames = append(anim.Frames, ItemFrame{
+			ID:    frameID,
+			Image: item.Image,
+		})
+	}
+
+	return anim, nil
 }
 
 // Lands returns an iterator over all available land art tiles.
@@ -154,16 +516,190 @@ func (s *SDK) Items() iter.Seq[*Item] {
 	}
 }
 
+// LandsParallel returns an iterator over all available land art tiles, like Lands,
+// but decodes tiles ahead of the consumer using the given number of workers (defaults
+// to the number of CPUs if not positive). Tiles are still yielded in ID order.
+func (s *SDK) LandsParallel(workers int) iter.Seq[*Land] {
+	return decodeParallel(landTileMax, workers, func(i int) (*Land, bool) {
+		tile, err := s.Land(i)
+		return tile, tile != nil && err == nil
+	})
+}
+
+// ItemsParallel returns an iterator over all available static art tiles, like Items,
+// but decodes tiles ahead of the consumer using the given number of workers (defaults
+// to the number of CPUs if not positive). Tiles are still yielded in ID order.
+func (s *SDK) ItemsParallel(workers int) iter.Seq[*Item] {
+	return decodeParallel(maxValidArtIndex-staticTileMinID+1, workers, func(i int) (*Item, bool) {
+		tile, err := s.Item(i)
+		return tile, tile != nil && err == nil
+	})
+}
+
+// decodeParallel decodes the entries [0, count) on a pool of workers and yields the
+// successfully decoded ones in order. Decoding runs at most a few entries ahead of the
+// consumer, and stops as soon as the consumer stops iterating.
+func decodeParallel[T any](count, workers int, decode func(i int) (T, bool)) iter.Seq[T] {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type result struct {
+		value T
+		ok    bool
+	}
+
+	type job struct {
+		index int
+		out   chan result
+	}
+
+	return func(yield func(T) bool) {
+		done := make(chan struct{})
+		defer close(done)
+
+		// Schedule the jobs, keeping track of their order in the pending queue
+		jobs := make(chan job)
+		pending := make(chan chan result, workers*4)
+		go func() {
+			defer close(jobs)
+			defer close(pending)
+			for i := 0; i < count; i++ {
+				out := make(chan result, 1)
+				select {
+				case pending <- out:
+				case <-done:
+					return
+				}
+
+				select {
+				case jobs <- job{index: i, out: out}:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		for i := 0; i < workers; i++ {
+			go func() {
+				for j := range jobs {
+					value, ok := decode(j.index)
+					j.out <- result{value: value, ok: ok}
+				}
+			}()
+		}
+
+		// Consume the results in order
+		for out := range pending {
+			if r := <-out; r.ok && !yield(r.value) {
+				return
+			}
+		}
+	}
+}
+
+// ExportArt decodes all land and static art tiles and writes them as PNG files
+// into the given directory, named land_XXXX.png and item_XXXX.png after their
+// hexadecimal tile ID. Tiles are processed concurrently by the given number of
+// workers (defaults to the number of CPUs if not positive). The optional progress
+// callback is invoked after each tile with the number of tiles done so far and
+// the total number of tiles; calls are serialized.
+func (s *SDK) ExportArt(dir string, workers int, progress func(done, total int)) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	file, err := s.loadArt()
+	if err != nil {
+		return err
+	}
+
+	ids := slices.Collect(file.Entries())
+	queue := make(chan uint32)
+	failed := atomic.Bool{}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	var done int
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				err := s.exportArtTile(dir, int(id))
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+					failed.Store(true)
+				}
+				if done++; progress != nil {
+					progress(done, len(ids))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		if failed.Load() {
+			break
+		}
+		queue <- id
+	}
+
+	close(queue)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// exportArtTile decodes a single art tile and writes it as a PNG file. Tiles
+// that cannot be decoded are skipped, as they are by Lands() and Items().
+func (s *SDK) exportArtTile(dir string, id int) error {
+	var img image.Image
+	var name string
+	switch {
+	case id < landTileMax:
+		if tile, err := s.Land(id); err == nil {
+			img, name = tile.Image, fmt.Sprintf("land_%04X.png", id)
+		}
+	default:
+		if tile, err := s.Item(id - staticTileMinID); err == nil {
+			img, name = tile.Image, fmt.Sprintf("item_%04X.png", id-staticTileMinID)
+		}
+	}
+
+	if img == nil {
+		return nil // Empty or invalid slot
+	}
+
+	return writePNG(filepath.Join(dir, name), img)
+}
+
 // decodeLandImage converts raw land art data into an image.Image.
 // Land art is always 44x44 pixels. The format is essentially a run-length
 // encoded 44x44 image where each 2-byte value represents a color index.
 func decodeLandImage(data []byte) (image.Image, error) {
+	img := new(bitmap.ARGB1555)
+	if err := decodeLandImageInto(img, data); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// decodeLandImageInto decodes raw land art data into the bitmap, resized to 44x44
+func decodeLandImageInto(img *bitmap.ARGB1555, data []byte) error {
 	if len(data) < landTileRawLength {
-		return nil, fmt.Errorf("%w: land art data too short, expected %d bytes, got %d",
+		return fmt.Errorf("%w: land art data too short, expected %d bytes, got %d",
 			ErrInvalidArtData, landTileRawLength, len(data))
 	}
 
-	img := bitmap.NewARGB1555(image.Rect(0, 0, landTileSize, landTileSize))
+	resetBitmap(img, landTileSize, landTileSize)
 	offset := 0
 	for y := 0; y < 22; y++ {
 		// Start at the center-top of the tile and work outward
@@ -172,7 +708,7 @@ func decodeLandImage(data []byte) (image.Image, error) {
 		pixelsInRow := (y * 2) + 2 // Number of pixels in this row
 		for x := 0; x < pixelsInRow; x++ {
 			if offset+1 >= len(data) {
-				return nil, fmt.Errorf("%w: land art data truncated in first half", ErrInvalidArtData)
+				return fmt.Errorf("%w: land art data truncated in first half", ErrInvalidArtData)
 			}
 
 			// Read 16-bit color value (little-endian)
@@ -195,7 +731,7 @@ func decodeLandImage(data []byte) (image.Image, error) {
 		pixelsInRow := 44 - (2 * y) // Corrected: Number of pixels for this row
 		for x := 0; x < pixelsInRow; x++ {
 			if offset+1 >= len(data) {
-				return nil, fmt.Errorf("%w: land art data truncated in second half", ErrInvalidArtData)
+				return fmt.Errorf("%w: land art data truncated in second half", ErrInvalidArtData)
 			}
 
 			// Read 16-bit color value (little-endian)
@@ -210,15 +746,25 @@ func decodeLandImage(data []byte) (image.Image, error) {
 		}
 	}
 
-	return img, nil
+	return nil
 }
 
 // decodeStaticImage converts raw static art data into an image.Image.
 // Static art has a header with dimensions, followed by a lookup table and
 // run-length encoded pixel data.
 func decodeStaticImage(data []byte) (image.Image, error) {
+	img := new(bitmap.ARGB1555)
+	if err := decodeStaticImageInto(img, data); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// decodeStaticImageInto decodes raw static art data into the bitmap, resized to the
+// dimensions of the art
+func decodeStaticImageInto(img *bitmap.ARGB1555, data []byte) error {
 	if len(data) < 8 { // Header (4) + Width (2) + Height (2)
-		return nil, fmt.Errorf("%w: static art data too short for header", ErrInvalidArtData)
+		return fmt.Errorf("%w: static art data too short for header", ErrInvalidArtData)
 	}
 
 	// Skip the 4 byte art entry header
@@ -232,30 +778,27 @@ func decodeStaticImage(data []byte) (image.Image, error) {
 
 	// Sanity check on dimensions
 	if width <= 0 || height <= 0 || width > 2048 || height > 2048 { // Max typical UO art dim is ~512, 2048 is very safe.
-		return nil, fmt.Errorf("%w: invalid dimensions %dx%d", ErrInvalidArtData, width, height)
+		return fmt.Errorf("%w: invalid dimensions %dx%d", ErrInvalidArtData, width, height)
 	}
 
-	// Read lookup table. Each entry is a WORD offset relative to the start of the RLE data block.
-	lookupTableValues := make([]int, height)
+	// Locate the lookup table. Each entry is a WORD offset relative to the start of the RLE data block.
+	lookupTable := offset
 	lookupTableByteSize := height * 2
 	if offset+lookupTableByteSize > len(data) {
-		return nil, fmt.Errorf("%w: static art data too short for lookup table (needs %d bytes, has %d remaining from offset %d, total data %d)", ErrInvalidArtData, lookupTableByteSize, len(data)-offset, offset, len(data))
-	}
-	for i := 0; i < height; i++ {
-		lookupTableValues[i] = int(binary.LittleEndian.Uint16(data[offset : offset+2]))
-		offset += 2
+		return fmt.Errorf("%w: static art data too short for lookup table (needs %d bytes, has %d remaining from offset %d, total data %d)", ErrInvalidArtData, lookupTableByteSize, len(data)-offset, offset, len(data))
 	}
+	offset += lookupTableByteSize
 
 	// 'offset' is now at the start of the RLE data block.
 	// This corresponds to 'start' in the C# reference (UOFiddler Art.cs GetStatic).
 	rleDataBlockStartOffset := offset
 
-	img := bitmap.NewARGB1555(image.Rect(0, 0, width, height))
+	resetBitmap(img, width, height)
 
 	for y := 0; y < height; y++ {
 		// Calculate the starting byte offset for this line's RLE data, relative to the beginning of 'data'.
-		// lookupTableValues[y] is a WORD offset from rleDataBlockStartOffset.
-		lineRleStartOffsetInData := rleDataBlockStartOffset + (lookupTableValues[y] * 2)
+		// The lookup table entry of the line is a WORD offset from rleDataBlockStartOffset.
+		lineRleStartOffsetInData := rleDataBlockStartOffset + (int(binary.LittleEndian.Uint16(data[lookupTable+y*2:])) * 2)
 		currentReadOffset := lineRleStartOffsetInData
 
 		x := 0 // Current horizontal pixel position in the output image for this line
@@ -263,7 +806,7 @@ func decodeStaticImage(data []byte) (image.Image, error) {
 			// Ensure we can read xPixelOffset (2 bytes) and runLength (2 bytes) for the RLE pair.
 			if currentReadOffset+4 > len(data) {
 				if x < width { // If we still expect pixels on this line.
-					return nil, fmt.Errorf("%w: static art data truncated before RLE pair header at y=%d, x_cursor=%d. Need 4 bytes from readOffset=%d, dataLen=%d", ErrInvalidArtData, y, x, currentReadOffset, len(data))
+					return fmt.Errorf("%w: static art data truncated before RLE pair header at y=%d, x_cursor=%d. Need 4 bytes from readOffset=%d, dataLen=%d", ErrInvalidArtData, y, x, currentReadOffset, len(data))
 				}
 				break // Line ends if x >= width or truncated past expected content.
 			}
@@ -282,7 +825,7 @@ func decodeStaticImage(data []byte) (image.Image, error) {
 			for i := 0; i < runLength; i++ {
 				// Ensure we can read 2 bytes for color data.
 				if currentReadOffset+2 > len(data) {
-					return nil, fmt.Errorf("%w: static art data truncated during pixel data run at y=%d, x_target_pixel=%d (x_cursor_at_run_start=%d, pixel_in_run=%d). Need 2 bytes from readOffset=%d, dataLen=%d. RunLength was %d", ErrInvalidArtData, y, x+i, x, i, runLength, currentReadOffset, len(data))
+					return fmt.Errorf("%w: static art data truncated during pixel data run at y=%d, x_target_pixel=%d (x_cursor_at_run_start=%d, pixel_in_run=%d). Need 2 bytes from readOffset=%d, dataLen=%d. RunLength was %d", ErrInvalidArtData, y, x+i, x, i, runLength, currentReadOffset, len(data))
 				}
 
 				colorValue := binary.LittleEndian.Uint16(data[currentReadOffset : currentReadOffset+2])
@@ -297,5 +840,157 @@ func decodeStaticImage(data []byte) (image.Image, error) {
 		}
 	}
 
-	return img, nil
+	return nil
+}
+
+// WriteArt encodes the given art tiles and writes them as an art.mul/artidx.mul
+// pair. Tiles with an ID below 0x4000 are encoded as 44x44 land tiles, the rest
+// as static art. Tiles may be given in any order, missing IDs are written as empty
+// index entries.
+func WriteArt(artMul, artIdx io.Writer, tiles []Art) error {
+	sorted := slices.Clone(tiles)
+	slices.SortFunc(sorted, func(a, b Art) int {
+		return a.ID - b.ID
+	})
+
+	w := mul.NewWriter(artMul, artIdx)
+	for i, tile := range sorted {
+		switch {
+		case tile.ID < 0 || tile.ID > maxValidArtIndex:
+			return fmt.Errorf("%w: art ID %d out of range [0-%d]", ErrInvalidTileID, tile.ID, maxValidArtIndex)
+		case i > 0 && sorted[i-1].ID == tile.ID:
+			return fmt.Errorf("%w: duplicate art ID %d", ErrInvalidTileID, tile.ID)
+		}
+
+		var data []byte
+		var err error
+		if tile.ID < landTileMax {
+			data, err = encodeLandImage(tile.Image)
+		} else {
+			data, err = encodeStaticImage(tile.Image)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode art %d: %w", tile.ID, err)
+		}
+
+		if err := w.Write(uint32(tile.ID), data, 0); err != nil {
+			return err
+		}
+	}
+
+	return w.Pad(maxValidArtIndex + 1)
+}
+
+// encodeLandImage converts a 44x44 image into the raw land art layout, which
+// stores only the pixels inside the diamond, row by row.
+func encodeLandImage(img image.Image) ([]byte, error) {
+	if img == nil {
+		return nil, fmt.Errorf("%w: land art image is nil", ErrInvalidArtData)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() != landTileSize || bounds.Dy() != landTileSize {
+		return nil, fmt.Errorf("%w: land art must be %dx%d, got %dx%d",
+			ErrInvalidArtData, landTileSize, landTileSize, bounds.Dx(), bounds.Dy())
+	}
+
+	out := make([]byte, 0, landTileRawLength)
+	for y := 0; y < landTileSize; y++ {
+		// Top half grows by 2 pixels per row, bottom half shrinks by 2
+		startX, pixelsInRow := 22-y-1, (y*2)+2
+		if y >= 22 {
+			startX, pixelsInRow = y-22, 44-(2*(y-22))
+		}
+
+		for x := startX; x < startX+pixelsInRow; x++ {
+			value, _ := encodeARGB1555(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			out = binary.LittleEndian.AppendUint16(out, value)
+		}
+	}
+
+	// Pad to the raw land length expected by the decoder
+	return append(out, make([]byte, landTileRawLength-len(out))...), nil
+}
+
+// encodeStaticImage converts an image into the static art layout: a header, the
+// dimensions, a lookup table of line offsets and the run-length encoded lines.
+func encodeStaticImage(img image.Image) ([]byte, error) {
+	if img == nil {
+		return nil, fmt.Errorf("%w: static art image is nil", ErrInvalidArtData)
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || width > 2048 || height > 2048 {
+		return nil, fmt.Errorf("%w: invalid dimensions %dx%d", ErrInvalidArtData, width, height)
+	}
+
+	// Encode every line as runs of opaque pixels, each run prefixed by the number
+	// of transparent pixels before it, and terminated by an empty run.
+	lookup := make([]int, height)
+	var runs []byte
+	for y := 0; y < height; y++ {
+		lookup[y] = len(runs) / 2
+
+		x := 0
+		for x < width {
+			start := x
+			for start < width {
+				if _, opaque := encodeARGB1555(img.At(bounds.Min.X+start, bounds.Min.Y+y)); opaque {
+					break
+				}
+				start++
+			}
+			if start == width {
+				break
+			}
+
+			end := start
+			for end < width {
+				if _, opaque := encodeARGB1555(img.At(bounds.Min.X+end, bounds.Min.Y+y)); !opaque {
+					break
+				}
+				end++
+			}
+
+			runs = binary.LittleEndian.AppendUint16(runs, uint16(start-x))
+			runs = binary.LittleEndian.AppendUint16(runs, uint16(end-start))
+			for px := start; px < end; px++ {
+				value, _ := encodeARGB1555(img.At(bounds.Min.X+px, bounds.Min.Y+y))
+				runs = binary.LittleEndian.AppendUint16(runs, value)
+			}
+			x = end
+		}
+
+		// End of line marker
+		runs = binary.LittleEndian.AppendUint16(runs, 0)
+		runs = binary.LittleEndian.AppendUint16(runs, 0)
+	}
+
+	if len(runs)/2 > 0xFFFF {
+		return nil, fmt.Errorf("%w: static art too large to encode (%d words)", ErrInvalidArtData, len(runs)/2)
+	}
+
+	out := make([]byte, 0, 8+height*2+len(runs))
+	out = binary.LittleEndian.AppendUint32(out, 1234) // Unused header, as written by UOFiddler
+	out = binary.LittleEndian.AppendUint16(out, uint16(width))
+	out = binary.LittleEndian.AppendUint16(out, uint16(height))
+	for _, offset := range lookup {
+		out = binary.LittleEndian.AppendUint16(out, uint16(offset))
+	}
+	return append(out, runs...), nil
+}
+
+// encodeARGB1555 converts a color into its 15-bit value as stored in the art files,
+// along with whether the pixel is opaque.
+func encodeARGB1555(c color.Color) (uint16, bool) {
+	if _, _, _, a := c.RGBA(); a < 0x8000 {
+		return 0, false
+	}
+
+	if v, ok := c.(bitmap.ARGB1555Color); ok {
+		return uint16(v) & 0x7FFF, true
+	}
+
+	return uint16(bitmap.ARGB1555Model.Convert(c).(bitmap.ARGB1555Color)) & 0x7FFF, true
 }
@@ -0,0 +1,151 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"cmp"
+	"image"
+	"math/bits"
+	"slices"
+)
+
+// PerceptualHash computes a 64-bit difference hash of the image. The image is reduced
+// to a 9x8 grid of premultiplied luminance and each bit records whether a cell is
+// brighter than its right neighbour, so visually identical images produce hashes with
+// a small (usually zero) Hamming distance, regardless of minor color differences.
+func PerceptualHash(img image.Image) uint64 {
+	const cols, rows = 9, 8
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return 0
+	}
+
+	// Average the luminance of every cell of the grid
+	var grid [rows][cols]uint64
+	w, h := bounds.Dx(), bounds.Dy()
+	for cy := 0; cy < rows; cy++ {
+		y0, y1 := cy*h/rows, max((cy+1)*h/rows, cy*h/rows+1)
+		for cx := 0; cx < cols; cx++ {
+			x0, x1 := cx*w/cols, max((cx+1)*w/cols, cx*w/cols+1)
+
+			var sum, count uint64
+			for y := y0; y < min(y1, h); y++ {
+				for x := x0; x < min(x1, w); x++ {
+					r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+					sum += (299*uint64(r) + 587*uint64(g) + 114*uint64(b)) / 1000
+					count++
+				}
+			}
+
+			if count > 0 {
+				grid[cy][cx] = sum / count
+			}
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols-1; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// DuplicateItems finds clusters of visually identical static art. Two items belong to
+// the same cluster if their images have the same size and their perceptual hashes
+// differ by at most maxDistance bits. Each cluster holds at least two item IDs in
+// ascending order, and clusters are ordered by their first ID.
+func (s *SDK) DuplicateItems(maxDistance int) ([][]int, error) {
+	if _, err := s.loadArt(); err != nil {
+		return nil, err
+	}
+
+	var prints []fingerprint
+	for item := range s.ItemsParallel(0) {
+		if item.Image != nil && !item.Image.Bounds().Empty() {
+			prints = append(prints, fingerprint{
+				id:   item.ID - staticTileMinID,
+				size: item.Image.Bounds().Size(),
+				hash: PerceptualHash(item.Image),
+			})
+		}
+	}
+
+	return clusterPrints(prints, maxDistance), nil
+}
+
+// fingerprint identifies an image by its size and perceptual hash
+type fingerprint struct {
+	id   int
+	size image.Point
+	hash uint64
+}
+
+// clusterPrints groups the fingerprints of the same size whose hashes differ by at
+// most maxDistance bits, returning only the clusters with more than one member
+func clusterPrints(prints []fingerprint, maxDistance int) [][]int {
+	// Only images of the same size can be duplicates, so compare within size groups
+	slices.SortFunc(prints, func(a, b fingerprint) int {
+		if c := cmp.Compare(a.size.X, b.size.X); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.size.Y, b.size.Y); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+
+	// Union-find over the fingerprints, merging every pair that is close enough
+	parent := make([]int, len(prints))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for start := 0; start < len(prints); {
+		end := start + 1
+		for end < len(prints) && prints[end].size == prints[start].size {
+			end++
+		}
+
+		for i := start; i < end; i++ {
+			for j := i + 1; j < end; j++ {
+				if bits.OnesCount64(prints[i].hash^prints[j].hash) <= maxDistance {
+					parent[find(j)] = find(i)
+				}
+			}
+		}
+		start = end
+	}
+
+	groups := make(map[int][]int)
+	for i, p := range prints {
+		root := find(i)
+		groups[root] = append(groups[root], p.id)
+	}
+
+	var clusters [][]int
+	for _, ids := range groups {
+		if len(ids) > 1 {
+			slices.Sort(ids)
+			clusters = append(clusters, ids)
+		}
+	}
+
+	slices.SortFunc(clusters, func(a, b []int) int {
+		return cmp.Compare(a[0], b[0])
+	})
+	return clusters
+}
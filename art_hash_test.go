@@ -0,0 +1,76 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerceptualHash(t *testing.T) {
+	gradient := func(shift uint8) image.Image {
+		img := image.NewNRGBA(image.Rect(0, 0, 36, 32))
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 36; x++ {
+				v := uint8(x*7) + shift
+				if (x/4+y/4)%2 == 0 {
+					v = 255 - v
+				}
+				img.Set(x, y, color.NRGBA{R: v, G: v, B: v, A: 0xFF})
+			}
+		}
+		return img
+	}
+
+	a := PerceptualHash(gradient(0))
+	assert.Equal(t, a, PerceptualHash(gradient(0)))
+	assert.LessOrEqual(t, bits.OnesCount64(a^PerceptualHash(gradient(1))), 4)
+
+	// A completely different image
+	other := bitmap.NewARGB1555(image.Rect(0, 0, 36, 32))
+	for x := 0; x < 36; x += 8 {
+		for y := 0; y < 32; y++ {
+			other.Set(x, y, bitmap.ARGB1555Color(0xFFFF))
+		}
+	}
+	assert.Greater(t, bits.OnesCount64(a^PerceptualHash(other)), 8)
+	assert.Equal(t, uint64(0), PerceptualHash(image.NewNRGBA(image.Rectangle{})))
+}
+
+func TestClusterPrints(t *testing.T) {
+	small, large := image.Pt(10, 10), image.Pt(20, 20)
+	prints := []fingerprint{
+		{id: 5, size: small, hash: 0b1111},
+		{id: 1, size: small, hash: 0b1110},
+		{id: 3, size: large, hash: 0b1111}, // Same hash, different size
+		{id: 2, size: small, hash: 0xFF00},
+		{id: 7, size: large, hash: 0b1111},
+		{id: 9, size: small, hash: 0xFF00},
+	}
+
+	assert.Equal(t, [][]int{{1, 5}, {2, 9}, {3, 7}}, clusterPrints(prints, 1))
+	assert.Equal(t, [][]int{{2, 9}, {3, 7}}, clusterPrints(prints, 0))
+}
+
+func TestSDK_DuplicateItems(t *testing.T) {
+	runWith(t, func(sdk *SDK) {
+		clusters, err := sdk.DuplicateItems(0)
+		require.NoError(t, err)
+
+		for _, cluster := range clusters {
+			require.GreaterOrEqual(t, len(cluster), 2)
+			a, err := sdk.Item(cluster[0])
+			require.NoError(t, err)
+			b, err := sdk.Item(cluster[1])
+			require.NoError(t, err)
+			assert.Equal(t, a.Image.Bounds().Size(), b.Image.Bounds().Size())
+		}
+	})
+}
@@ -4,8 +4,16 @@
 package ultima
 
 import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -65,6 +73,119 @@ func TestArt(t *testing.T) {
 			assert.Less(t, bounds.Dy(), 1024) // Reasonable size limit
 		})
 
+		t.Run("LandTexture", func(t *testing.T) {
+			tile, err := sdk.Land(3)
+			require.NoError(t, err)
+			require.NotZero(t, tile.TextureID)
+
+			tex, err := tile.Texture()
+			assert.NoError(t, err)
+			require.NotNil(t, tex)
+			assert.Equal(t, int(tile.TextureID), tex.Index)
+		})
+
+		t.Run("ExportArt", func(t *testing.T) {
+			dir := t.TempDir()
+			calls, total := 0, 0
+			err := sdk.ExportArt(dir, 8, func(done, n int) {
+				calls++
+				total = n
+				assert.Equal(t, calls, done)
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, total, calls)
+
+			files, err := os.ReadDir(dir)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, files)
+			assert.FileExists(t, filepath.Join(dir, "land_0000.png"))
+		})
+
+		t.Run("ItemProbes", func(t *testing.T) {
+			assert.True(t, sdk.ItemExists(0x0E3D))
+			assert.False(t, sdk.ItemExists(-1))
+
+			tile, err := sdk.Item(0x0E3D)
+			require.NoError(t, err)
+
+			assert.NotNil(t, tile.Bitmap())
+			assert.Same(t, tile.Image, tile.Bitmap())
+
+			w, h, err := sdk.ItemSize(0x0E3D)
+			assert.NoError(t, err)
+			assert.Equal(t, tile.Image.Bounds().Dx(), w)
+			assert.Equal(t, tile.Image.Bounds().Dy(), h)
+		})
+
+		t.Run("ParallelIterators", func(t *testing.T) {
+			var expect, actual []int
+			for tile := range sdk.Lands() {
+				expect = append(expect, tile.ID)
+			}
+			for tile := range sdk.LandsParallel(4) {
+				actual = append(actual, tile.ID)
+			}
+			assert.Equal(t, expect, actual)
+
+			expect, actual = nil, nil
+			for tile := range sdk.Items() {
+				expect = append(expect, tile.ID)
+			}
+			for tile := range sdk.ItemsParallel(0) {
+				actual = append(actual, tile.ID)
+			}
+			assert.Equal(t, expect, actual)
+		})
+
+		t.Run("FindArtByName", func(t *testing.T) {
+			lands, items, err := sdk.FindArtByName("GRASS")
+			require.NoError(t, err)
+			assert.NotEmpty(t, lands)
+			assert.NotEmpty(t, items)
+
+			for _, id := range items {
+				assert.True(t, sdk.ItemExists(id))
+			}
+
+			lands, items, err = sdk.FindArtByName("this tile does not exist")
+			assert.NoError(t, err)
+			assert.Empty(t, lands)
+			assert.Empty(t, items)
+		})
+
+		t.Run("UnusedArt", func(t *testing.T) {
+			var count int
+			for id := range sdk.UnusedArt() {
+				if id >= staticTileMinID {
+					assert.False(t, sdk.ItemExists(id-staticTileMinID))
+				}
+				count++
+			}
+			assert.Greater(t, count, 0)
+		})
+
+		t.Run("ItemAnimation", func(t *testing.T) {
+			ids, err := sdk.ItemsWithFlags(TileFlagAnimation)
+			require.NoError(t, err)
+			require.NotEmpty(t, ids)
+
+			var anim *ItemAnimation
+			for _, id := range ids {
+				if anim, err = sdk.ItemAnimation(id); err == nil {
+					break
+				}
+			}
+
+			require.NotNil(t, anim, "expected at least one animated item")
+			assert.NotEmpty(t, anim.Frames)
+			for _, frame := range anim.Frames {
+				assert.NotNil(t, frame.Image)
+			}
+
+			_, err = sdk.ItemAnimation(-1)
+			assert.ErrorIs(t, err, ErrInvalidTileID)
+		})
+
 		t.Run("InvalidIDs", func(t *testing.T) {
 
 			// Test invalid land ID
@@ -111,6 +232,13 @@ func TestArt(t *testing.T) {
 	})
 }
 
+func TestLand_TextureDetached(t *testing.T) {
+	tile := &Land{Art: Art{ID: 1}, LandInfo: &LandInfo{TextureID: 1}}
+	tex, err := tile.Texture()
+	assert.Error(t, err)
+	assert.Nil(t, tex)
+}
+
 // TestInvalidImageData tests decoding functions with various bad data
 func TestInvalidImageData(t *testing.T) {
 	t.Run("InvalidLandArt", func(t *testing.T) {
@@ -154,3 +282,229 @@ func TestInvalidImageData(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestArt_EncodeRoundTrip(t *testing.T) {
+	t.Run("Land", func(t *testing.T) {
+		raw := make([]byte, landTileRawLength)
+		for i := 0; i < len(raw); i += 2 {
+			binary.LittleEndian.PutUint16(raw[i:], uint16(i/2)&0x7FFF)
+		}
+
+		img, err := decodeLandImage(raw)
+		require.NoError(t, err)
+
+		encoded, err := encodeLandImage(img)
+		require.NoError(t, err)
+		assert.Len(t, encoded, landTileRawLength)
+		assert.Equal(t, raw[:2024], encoded[:2024])
+	})
+
+	t.Run("LandInvalidSize", func(t *testing.T) {
+		_, err := encodeLandImage(bitmap.NewARGB1555(image.Rect(0, 0, 10, 10)))
+		assert.Error(t, err)
+	})
+
+	t.Run("Static", func(t *testing.T) {
+		img := bitmap.NewARGB1555(image.Rect(0, 0, 5, 3))
+		img.Set(1, 0, bitmap.ARGB1555Color(0xFC00))
+		img.Set(2, 0, bitmap.ARGB1555Color(0x8000))
+		img.Set(4, 0, bitmap.ARGB1555Color(0x801F))
+		img.Set(0, 2, bitmap.ARGB1555Color(0x83E0))
+
+		encoded, err := encodeStaticImage(img)
+		require.NoError(t, err)
+
+		decoded, err := decodeStaticImage(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, img.Bounds(), decoded.Bounds())
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 5; x++ {
+				assert.Equal(t, img.At(x, y), decoded.At(x, y), "pixel (%d,%d)", x, y)
+			}
+		}
+	})
+}
+
+func TestWriteArt(t *testing.T) {
+	land := bitmap.NewARGB1555(image.Rect(0, 0, 44, 44))
+	land.Set(22, 22, bitmap.ARGB1555Color(0xFC00))
+	static := bitmap.NewARGB1555(image.Rect(0, 0, 2, 2))
+	static.Set(1, 1, bitmap.ARGB1555Color(0x801F))
+
+	var artMul, artIdx bytes.Buffer
+	err := WriteArt(&artMul, &artIdx, []Art{
+		{ID: 0x4001, Image: static},
+		{ID: 2, Image: land},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, (maxValidArtIndex+1)*12, artIdx.Len())
+
+	// Land tile at ID 2 is written first
+	idx := artIdx.Bytes()
+	assert.Equal(t, uint32(0xFFFFFFFF), binary.LittleEndian.Uint32(idx[0:4]))
+	assert.Equal(t, uint32(0), binary.LittleEndian.Uint32(idx[24:28]))
+	assert.Equal(t, uint32(landTileRawLength), binary.LittleEndian.Uint32(idx[28:32]))
+
+	// Static tile follows the land tile
+	entry := idx[0x4001*12:]
+	offset := binary.LittleEndian.Uint32(entry[0:4])
+	length := binary.LittleEndian.Uint32(entry[4:8])
+	assert.Equal(t, uint32(landTileRawLength), offset)
+
+	img, err := decodeStaticImage(artMul.Bytes()[offset : offset+length])
+	require.NoError(t, err)
+	assert.Equal(t, static.At(1, 1), img.At(1, 1))
+
+	// Duplicates and out-of-range IDs are rejected
+	assert.Error(t, WriteArt(&artMul, &artIdx, []Art{{ID: 1, Image: land}, {ID: 1, Image: land}}))
+	assert.Error(t, WriteArt(&artMul, &artIdx, []Art{{ID: 0x10000, Image: static}}))
+}
+
+func TestSDK_DecodeArtInto(t *testing.T) {
+	dir := t.TempDir()
+	land := bitmap.NewARGB1555(image.Rect(0, 0, 44, 44))
+	land.Set(22, 22, bitmap.ARGB1555Color(0xFC00))
+	static := bitmap.NewARGB1555(image.Rect(0, 0, 3, 2))
+	static.Set(1, 1, bitmap.ARGB1555Color(0x801F))
+
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{{ID: 2, Image: land}, {ID: 0x4001, Image: static}}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "artidx.mul"), artIdx.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	var dst Bitmap
+	require.NoError(t, sdk.DecodeArtInto(&dst, 2))
+	assert.Equal(t, image.Rect(0, 0, 44, 44), dst.Bounds())
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), dst.At(22, 22))
+	buffer := &dst.Pix[0]
+
+	// The smaller static reuses the buffer of the land tile, cleared
+	require.NoError(t, sdk.DecodeArtInto(&dst, 0x4001))
+	assert.Equal(t, image.Rect(0, 0, 3, 2), dst.Bounds())
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), dst.At(1, 1))
+	assert.Equal(t, bitmap.ARGB1555Color(0), dst.At(0, 0))
+	assert.Same(t, buffer, &dst.Pix[0])
+
+	assert.Error(t, sdk.DecodeArtInto(&dst, 0x4002))
+	assert.Error(t, sdk.DecodeArtInto(&dst, -1))
+}
+
+func TestArt_DefRemap(t *testing.T) {
+	dir := t.TempDir()
+	static := bitmap.NewARGB1555(image.Rect(0, 0, 2, 2))
+	static.Set(1, 1, bitmap.ARGB1555Color(0x801F))
+
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{{ID: 0x4003, Image: static}}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "artidx.mul"), artIdx.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "art.def"), []byte(
+		"# remap chain: 0x4001 -> 0x4002 (empty) -> 0x4003\n"+
+			"16385 {16386} 0\n"+
+			"16386 {16387} 0\n"+
+			"16390 {16391} 0\n"+
+			"16391 {16390} 0\n",
+	), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	file, err := sdk.loadArt()
+	require.NoError(t, err)
+
+	// Follows the chain to the first non-empty substitute
+	tile, err := sdk.decodeArt(file, 0x4001)
+	assert.NoError(t, err)
+	assert.Equal(t, 0x4001, tile.ID)
+	require.NotNil(t, tile.Image)
+	assert.Equal(t, static.At(1, 1), tile.Image.At(1, 1))
+
+	// Cycles terminate and yield an empty tile
+	tile, err = sdk.decodeArt(file, 0x4006)
+	assert.NoError(t, err)
+	assert.Nil(t, tile.Image)
+}
+
+func TestArt_Bitmap(t *testing.T) {
+	img := bitmap.NewARGB1555(image.Rect(0, 0, 4, 4))
+	assert.Same(t, img, Art{Image: img}.Bitmap())
+	assert.Nil(t, Art{}.Bitmap())
+	assert.Nil(t, Art{Image: image.NewRGBA(image.Rect(0, 0, 4, 4))}.Bitmap())
+}
+
+func TestArt_ContentBounds(t *testing.T) {
+	img := bitmap.NewARGB1555(image.Rect(0, 0, 10, 8))
+	img.Set(2, 3, bitmap.ARGB1555Color(0x801F))
+	img.Set(6, 5, bitmap.ARGB1555Color(0xFC00))
+
+	art := Art{ID: 1, Image: img}
+	assert.Equal(t, image.Rect(2, 3, 7, 6), art.ContentBounds())
+
+	trimmed := art.Trim()
+	assert.Equal(t, image.Rect(2, 3, 7, 6), trimmed.Bounds())
+	assert.Equal(t, img.At(6, 5), trimmed.At(6, 5))
+
+	// Generic images use the alpha channel
+	rgba := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	rgba.Pix[rgba.PixOffset(1, 2)+3] = 0xFF
+	assert.Equal(t, image.Rect(1, 2, 2, 3), Art{Image: rgba}.ContentBounds())
+
+	// Empty and fully transparent images
+	assert.True(t, Art{}.ContentBounds().Empty())
+	assert.Nil(t, Art{}.Trim())
+	assert.True(t, Art{Image: bitmap.NewARGB1555(image.Rect(0, 0, 4, 4))}.ContentBounds().Empty())
+}
+
+func TestDecodeParallel(t *testing.T) {
+	odd := func(i int) (int, bool) {
+		return i, i%2 == 1
+	}
+
+	var out []int
+	for v := range decodeParallel(100, 8, odd) {
+		out = append(out, v)
+	}
+
+	require.Len(t, out, 50)
+	for i, v := range out {
+		assert.Equal(t, i*2+1, v)
+	}
+
+	// Stops early without leaking or blocking
+	out = out[:0]
+	for v := range decodeParallel(100000, 0, odd) {
+		if out = append(out, v); len(out) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []int{1, 3, 5}, out)
+}
+
+func TestArt_UnusedArt(t *testing.T) {
+	dir := t.TempDir()
+	static := bitmap.NewARGB1555(image.Rect(0, 0, 2, 2))
+	static.Set(1, 1, bitmap.ARGB1555Color(0x801F))
+
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{
+		{ID: 0x4000, Image: static},
+		{ID: 0x4002, Image: static},
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "artidx.mul"), artIdx.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	unused := slices.Collect(sdk.UnusedArt())
+	assert.Len(t, unused, maxValidArtIndex+1-2)
+	assert.NotContains(t, unused, 0x4000)
+	assert.NotContains(t, unused, 0x4002)
+	assert.Contains(t, unused, 0x4001)
+}
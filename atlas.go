@@ -0,0 +1,204 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Atlas is a set of images packed into one or more sheets, along with the location
+// of every source image within those sheets.
+type Atlas struct {
+	Sheets  []*image.NRGBA // Packed sheets
+	Sprites []AtlasSprite  // Location of each packed image, sorted by ID
+}
+
+// AtlasSprite describes where a source image was placed within an atlas.
+type AtlasSprite struct {
+	ID     int `json:"id"`     // Identifier of the source image
+	Sheet  int `json:"sheet"`  // Index of the sheet containing the image
+	X      int `json:"x"`      // Left position within the sheet
+	Y      int `json:"y"`      // Top position within the sheet
+	Width  int `json:"width"`  // Width of the image
+	Height int `json:"height"` // Height of the image
+}
+
+// Rect returns the rectangle covered by the sprite within its sheet.
+func (s AtlasSprite) Rect() image.Rectangle {
+	return image.Rect(s.X, s.Y, s.X+s.Width, s.Y+s.Height)
+}
+
+// atlasManifest is the JSON document written alongside the atlas sheets
+type atlasManifest struct {
+	Sheets  []string      `json:"sheets"`
+	Sprites []AtlasSprite `json:"sprites"`
+}
+
+// PackAtlas packs the images into as many sheets as needed, with each sheet being at
+// most size x size pixels. Images are packed in rows, tallest first, which works well
+// for the similarly sized art and gumps of the client. Nil images are skipped.
+func PackAtlas(images map[int]image.Image, size int) (*Atlas, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid atlas size: %d", size)
+	}
+
+	type source struct {
+		id  int
+		img image.Image
+	}
+
+	sources := make([]source, 0, len(images))
+	for id, img := range images {
+		switch {
+		case img == nil || img.Bounds().Empty():
+			continue
+		case img.Bounds().Dx() > size || img.Bounds().Dy() > size:
+			return nil, fmt.Errorf("image %d (%v) does not fit in a %dx%d atlas", id, img.Bounds().Size(), size, size)
+		}
+
+		sources = append(sources, source{id: id, img: img})
+	}
+
+	// Tallest first, so each row wastes as little space as possible
+	slices.SortFunc(sources, func(a, b source) int {
+		if c := cmp.Compare(b.img.Bounds().Dy(), a.img.Bounds().Dy()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+
+	// Place every image, starting a new row or sheet when the current one is full
+	atlas := &Atlas{Sprites: make([]AtlasSprite, 0, len(sources))}
+	extents := []image.Point{}
+	x, y, rowHeight := 0, 0, 0
+	for _, src := range sources {
+		w, h := src.img.Bounds().Dx(), src.img.Bounds().Dy()
+		if x+w > size {
+			x, y, rowHeight = 0, y+rowHeight, 0
+		}
+		if len(extents) == 0 || y+h > size {
+			x, y, rowHeight = 0, 0, 0
+			extents = append(extents, image.Point{})
+		}
+
+		sheet := len(extents) - 1
+		atlas.Sprites = append(atlas.Sprites, AtlasSprite{
+			ID: src.id, Sheet: sheet,
+			X: x, Y: y, Width: w, Height: h,
+		})
+
+		extents[sheet].X = max(extents[sheet].X, x+w)
+		extents[sheet].Y = max(extents[sheet].Y, y+h)
+		rowHeight = max(rowHeight, h)
+		x += w
+	}
+
+	// Allocate the sheets to the extent actually used and draw the images
+	atlas.Sheets = make([]*image.NRGBA, len(extents))
+	for i, extent := range extents {
+		atlas.Sheets[i] = image.NewNRGBA(image.Rectangle{Max: extent})
+	}
+
+	for i, sprite := range atlas.Sprites {
+		src := sources[i].img
+		draw.Draw(atlas.Sheets[sprite.Sheet], sprite.Rect(), src, src.Bounds().Min, draw.Src)
+	}
+
+	slices.SortFunc(atlas.Sprites, func(a, b AtlasSprite) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return atlas, nil
+}
+
+// Save writes the atlas into the directory as PNG sheets named "<name>_<n>.png" and
+// a JSON manifest named "<name>.json" describing the location of every image.
+func (a *Atlas) Save(dir, name string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create atlas directory: %w", err)
+	}
+
+	manifest := atlasManifest{
+		Sheets:  make([]string, 0, len(a.Sheets)),
+		Sprites: a.Sprites,
+	}
+
+	for i, sheet := range a.Sheets {
+		filename := fmt.Sprintf("%s_%d.png", name, i)
+		if err := writePNG(filepath.Join(dir, filename), sheet); err != nil {
+			return err
+		}
+
+		manifest.Sheets = append(manifest.Sheets, filename)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode atlas manifest: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, name+".json"), data, 0644)
+}
+
+// ArtAtlas packs the art tiles with the given art IDs into an atlas. Land tiles use
+// IDs below 0x4000 and statics are offset by 0x4000, as in art.mul. Tiles without
+// art data are skipped.
+func (s *SDK) ArtAtlas(ids []int, size int) (*Atlas, error) {
+	file, err := s.loadArt()
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(map[int]image.Image, len(ids))
+	for _, id := range ids {
+		if id < 0 || id > maxValidArtIndex {
+			return nil, fmt.Errorf("%w: art ID %d out of range", ErrInvalidTileID, id)
+		}
+
+		if tile, err := s.decodeArt(file, id); err == nil && tile.Image != nil {
+			images[id] = tile.Image
+		}
+	}
+
+	return PackAtlas(images, size)
+}
+
+// GumpAtlas packs the gumps with the given IDs into an atlas, so that they can be served
+// as a few textures rather than as individual images. Missing gumps are skipped.
+func (s *SDK) GumpAtlas(ids []int, size int) (*Atlas, error) {
+	images := make(map[int]image.Image, len(ids))
+	for _, id := range ids {
+		if id < 0 || id >= 0xFFFF {
+			return nil, fmt.Errorf("gump ID %d out of range [0-%d]", id, 0xFFFF-1)
+		}
+
+		if gump, err := s.Gump(id); err == nil && gump.Image != nil {
+			images[id] = gump.Image
+		}
+	}
+
+	return PackAtlas(images, size)
+}
+
+// writePNG encodes the image as a PNG file at the given path
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackAtlas(t *testing.T) {
+	images := make(map[int]image.Image)
+	for i := 0; i < 10; i++ {
+		img := image.NewNRGBA(image.Rect(0, 0, 10+i, 20-i))
+		img.Set(0, 0, color.NRGBA{R: uint8(i), A: 0xFF})
+		images[i] = img
+	}
+	images[99] = nil // Skipped
+
+	atlas, err := PackAtlas(images, 32)
+	require.NoError(t, err)
+	require.Len(t, atlas.Sprites, 10)
+	assert.Greater(t, len(atlas.Sheets), 1)
+
+	for i, a := range atlas.Sprites {
+		assert.Equal(t, i, a.ID)
+		assert.Equal(t, images[a.ID].Bounds().Size(), a.Rect().Size())
+
+		// Sprites fit in their sheet and contain the source pixels
+		sheet := atlas.Sheets[a.Sheet]
+		assert.True(t, a.Rect().In(sheet.Bounds()))
+		assert.Equal(t, color.NRGBA{R: uint8(i), A: 0xFF}, sheet.At(a.X, a.Y))
+
+		// Sprites on the same sheet never overlap
+		for _, b := range atlas.Sprites[i+1:] {
+			if a.Sheet == b.Sheet {
+				assert.False(t, a.Rect().Overlaps(b.Rect()), "%d overlaps %d", a.ID, b.ID)
+			}
+		}
+	}
+}
+
+func TestPackAtlas_Invalid(t *testing.T) {
+	_, err := PackAtlas(nil, 0)
+	assert.Error(t, err)
+
+	_, err = PackAtlas(map[int]image.Image{
+		1: image.NewNRGBA(image.Rect(0, 0, 64, 8)),
+	}, 32)
+	assert.Error(t, err)
+}
+
+func TestAtlas_Save(t *testing.T) {
+	atlas, err := PackAtlas(map[int]image.Image{
+		1: image.NewNRGBA(image.Rect(0, 0, 8, 8)),
+		2: image.NewNRGBA(image.Rect(0, 0, 4, 4)),
+	}, 16)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, atlas.Save(dir, "atlas"))
+	assert.FileExists(t, filepath.Join(dir, "atlas_0.png"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "atlas.json"))
+	require.NoError(t, err)
+
+	var manifest atlasManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, []string{"atlas_0.png"}, manifest.Sheets)
+	assert.Equal(t, atlas.Sprites, manifest.Sprites)
+}
+
+func TestSDK_ArtAtlas(t *testing.T) {
+	runWith(t, func(sdk *SDK) {
+		atlas, err := sdk.ArtAtlas([]int{0x0003, 0x4000 + 0x0E3D}, 1024)
+		require.NoError(t, err)
+		assert.Len(t, atlas.Sprites, 2)
+		assert.Len(t, atlas.Sheets, 1)
+
+		_, err = sdk.ArtAtlas([]int{-1}, 1024)
+		assert.ErrorIs(t, err, ErrInvalidTileID)
+	})
+}
+
+func TestSDK_GumpAtlas(t *testing.T) {
+	dir := t.TempDir()
+	writeTestGumps(t, dir, map[int]*bitmap.ARGB1555{
+		1: newTestGump(8, 8, image.Rect(0, 0, 8, 8), 0xFC00),
+		5: newTestGump(4, 2, image.Rect(0, 0, 4, 2), 0x801F),
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	atlas, err := sdk.GumpAtlas([]int{1, 2, 5}, 16)
+	require.NoError(t, err)
+	require.Len(t, atlas.Sheets, 1)
+	require.Len(t, atlas.Sprites, 2, "missing gumps are skipped")
+	assert.Equal(t, AtlasSprite{ID: 1, Width: 8, Height: 8}, atlas.Sprites[0])
+	assert.Equal(t, 5, atlas.Sprites[1].ID)
+
+	sprite := atlas.Sprites[1]
+	assert.Equal(t, color.NRGBA{B: 0xFF, A: 0xFF}, atlas.Sheets[0].NRGBAAt(sprite.X, sprite.Y))
+
+	_, err = sdk.GumpAtlas([]int{-1}, 16)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@
 package ultima
 
 import (
+	"image"
 	"runtime"
 	"testing"
 
@@ -56,6 +57,21 @@ func BenchmarkSDK(b *testing.B) {
 			}
 		})
 
+		b.Run("MapTilesRect", func(b *testing.B) {
+			m, err := sdk.Map(0)
+			if err != nil {
+				b.Fatalf("failed to load map: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				count := 0
+				for range m.Tiles(image.Rect(0, 0, 512, 512)) {
+					count++
+				}
+				runtime.KeepAlive(count)
+			}
+		})
+
 		b.Run("GumpImage", func(b *testing.B) {
 			gumps := []*Gump{}
 			for g := range sdk.Gumps() {
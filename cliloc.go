@@ -6,15 +6,21 @@ package ultima
 import (
 	"bufio"
 	"bytes"
+	"cmp"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-
 	"iter"
+	"math"
+	"os"
+	"slices"
+	"strings"
 
 	"codeberg.org/go-mmap/mmap"
 	"github.com/kelindar/ultima-sdk/internal/mul"
+	"github.com/kelindar/ultima-sdk/internal/uofile"
 )
 
 var (
@@ -22,17 +28,64 @@ var (
 	ErrInvalidStringID = errors.New("invalid string ID")
 )
 
+// clilocRecord marks, in their extra field, the entries which point to their record in
+// the cliloc file: ID (4), flag (1), length (2) and text
+const clilocRecord = 1
+
 // StringEntry represents a single localized string entry from a cliloc file.
 type StringEntry []byte
 
+// StringFlag tells whether a localized string is the original one of the client, or was
+// added or modified by a shard
+type StringFlag byte
+
+// String flag constants
+const (
+	StringOriginal StringFlag = 0 // String as shipped with the client
+	StringCustom   StringFlag = 1 // String added by a shard
+	StringModified StringFlag = 2 // Client string whose text was changed by a shard
+)
+
+// NewStringEntry creates a string entry with its ID, flag and text, such as to be
+// written with WriteCliloc
+func NewStringEntry(id int, flag StringFlag, text string) StringEntry {
+	entry := make(StringEntry, 5, 5+len(text))
+	binary.LittleEndian.PutUint32(entry[0:4], uint32(id))
+	entry[4] = byte(flag)
+	return append(entry, text...)
+}
+
+// stringEntryJSON is the JSON representation of a string entry
+type stringEntryJSON struct {
+	ID   int        `json:"id"`
+	Flag StringFlag `json:"flag"`
+	Text string     `json:"text"`
+}
+
+// MarshalJSON encodes the string entry as an object with its ID, flag and text
+func (s StringEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stringEntryJSON{ID: s.ID(), Flag: s.Flag(), Text: s.Text()})
+}
+
+// UnmarshalJSON decodes the string entry from an object with its ID, flag and text
+func (s *StringEntry) UnmarshalJSON(data []byte) error {
+	var v stringEntryJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*s = NewStringEntry(v.ID, v.Flag, v.Text)
+	return nil
+}
+
 // ID returns the ID of the string entry
 func (s StringEntry) ID() int {
 	return int(binary.LittleEndian.Uint32(s[0:4]))
 }
 
 // Flag returns the flag of the string entry
-func (s StringEntry) Flag() byte {
-	return s[4]
+func (s StringEntry) Flag() StringFlag {
+	return StringFlag(s[4])
 }
 
 // Text returns the text of the string entry
@@ -47,27 +100,85 @@ func (s *SDK) String(id int) (string, error) {
 }
 
 // StringWithLang retrieves a localized string by its ID using the specified language.
-// If the ID is not found or the language file doesn't exist, an error is returned.
+// If the ID is not found or the language file doesn't exist, the languages of the
+// fallback chain (see SetLanguageFallback) are tried in order, and an error is returned
+// if none of them has the string.
 func (s *SDK) StringWithLang(id int, lang string) (string, error) {
 	entry, err := s.StringEntry(id, lang)
-	if err != nil {
-		return "", err
+	if err == nil {
+		return entry.Text(), nil
+	}
+
+	for _, fallback := range s.languageFallback() {
+		if fallback == lang {
+			continue
+		}
+
+		if entry, err := s.StringEntry(id, fallback); err == nil {
+			return entry.Text(), nil
+		}
 	}
-	return entry.Text(), nil
+	return "", err
+}
+
+// SetLanguageFallback sets the chain of languages which StringWithLang falls back to,
+// in order, when a string is missing in the requested language. By default, strings
+// fall back to English ("enu"); calling it without languages disables the fallback.
+func (s *SDK) SetLanguageFallback(langs ...string) {
+	chain := slices.Clone(langs)
+	s.fallback.Store(&chain)
 }
 
-// StringEntry retrieves a string entry by its ID using the default language ("enu").
+// languageFallback returns the chain of languages to fall back to
+func (s *SDK) languageFallback() []string {
+	if chain := s.fallback.Load(); chain != nil {
+		return *chain
+	}
+	return []string{"enu"}
+}
+
+// StringEntry retrieves a string entry by its ID using the specified language.
 func (s *SDK) StringEntry(id int, lang string) (StringEntry, error) {
 	file, err := s.loadCliloc(lang)
 	if err != nil {
 		return StringEntry{}, err
 	}
 
-	data, err := file.ReadFull(uint32(id))
-	if err != nil {
+	entry, err := file.Entry(uint32(id))
+	switch {
+	case err != nil:
 		return StringEntry{}, err
+	case entry == nil:
+		return StringEntry{}, fmt.Errorf("%w: %d", ErrInvalidStringID, id)
+	}
+
+	return readStringEntry(entry, nil)
+}
+
+// SetClilocIndexOnly sets whether the cliloc files loaded from now on only index their
+// strings, reading the text of each one from the memory-mapped file when accessed rather
+// than copying every string into memory up front. This cuts the memory used by services
+// which only access a few strings. Compressed cliloc files are always read into memory.
+func (s *SDK) SetClilocIndexOnly(enabled bool) {
+	s.lazyText.Store(enabled)
+}
+
+// readStringEntry reads a string entry, using the buffer if it is large enough
+func readStringEntry(entry uofile.Entry, buffer []byte) (StringEntry, error) {
+	size := entry.Len()
+	if size > cap(buffer) {
+		buffer = make([]byte, size)
+	}
+
+	data := buffer[:size]
+	if _, err := entry.ReadAt(data, 0); err != nil {
+		return nil, err
 	}
 
+	// Entries indexed in place still hold the length of their text, before the text
+	if entry.Extra() == clilocRecord && size >= 7 {
+		data = append(data[:5], data[7:]...)
+	}
 	return StringEntry(data), nil
 }
 
@@ -87,15 +198,19 @@ func (s *SDK) StringsWithLang(lang string) iter.Seq2[int, string] {
 		buffer := make([]byte, 1024)
 		for index := range file.Entries() {
 			entry, err := file.Entry(index)
-			if err != nil {
+			if err != nil || entry == nil {
 				continue
 			}
 
-			if _, err := entry.ReadAt(buffer[:entry.Len()], 0); err != nil {
+			txt, err := readStringEntry(entry, buffer)
+			if err != nil {
 				continue
 			}
 
-			txt := StringEntry(buffer[:entry.Len()])
+			if cap(txt) > cap(buffer) {
+				buffer = txt // Keep the larger buffer
+			}
+
 			if !yield(txt.ID(), txt.Text()) {
 				break
 			}
@@ -103,7 +218,103 @@ func (s *SDK) StringsWithLang(lang string) iter.Seq2[int, string] {
 	}
 }
 
-// decodeClilocFile loads all string entries from a cliloc file into mul.Entry3D
+// StringsWithFlag returns an iterator over the localized strings in the default language
+// ("enu") having the flag, such as StringCustom to list the strings added by a shard.
+func (s *SDK) StringsWithFlag(flag StringFlag) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for entry := range s.stringEntries("enu") {
+			if entry.Flag() == flag && !yield(entry.ID(), entry.Text()) {
+				break
+			}
+		}
+	}
+}
+
+// Languages returns the codes of the languages installed in the client directory, such
+// as "enu" or "deu", sorted alphabetically. Each of them has a cliloc file and can be
+// given to StringWithLang.
+func (s *SDK) Languages() []string {
+	files, err := os.ReadDir(s.basePath)
+	if err != nil {
+		return nil
+	}
+
+	var languages []string
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || len(name) <= len("cliloc.") || !strings.EqualFold(name[:len("cliloc.")], "cliloc.") {
+			continue
+		}
+
+		languages = append(languages, name[len("cliloc."):])
+	}
+
+	slices.Sort(languages)
+	return languages
+}
+
+// stringEntries returns an iterator over the string entries in the specified language,
+// each entry being a copy which the caller may keep.
+func (s *SDK) stringEntries(lang string) iter.Seq[StringEntry] {
+	file, err := s.loadCliloc(lang)
+	if err != nil {
+		return func(yield func(StringEntry) bool) {} // Empty iterator
+	}
+
+	return func(yield func(StringEntry) bool) {
+		for index := range file.Entries() {
+			entry, err := file.Entry(index)
+			if err != nil || entry == nil {
+				continue
+			}
+
+			data, err := readStringEntry(entry, nil)
+			if err != nil {
+				continue
+			}
+
+			if !yield(data) {
+				break
+			}
+		}
+	}
+}
+
+// WriteCliloc encodes the string entries into the cliloc file format, sorted by their ID.
+// Texts longer than 32767 bytes, which the format cannot hold, are rejected.
+func WriteCliloc(dst io.Writer, entries []StringEntry) error {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b StringEntry) int {
+		return cmp.Compare(a.ID(), b.ID())
+	})
+
+	w := bufio.NewWriter(dst)
+	binary.Write(w, binary.LittleEndian, uint32(0xFFFFFFFF))
+	binary.Write(w, binary.LittleEndian, uint16(0))
+	for _, entry := range sorted {
+		if len(entry) < 5 {
+			return fmt.Errorf("%w: entry of %d bytes is too short", ErrInvalidStringID, len(entry))
+		}
+
+		text := entry[5:]
+		if len(text) > math.MaxInt16 {
+			return fmt.Errorf("string %d is too long (%d bytes)", entry.ID(), len(text))
+		}
+
+		w.Write(entry[0:5])
+		binary.Write(w, binary.LittleEndian, uint16(len(text)))
+		w.Write(text)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write cliloc: %w", err)
+	}
+	return nil
+}
+
+// decodeClilocFile loads all string entries from a cliloc file into mul.Entry3D, after
+// decompressing it if needed. When indexOnly is set, the entries of an uncompressed file
+// only point to their record within the file.
 //
 // The cliloc file format:
 // - Header1 (int32, LittleEndian) - typically 0xFFFFFFFF
@@ -113,8 +324,38 @@ func (s *SDK) StringsWithLang(lang string) iter.Seq2[int, string] {
 //   - Flag (byte)
 //   - Length (int16, LittleEndian)
 //   - Text (bytes[Length], UTF-8 encoded)
-func decodeClilocFile(file *mmap.File, add mul.AddFn) error {
-	reader := bufio.NewReader(file)
+func decodeClilocFile(file *mmap.File, add mul.AddFn, indexOnly bool) error {
+	// Index the entries in place, their text being read from the file when accessed
+	var header [4]byte
+	file.ReadAt(header[:], 0)
+	if indexOnly && !isCompressedCliloc(header[:]) && walkCliloc(file, file.Len(), nil) {
+		walkCliloc(file, file.Len(), func(offset, id, length int) {
+			add(uint32(id), uint32(offset), uint32(7+length), clilocRecord, nil)
+		})
+		return nil
+	}
+
+	data := make([]byte, file.Len())
+	if _, err := file.ReadAt(data, 0); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read cliloc: %w", err)
+	}
+
+	// Recent clients ship compressed cliloc files, which must be decompressed first
+	switch {
+	case isCompressedCliloc(data):
+		plain, err := decompressCliloc(data)
+		switch {
+		case err != nil:
+			return fmt.Errorf("failed to decompress cliloc: %w", err)
+		case !isPlainCliloc(plain):
+			return fmt.Errorf("failed to decompress cliloc: %w", errInvalidCompressedCliloc)
+		}
+		data = plain
+	case !isPlainCliloc(data):
+		return fmt.Errorf("invalid cliloc: entries do not match the size of the file")
+	}
+
+	reader := bytes.NewReader(data)
 
 	// Read file headers
 	var header1 int32
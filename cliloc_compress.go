@@ -0,0 +1,168 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+)
+
+var errInvalidCompressedCliloc = errors.New("invalid compressed cliloc")
+
+// clilocCompressed is the marker of compressed cliloc files, as the last byte of their
+// 4-byte header
+const clilocCompressed = 0x8E
+
+// isCompressedCliloc checks whether the data starts with the header of a compressed
+// cliloc file
+func isCompressedCliloc(data []byte) bool {
+	return len(data) >= 4 && data[3] == clilocCompressed
+}
+
+// isPlainCliloc checks whether the data is an uncompressed cliloc file, by walking its
+// entries which must end exactly at the end of the data
+func isPlainCliloc(data []byte) bool {
+	return walkCliloc(bytes.NewReader(data), len(data), nil)
+}
+
+// walkCliloc walks the entries of an uncompressed cliloc file of the given size, calling
+// the function (if any) with the offset, ID and text length of each entry. It returns
+// false if the entries do not end exactly at the end of the file.
+func walkCliloc(r io.ReaderAt, size int, fn func(offset, id, length int)) bool {
+	if size < 6 {
+		return false
+	}
+
+	var header [7]byte // ID (4), flag (1), length (2)
+	offset := 6
+	for offset < size {
+		if offset+7 > size {
+			return false
+		}
+
+		if _, err := r.ReadAt(header[:], int64(offset)); err != nil {
+			return false
+		}
+
+		length := int16(binary.LittleEndian.Uint16(header[5:]))
+		if length < 0 || offset+7+int(length) > size {
+			return false
+		}
+
+		if fn != nil {
+			fn(offset, int(int32(binary.LittleEndian.Uint32(header[:]))), int(length))
+		}
+		offset += 7 + int(length)
+	}
+	return true
+}
+
+// decompressCliloc decompresses a cliloc file shipped by recent clients. Such files start
+// with a 4-byte header ending with the clilocCompressed marker, followed by the compressed data encoded with a move-to-front
+// transform. Once transformed back, the data holds the number of occurrences of every
+// byte (256 little-endian 32-bit counts), followed by the occurrences of every byte in
+// order of decreasing frequency: the first one being its initial rank, and the others
+// the rank at which it is re-inserted after being emitted.
+func decompressCliloc(data []byte) ([]byte, error) {
+	if len(data) < 4+1024 {
+		return nil, errInvalidCompressedCliloc
+	}
+
+	input := moveToFront(data[4:])
+
+	// Read the number of occurrences of every byte, which sum up to the output length
+	var counts [256]int
+	size, symbols := 0, 0
+	for i := range counts {
+		counts[i] = int(binary.LittleEndian.Uint32(input[i*4:]))
+		if counts[i] < 0 || counts[i] > len(input) {
+			return nil, errInvalidCompressedCliloc
+		}
+
+		size += counts[i]
+		if counts[i] > 0 {
+			symbols++
+		}
+	}
+
+	input = input[1024:]
+	if size > len(input) {
+		return nil, errInvalidCompressedCliloc
+	}
+
+	// Every byte has a run of its ranks, ordered by decreasing frequency
+	var table [256]byte
+	var start, end [256]int
+	for i := range table {
+		table[i] = byte(i)
+	}
+
+	offset := 0
+	for _, symbol := range byFrequency(counts) {
+		table[input[offset]] = symbol
+		start[symbol], end[symbol] = offset+1, offset+counts[symbol]
+		offset += counts[symbol]
+	}
+
+	output := make([]byte, 0, size)
+	value := table[0]
+	for len(output) < size {
+		output = append(output, value)
+
+		// Once a byte is exhausted, it is removed from the front of the table
+		if start[value] >= end[value] {
+			symbols--
+			copy(table[:max(symbols, 0)], table[1:])
+			value = table[0]
+			continue
+		}
+
+		// Otherwise, it is moved back into the table at its next rank
+		rank := int(input[start[value]])
+		start[value]++
+		if rank != 0 {
+			copy(table[:rank], table[1:rank+1])
+			table[rank] = value
+			value = table[0]
+		}
+	}
+
+	return output, nil
+}
+
+// moveToFront reverses a move-to-front transform over the bytes
+func moveToFront(data []byte) []byte {
+	var table [256]byte
+	for i := range table {
+		table[i] = byte(i)
+	}
+
+	output := make([]byte, len(data))
+	for i, rank := range data {
+		value := table[rank]
+		copy(table[1:rank+1], table[:rank])
+		table[0] = value
+		output[i] = value
+	}
+	return output
+}
+
+// byFrequency returns the bytes which occur, by decreasing number of occurrences and
+// then by increasing value
+func byFrequency(counts [256]int) []byte {
+	symbols := make([]byte, 0, 256)
+	for i, count := range counts {
+		if count > 0 {
+			symbols = append(symbols, byte(i))
+		}
+	}
+
+	slices.SortStableFunc(symbols, func(a, b byte) int {
+		return counts[b] - counts[a]
+	})
+	return symbols
+}
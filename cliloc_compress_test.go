@@ -0,0 +1,141 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecompressCliloc(t *testing.T) {
+	for _, plain := range [][]byte{
+		[]byte("a"),
+		[]byte("abracadabra"),
+		bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog "), 20),
+	} {
+		output, err := decompressCliloc(compressTestCliloc(plain))
+		require.NoError(t, err)
+		assert.Equal(t, plain, output)
+	}
+
+	_, err := decompressCliloc([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestSDK_CompressedCliloc(t *testing.T) {
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCliloc(&buffer, []StringEntry{
+		NewStringEntry(500000, 0, "Hello"),
+		NewStringEntry(500001, 0, "~1_NAME~ says hello"),
+		NewStringEntry(1000000, 2, ""),
+	}))
+
+	assert.True(t, isPlainCliloc(buffer.Bytes()))
+	compressed := compressTestCliloc(buffer.Bytes())
+	assert.False(t, isPlainCliloc(compressed))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc.enu"), compressed, 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	text, err := sdk.String(500001)
+	require.NoError(t, err)
+	assert.Equal(t, "~1_NAME~ says hello", text)
+	assert.Equal(t, map[int]string{500000: "Hello", 500001: "~1_NAME~ says hello", 1000000: ""}, maps.Collect(sdk.Strings()))
+
+	// Files which are neither plain nor decodable are reported, rather than read as empty
+	plain := buffer.Bytes()
+	for lang, data := range map[string][]byte{
+		"deu": plain[:len(plain)-1],           // Truncated plain file
+		"fra": append(compressed[:4:4], 0xFF), // Truncated compressed file
+		"esp": compressed[:len(compressed)/2], // Compressed file missing its end
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc."+lang), data, 0644))
+		_, err := sdk.StringEntry(500000, lang)
+		assert.Error(t, err, lang)
+	}
+}
+
+// compressTestCliloc compresses the data as recent clients do, being the reverse of
+// decompressCliloc
+func compressTestCliloc(plain []byte) []byte {
+	var counts [256]int
+	var first [256]int
+	next := make([]int, len(plain))
+	for i := range first {
+		first[i] = -1
+	}
+
+	for i := len(plain) - 1; i >= 0; i-- {
+		next[i], first[plain[i]] = first[plain[i]], i
+		counts[plain[i]]++
+	}
+
+	// The table starts ordered by first occurrence, which gives the initial ranks
+	var list []byte
+	for i, count := range counts {
+		if count > 0 {
+			list = append(list, byte(i))
+		}
+	}
+
+	slices.SortFunc(list, func(a, b byte) int { return first[a] - first[b] })
+	ranks := make([][]byte, 256)
+	for i, symbol := range list {
+		ranks[symbol] = append(ranks[symbol], byte(i))
+	}
+
+	// Each byte is re-inserted at the rank of its next occurrence among the others
+	upcoming := first
+	for i, value := range plain {
+		rest := list[1:]
+		if next[i] < 0 {
+			list = rest
+			continue
+		}
+
+		rank := 0
+		for rank < len(rest) && upcoming[rest[rank]] < next[i] {
+			rank++
+		}
+
+		ranks[value] = append(ranks[value], byte(rank))
+		upcoming[value] = next[i]
+		list = slices.Insert(slices.Clone(rest), rank, value)
+	}
+
+	stage := make([]byte, 1024)
+	for i, count := range counts {
+		binary.LittleEndian.PutUint32(stage[i*4:], uint32(count))
+	}
+	for _, symbol := range byFrequency(counts) {
+		stage = append(stage, ranks[symbol]...)
+	}
+
+	// Apply a move-to-front transform, after the header
+	var table [256]byte
+	for i := range table {
+		table[i] = byte(i)
+	}
+
+	output := make([]byte, 4, 4+len(stage))
+	output[3] = clilocCompressed
+	for _, value := range stage {
+		rank := bytes.IndexByte(table[:], value)
+		copy(table[1:rank+1], table[:rank])
+		table[0] = value
+		output = append(output, byte(rank))
+	}
+	return output
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"cmp"
+	"slices"
+)
+
+// StringDiff is a bitmask describing how a localized string differs between two
+// versions of a cliloc file.
+type StringDiff uint8
+
+// String difference constants
+const (
+	StringDiffText    StringDiff = 1 << iota // Text has changed
+	StringDiffFlag                           // Flag has changed
+	StringDiffAdded                          // String only exists in the newer version
+	StringDiffRemoved                        // String only exists in the older version
+)
+
+// StringChange describes a single localized string which differs between two versions.
+type StringChange struct {
+	ID      int        // ID of the string
+	Diff    StringDiff // Which properties have changed
+	OldText string     // Text in the older version, empty if added
+	NewText string     // Text in the newer version, empty if removed
+}
+
+// DiffStrings compares the localized strings of a language between two client versions
+// and returns the strings which were added, removed or changed, in ascending ID order.
+func DiffStrings(a, b *SDK, lang string) ([]StringChange, error) {
+	older, err := a.sortedStringEntries(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	newer, err := b.sortedStringEntries(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffStringEntries(older, newer), nil
+}
+
+// DiffStringEntries compares two sets of string entries, such as read from two cliloc
+// files or imported with StringsFromCSV, and returns the strings which were added,
+// removed or changed, in ascending ID order.
+func DiffStringEntries(older, newer []StringEntry) []StringChange {
+	byID := func(a, b StringEntry) int { return cmp.Compare(a.ID(), b.ID()) }
+	older = slices.SortedStableFunc(slices.Values(older), byID)
+	newer = slices.SortedStableFunc(slices.Values(newer), byID)
+
+	var out []StringChange
+	for i, j := 0, 0; i < len(older) || j < len(newer); {
+		switch {
+		case j >= len(newer) || (i < len(older) && older[i].ID() < newer[j].ID()):
+			out = append(out, StringChange{ID: older[i].ID(), Diff: StringDiffRemoved, OldText: older[i].Text()})
+			i++
+		case i >= len(older) || newer[j].ID() < older[i].ID():
+			out = append(out, StringChange{ID: newer[j].ID(), Diff: StringDiffAdded, NewText: newer[j].Text()})
+			j++
+		default:
+			var diff StringDiff
+			if older[i].Text() != newer[j].Text() {
+				diff |= StringDiffText
+			}
+			if older[i].Flag() != newer[j].Flag() {
+				diff |= StringDiffFlag
+			}
+
+			if diff != 0 {
+				out = append(out, StringChange{ID: older[i].ID(), Diff: diff, OldText: older[i].Text(), NewText: newer[j].Text()})
+			}
+			i, j = i+1, j+1
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStringEntries(t *testing.T) {
+	older := []StringEntry{
+		NewStringEntry(3, 0, "Same"),
+		NewStringEntry(1, 0, "Removed"),
+		NewStringEntry(4, 0, "Old text"),
+		NewStringEntry(5, 0, "Flagged"),
+	}
+	newer := []StringEntry{
+		NewStringEntry(2, 0, "Added"),
+		NewStringEntry(3, 0, "Same"),
+		NewStringEntry(4, 0, "New text"),
+		NewStringEntry(5, 2, "Flagged"),
+		NewStringEntry(6, 0, "Also added"),
+	}
+
+	assert.Equal(t, []StringChange{
+		{ID: 1, Diff: StringDiffRemoved, OldText: "Removed"},
+		{ID: 2, Diff: StringDiffAdded, NewText: "Added"},
+		{ID: 4, Diff: StringDiffText, OldText: "Old text", NewText: "New text"},
+		{ID: 5, Diff: StringDiffFlag, OldText: "Flagged", NewText: "Flagged"},
+		{ID: 6, Diff: StringDiffAdded, NewText: "Also added"},
+	}, DiffStringEntries(older, newer))
+
+	assert.Empty(t, DiffStringEntries(newer, newer))
+}
+
+func TestDiffStrings(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	writeTestCliloc(t, dirA, "enu", map[int]string{1: "Hello", 2: "World"})
+	writeTestCliloc(t, dirB, "enu", map[int]string{1: "Hello", 2: "Britannia", 3: "New"})
+
+	a, err := Open(dirA)
+	require.NoError(t, err)
+	defer a.Close()
+
+	b, err := Open(dirB)
+	require.NoError(t, err)
+	defer b.Close()
+
+	changes, err := DiffStrings(a, b, "enu")
+	require.NoError(t, err)
+	assert.Equal(t, []StringChange{
+		{ID: 2, Diff: StringDiffText, OldText: "World", NewText: "Britannia"},
+		{ID: 3, Diff: StringDiffAdded, NewText: "New"},
+	}, changes)
+
+	_, err = DiffStrings(a, b, "deu")
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// StringsToCSV exports the string entries of a language to CSV format, sorted by ID,
+// with headers: id, flag, text. The result can be read back with StringsFromCSV.
+func (s *SDK) StringsToCSV(lang string) ([]byte, error) {
+	entries, err := s.sortedStringEntries(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"id", "flag", "text"}); err != nil {
+		return nil, fmt.Errorf("cliloc: failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range entries {
+		record := []string{
+			strconv.Itoa(entry.ID()),
+			strconv.Itoa(int(entry.Flag())),
+			entry.Text(),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("cliloc: failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("cliloc: failed to flush CSV writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// StringsFromCSV parses CSV data with columns id, flag and text, as exported by
+// StringsToCSV. The first row is assumed to be a header and is skipped.
+func StringsFromCSV(data []byte) ([]StringEntry, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cliloc: failed to parse CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("cliloc: CSV data is empty")
+	}
+
+	entries := make([]StringEntry, 0, len(records)-1)
+	for rowNum, record := range records[1:] {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("cliloc: invalid CSV row %d, expected 3 columns (id,flag,text), got %d", rowNum+2, len(record))
+		}
+
+		id, err := strconv.ParseUint(record[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("cliloc: invalid id in row %d: %w", rowNum+2, err)
+		}
+
+		flag, err := strconv.ParseUint(record[1], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("cliloc: invalid flag in row %d: %w", rowNum+2, err)
+		}
+
+		entries = append(entries, NewStringEntry(int(id), StringFlag(flag), record[2]))
+	}
+	return entries, nil
+}
+
+// StringsToJSON exports the string entries of a language as an indented JSON array of
+// objects with their id, flag and text, sorted by ID
+func (s *SDK) StringsToJSON(lang string) ([]byte, error) {
+	entries, err := s.sortedStringEntries(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cliloc: failed to encode JSON: %w", err)
+	}
+	return data, nil
+}
+
+// StringsFromJSON parses a JSON array of string entries, as exported by StringsToJSON
+func StringsFromJSON(data []byte) ([]StringEntry, error) {
+	var entries []StringEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cliloc: failed to parse JSON: %w", err)
+	}
+	return entries, nil
+}
+
+// sortedStringEntries returns every string entry of a language, sorted by ID
+func (s *SDK) sortedStringEntries(lang string) ([]StringEntry, error) {
+	if _, err := s.loadCliloc(lang); err != nil {
+		return nil, fmt.Errorf("cliloc: %w", err)
+	}
+
+	return slices.SortedFunc(s.stringEntries(lang), func(a, b StringEntry) int {
+		return cmp.Compare(a.ID(), b.ID())
+	}), nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDK_StringsExport(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCliloc(t, dir, "deu", map[int]string{
+		1000001: "Hallo, ~1_NAME~!",
+		1000000: "Ein \"Schwert\", scharf",
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	expect := []StringEntry{
+		NewStringEntry(1000000, 0, "Ein \"Schwert\", scharf"),
+		NewStringEntry(1000001, 0, "Hallo, ~1_NAME~!"),
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		data, err := sdk.StringsToCSV("deu")
+		require.NoError(t, err)
+		assert.Equal(t, "id,flag,text\n1000000,0,\"Ein \"\"Schwert\"\", scharf\"\n1000001,0,\"Hallo, ~1_NAME~!\"\n", string(data))
+
+		entries, err := StringsFromCSV(data)
+		require.NoError(t, err)
+		assert.Equal(t, expect, entries)
+
+		_, err = StringsFromCSV([]byte("id,flag,text\nabc,0,x\n"))
+		assert.Error(t, err)
+		_, err = StringsFromCSV([]byte("id,flag,text\n1,256,x\n"))
+		assert.Error(t, err)
+		_, err = StringsFromCSV(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := sdk.StringsToJSON("deu")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"text": "Hallo, ~1_NAME~!"`)
+
+		entries, err := StringsFromJSON(data)
+		require.NoError(t, err)
+		assert.Equal(t, expect, entries)
+
+		_, err = StringsFromJSON([]byte(`[{"id": "x"}]`))
+		assert.Error(t, err)
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		data, err := sdk.StringsToCSV("deu")
+		require.NoError(t, err)
+		entries, err := StringsFromCSV(data)
+		require.NoError(t, err)
+
+		var buffer bytes.Buffer
+		require.NoError(t, WriteCliloc(&buffer, entries))
+		original, err := os.ReadFile(filepath.Join(dir, "cliloc.deu"))
+		require.NoError(t, err)
+		assert.Equal(t, original, buffer.Bytes())
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format retrieves a localized string by its ID using the default language ("enu") and
+// expands its placeholders with the arguments, as the client does for localized messages.
+func (s *SDK) Format(id int, args ...any) (string, error) {
+	return s.FormatWithLang(id, "enu", args...)
+}
+
+// FormatWithLang retrieves a localized string by its ID using the specified language and
+// expands its placeholders with the arguments. Placeholders such as "~1_NAME~" are
+// replaced by the argument at their 1-based position, or removed if there is no such
+// argument. Arguments of the form "#1042971" are themselves replaced by the localized
+// string with that ID. A single string argument holding tab-separated values, as sent
+// by servers, is split into several arguments.
+func (s *SDK) FormatWithLang(id int, lang string, args ...any) (string, error) {
+	text, err := s.StringWithLang(id, lang)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		values = append(values, fmt.Sprint(arg))
+	}
+
+	if len(values) == 1 && strings.Contains(values[0], "\t") {
+		values = strings.Split(values[0], "\t")
+	}
+
+	// Resolve the arguments which refer to other localized strings
+	for i, value := range values {
+		if ref, ok := clilocReference(value); ok {
+			if values[i], err = s.StringWithLang(ref, lang); err != nil {
+				return "", fmt.Errorf("failed to resolve argument %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return expandCliloc(text, values), nil
+}
+
+// clilocReference parses an argument referring to a localized string, such as "#1042971"
+func clilocReference(value string) (int, bool) {
+	if len(value) < 2 || value[0] != '#' {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(value[1:])
+	return id, err == nil && id >= 0
+}
+
+// expandCliloc replaces the "~N_NAME~" placeholders of the text by the arguments, leaving
+// any tilde which is not part of a placeholder unchanged
+func expandCliloc(text string, args []string) string {
+	var out strings.Builder
+	for {
+		start := strings.IndexByte(text, '~')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(text[start+1:], '~')
+		if end < 0 {
+			break
+		}
+
+		end += start + 1
+		index, ok := clilocPlaceholder(text[start+1 : end])
+		if !ok {
+			// Not a placeholder, the closing tilde may open the next one
+			out.WriteString(text[:end])
+			text = text[end:]
+			continue
+		}
+
+		out.WriteString(text[:start])
+		if index < len(args) {
+			out.WriteString(args[index])
+		}
+		text = text[end+1:]
+	}
+
+	out.WriteString(text)
+	return out.String()
+}
+
+// clilocPlaceholder parses the content of a placeholder, such as "1_NAME" or "2", into
+// the 0-based index of its argument
+func clilocPlaceholder(name string) (int, bool) {
+	digits := name
+	if i := strings.IndexByte(name, '_'); i >= 0 {
+		digits = name[:i]
+	}
+
+	index, err := strconv.Atoi(digits)
+	if err != nil || index < 1 || digits[0] == '+' || digits[0] == '-' {
+		return 0, false
+	}
+	return index - 1, true
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandCliloc(t *testing.T) {
+	tests := []struct {
+		text   string
+		args   []string
+		expect string
+	}{
+		{"You see: ~1_NAME~", []string{"a sword"}, "You see: a sword"},
+		{"~1_AMOUNT~ ~2_ITEM~ for ~1_AMOUNT~ gold", []string{"5", "apples"}, "5 apples for 5 gold"},
+		{"~1~ and ~2_MISSING~!", []string{"one"}, "one and !"},
+		{"a ~ tilde ~1_X~", []string{"x"}, "a ~ tilde x"},
+		{"~unclosed", nil, "~unclosed"},
+		{"no placeholders", []string{"ignored"}, "no placeholders"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expect, expandCliloc(tc.text, tc.args), tc.text)
+	}
+}
+
+func TestSDK_Format(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCliloc(t, dir, "enu", map[int]string{
+		1000001: "~1_NAME~ gives you ~2_AMOUNT~ ~3_ITEM~",
+		1042971: "apples",
+	})
+	writeTestCliloc(t, dir, "deu", map[int]string{
+		1000001: "~1_NAME~ gibt dir ~2_AMOUNT~ ~3_ITEM~",
+		1042971: "Äpfel",
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	text, err := sdk.Format(1000001, "Iolo", 5, "#1042971")
+	require.NoError(t, err)
+	assert.Equal(t, "Iolo gives you 5 apples", text)
+
+	// A tab-separated argument, as sent by the server
+	text, err = sdk.FormatWithLang(1000001, "deu", "Iolo\t5\t#1042971")
+	require.NoError(t, err)
+	assert.Equal(t, "Iolo gibt dir 5 Äpfel", text)
+
+	_, err = sdk.Format(1000001, "Iolo", 5, "#1")
+	assert.Error(t, err)
+	_, err = sdk.Format(1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SearchStrings returns the IDs of the localized strings in the specified language which
+// contain every word of the query (case-insensitive), the most relevant first: strings
+// equal to the query, then those containing it as whole words, then anywhere, then
+// those containing its words apart. Shorter strings rank first among equally relevant
+// ones. The strings of the language are indexed on first use.
+func (s *SDK) SearchStrings(query, lang string) ([]int, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	index, err := s.stringIndex(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		id, score, length int
+	}
+
+	var matches []match
+	for i, text := range index.texts {
+		if score, ok := stringRelevance(text, query, words); ok {
+			matches = append(matches, match{index.ids[i], score, len(text)})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(cmp.Compare(a.score, b.score), cmp.Compare(a.length, b.length), cmp.Compare(a.id, b.id))
+	})
+
+	ids := make([]int, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.id)
+	}
+	return ids, nil
+}
+
+// stringRelevance scores how well the lower-cased text matches the query, lower being
+// more relevant, or returns false if the text does not contain every word of the query
+func stringRelevance(text, query string, words []string) (int, bool) {
+	switch {
+	case text == query:
+		return 0, true
+	case containsWord(text, query):
+		return 1, true
+	case strings.Contains(text, query):
+		return 2, true
+	}
+
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return 0, false
+		}
+	}
+	return 3, true
+}
+
+// containsWord checks whether the text contains the phrase on word boundaries
+func containsWord(text, phrase string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], phrase)
+		if i < 0 {
+			return false
+		}
+
+		start, end := offset+i, offset+i+len(phrase)
+		if !isWordByte(text, start-1) && !isWordByte(text, end) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+// isWordByte checks whether the byte at the position is part of a word
+func isWordByte(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+
+	c := rune(text[i])
+	return c >= 0x80 || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// clilocIndex holds the lower-cased strings of a language, for searching them
+type clilocIndex struct {
+	ids   []int    // IDs of the strings
+	texts []string // Lower-cased texts, in the same order as the IDs
+}
+
+// stringIndex returns the search index over the strings of a language, building it on
+// first use
+func (s *SDK) stringIndex(lang string) (*clilocIndex, error) {
+	if index, ok := s.clilocs.Load(lang); ok {
+		return index.(*clilocIndex), nil
+	}
+
+	if _, err := s.loadCliloc(lang); err != nil {
+		return nil, err
+	}
+
+	index := new(clilocIndex)
+	for id, text := range s.StringsWithLang(lang) {
+		index.ids = append(index.ids, id)
+		index.texts = append(index.texts, strings.ToLower(text))
+	}
+
+	// Another goroutine may have built the index concurrently, keep the first one
+	actual, _ := s.clilocs.LoadOrStore(lang, index)
+	return actual.(*clilocIndex), nil
+}
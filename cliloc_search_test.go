@@ -0,0 +1,45 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDK_SearchStrings(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCliloc(t, dir, "enu", map[int]string{
+		1: "A Dragon's Hoard",
+		2: "dragon",
+		3: "Dragonslayer",
+		4: "Slay the ancient dragon in its lair",
+		5: "The dragon is ancient",
+		6: "Wyrm",
+		7: "A red dragon",
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	ids, err := sdk.SearchStrings("Dragon", "enu")
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 7, 1, 5, 4, 3}, ids)
+
+	// Every word must be present, the phrase ranking first
+	ids, err = sdk.SearchStrings("ancient dragon", "enu")
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, ids)
+
+	ids, err = sdk.SearchStrings("  ", "enu")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	ids, err = sdk.SearchStrings("lich", "enu")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
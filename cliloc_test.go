@@ -4,9 +4,15 @@
 package ultima
 
 import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCliloc(t *testing.T) {
@@ -63,3 +69,162 @@ func TestDecodeClilocFile(t *testing.T) {
 	// For now, we'll just ensure the function is exported and compiles correctly.
 	t.Skip("Full testing of decodeClilocFile requires creating test files")
 }
+
+// writeTestCliloc writes a cliloc file for the language with the strings, keyed by ID
+func writeTestCliloc(t *testing.T, dir, lang string, strings map[int]string) {
+	var entries []StringEntry
+	for id, text := range strings {
+		entries = append(entries, NewStringEntry(id, 0, text))
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCliloc(&buffer, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc."+lang), buffer.Bytes(), 0644))
+}
+
+func TestWriteCliloc(t *testing.T) {
+	dir := t.TempDir()
+	entries := []StringEntry{
+		NewStringEntry(1000002, 2, "Second"),
+		NewStringEntry(1000001, 0, "First"),
+		NewStringEntry(1000003, 1, ""),
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCliloc(&buffer, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc.enu"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	for _, expect := range entries {
+		entry, err := sdk.StringEntry(expect.ID(), "enu")
+		require.NoError(t, err)
+		assert.Equal(t, expect, entry)
+	}
+
+	// Every string is iterated, by its ID
+	texts := maps.Collect(sdk.Strings())
+	assert.Equal(t, map[int]string{1000001: "First", 1000002: "Second", 1000003: ""}, texts)
+
+	assert.Error(t, WriteCliloc(&buffer, []StringEntry{NewStringEntry(1, 0, string(make([]byte, 1<<15)))}))
+}
+
+func TestSDK_Languages(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCliloc(t, dir, "enu", map[int]string{1: "Hello"})
+	writeTestCliloc(t, dir, "deu", map[int]string{1: "Hallo"})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc"), nil, 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "cliloc.bak"), 0755))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	assert.Equal(t, []string{"deu", "enu"}, sdk.Languages())
+	for _, lang := range sdk.Languages() {
+		_, err := sdk.StringWithLang(1, lang)
+		assert.NoError(t, err)
+	}
+}
+
+func TestSDK_LanguageFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCliloc(t, dir, "enu", map[int]string{1: "Hello", 2: "World", 3: "Sword"})
+	writeTestCliloc(t, dir, "deu", map[int]string{1: "Hallo"})
+	writeTestCliloc(t, dir, "fra", map[int]string{2: "Monde"})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	// Missing strings and languages fall back to English by default
+	text, err := sdk.StringWithLang(2, "deu")
+	require.NoError(t, err)
+	assert.Equal(t, "World", text)
+	text, err = sdk.StringWithLang(1, "jpn")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", text)
+
+	// A custom chain is tried in order
+	sdk.SetLanguageFallback("fra", "enu")
+	text, err = sdk.StringWithLang(2, "deu")
+	require.NoError(t, err)
+	assert.Equal(t, "Monde", text)
+	text, err = sdk.StringWithLang(3, "deu")
+	require.NoError(t, err)
+	assert.Equal(t, "Sword", text)
+	text, err = sdk.StringWithLang(1, "deu")
+	require.NoError(t, err)
+	assert.Equal(t, "Hallo", text)
+
+	// Without a fallback, missing strings are errors
+	sdk.SetLanguageFallback()
+	_, err = sdk.StringWithLang(2, "deu")
+	assert.Error(t, err)
+	_, err = sdk.StringWithLang(1, "jpn")
+	assert.Error(t, err)
+	_, err = sdk.StringWithLang(4, "enu")
+	assert.Error(t, err)
+}
+
+func TestSDK_ClilocIndexOnly(t *testing.T) {
+	dir := t.TempDir()
+	entries := []StringEntry{
+		NewStringEntry(500000, 0, "Hello"),
+		NewStringEntry(500001, 2, ""),
+		NewStringEntry(500002, 1, string(bytes.Repeat([]byte("long "), 400))),
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCliloc(&buffer, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc.enu"), buffer.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc.deu"), compressTestCliloc(buffer.Bytes()), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+	sdk.SetClilocIndexOnly(true)
+
+	// Both the indexed and the compressed files give the same strings
+	for _, lang := range []string{"enu", "deu"} {
+		for _, expect := range entries {
+			entry, err := sdk.StringEntry(expect.ID(), lang)
+			require.NoError(t, err)
+			assert.Equal(t, expect, entry)
+		}
+
+		assert.Equal(t, entries, slices.Collect(sdk.stringEntries(lang)))
+		texts := maps.Collect(sdk.StringsWithLang(lang))
+		assert.Len(t, texts, 3)
+		assert.Equal(t, entries[2].Text(), texts[500002])
+	}
+
+	_, err = sdk.StringEntry(1, "enu")
+	assert.Error(t, err)
+}
+
+func TestSDK_StringsWithFlag(t *testing.T) {
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCliloc(&buffer, []StringEntry{
+		NewStringEntry(1, StringOriginal, "Original"),
+		NewStringEntry(2, StringCustom, "Custom"),
+		NewStringEntry(3, StringModified, "Modified"),
+		NewStringEntry(4, StringCustom, "Also custom"),
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc.enu"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	assert.Equal(t, map[int]string{2: "Custom", 4: "Also custom"}, maps.Collect(sdk.StringsWithFlag(StringCustom)))
+	assert.Equal(t, map[int]string{3: "Modified"}, maps.Collect(sdk.StringsWithFlag(StringModified)))
+	assert.Equal(t, map[int]string{1: "Original"}, maps.Collect(sdk.StringsWithFlag(StringOriginal)))
+
+	entry, err := sdk.StringEntry(2, "enu")
+	require.NoError(t, err)
+	assert.Equal(t, StringCustom, entry.Flag())
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"codeberg.org/go-mmap/mmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
+)
+
+// defEntry represents a single remapping entry from a .def file (e.g. art.def,
+// body.def), packed as a hue followed by the target IDs, all as little-endian int32.
+type defEntry []byte
+
+// Hue returns the hue to apply when the entry is remapped
+func (d defEntry) Hue() int {
+	return int(int32(binary.LittleEndian.Uint32(d[0:4])))
+}
+
+// Targets returns the target IDs, in order of preference
+func (d defEntry) Targets() []int {
+	out := make([]int, 0, len(d)/4-1)
+	for i := 4; i+4 <= len(d); i += 4 {
+		out = append(out, int(int32(binary.LittleEndian.Uint32(d[i:i+4]))))
+	}
+	return out
+}
+
+// decodeDefFile loads all entries of a .def file into mul.Entry3D
+//
+// The .def file format is line-based text:
+//   - Lines starting with '#' (or empty lines) are ignored
+//   - Each other line has the form "id {target1, target2, ...} hue"
+//   - The braces may be omitted for a single target, and the hue is optional
+func decodeDefFile(file *mmap.File, add mul.AddFn) error {
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		id, entry, ok, err := parseDefLine(scanner.Text())
+		switch {
+		case err != nil:
+			return fmt.Errorf("invalid def entry on line %d: %w", line, err)
+		case !ok:
+			continue
+		}
+
+		add(uint32(id), uint32(id), uint32(len(entry)), 0, entry)
+	}
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read def file: %w", err)
+	}
+	return nil
+}
+
+// parseDefLine parses a single line of a .def file, returning false if the line
+// does not contain an entry.
+func parseDefLine(line string) (int, defEntry, bool, error) {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return 0, nil, false, nil
+	}
+
+	// Split into the source ID, the target list and the optional hue
+	var source, targets, hue string
+	if start := strings.IndexByte(line, '{'); start >= 0 {
+		end := strings.IndexByte(line, '}')
+		if end < start {
+			return 0, nil, false, fmt.Errorf("unbalanced braces in %q", line)
+		}
+		source, targets, hue = line[:start], line[start+1:end], line[end+1:]
+	} else {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0, nil, false, fmt.Errorf("missing target in %q", line)
+		}
+		source, targets = fields[0], fields[1]
+		if len(fields) > 2 {
+			hue = fields[2]
+		}
+	}
+
+	id, err := parseDefInt(source)
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	entry := make(defEntry, 4, 8)
+	if hue = strings.TrimSpace(hue); hue != "" {
+		h, err := parseDefInt(hue)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		binary.LittleEndian.PutUint32(entry[0:4], uint32(int32(h)))
+	}
+
+	for _, target := range strings.FieldsFunc(targets, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		v, err := parseDefInt(target)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		entry = binary.LittleEndian.AppendUint32(entry, uint32(int32(v)))
+	}
+
+	if len(entry) == 4 {
+		return 0, nil, false, fmt.Errorf("missing target in %q", line)
+	}
+
+	return id, entry, true, nil
+}
+
+// parseDefInt parses a decimal or hexadecimal (0x-prefixed) integer
+func parseDefInt(s string) (int, error) {
+	s, base := strings.TrimSpace(s), 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s, base = s[2:], 16
+	}
+
+	v, err := strconv.ParseInt(s, base, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	return int(v), nil
+}
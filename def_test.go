@@ -0,0 +1,56 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDefLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		id      int
+		targets []int
+		hue     int
+		ok      bool
+	}{
+		{line: "# comment", ok: false},
+		{line: "   ", ok: false},
+		{line: "16000 {15999} 0", id: 16000, targets: []int{15999}, ok: true},
+		{line: "400 {401, 402,403} 1153 # humans", id: 400, targets: []int{401, 402, 403}, hue: 1153, ok: true},
+		{line: "0x10 {0x20}", id: 16, targets: []int{32}, ok: true},
+		{line: "010 020", id: 10, targets: []int{20}, ok: true},
+		{line: "5 {6} -1", id: 5, targets: []int{6}, hue: -1, ok: true},
+	}
+
+	for _, tc := range tests {
+		id, entry, ok, err := parseDefLine(tc.line)
+		require.NoError(t, err, tc.line)
+		assert.Equal(t, tc.ok, ok, tc.line)
+		if !tc.ok {
+			continue
+		}
+
+		assert.Equal(t, tc.id, id, tc.line)
+		assert.Equal(t, tc.targets, entry.Targets(), tc.line)
+		assert.Equal(t, tc.hue, entry.Hue(), tc.line)
+	}
+}
+
+func TestParseDefLine_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"100",
+		"100 {}",
+		"100 } 5 {",
+		"abc {1}",
+		"100 {x}",
+		"100 {1} hue",
+	} {
+		_, _, _, err := parseDefLine(line)
+		assert.Error(t, err, line)
+	}
+}
@@ -7,8 +7,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"image"
-	"image/color"
 	"image/draw"
+	"io"
+	"math"
 
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
 )
@@ -189,6 +190,154 @@ func decodeARGB1555(width, height int, data []byte) *bitmap.ARGB1555 {
 	return img
 }
 
+// WriteFonts encodes the ASCII fonts, as loaded by Font and with their glyphs edited
+// through Rune, into the fonts.mul layout: for each font, a header followed by the size
+// and the ARGB1555 pixels of its 224 glyphs, starting at the space character.
+func WriteFonts(dst io.Writer, fonts []Font) error {
+	if len(fonts) != asciiFontsCount {
+		return fmt.Errorf("fonts.mul must hold %d fonts, got %d", asciiFontsCount, len(fonts))
+	}
+
+	var out []byte
+	for i, font := range fonts {
+		if font == nil {
+			return fmt.Errorf("font %d is nil", i)
+		}
+
+		// Keep the header and the unknown byte of every glyph of the fonts loaded by Font
+		ascii, _ := font.(*asciiFont)
+		if ascii != nil {
+			out = append(out, ascii.Header)
+		} else {
+			out = append(out, 0)
+		}
+
+		for k := 0; k < asciiGlyphCount; k++ {
+			img, width, height, err := glyphImage(font.Rune(rune(asciiFirstRune + k)))
+			if err != nil {
+				return fmt.Errorf("font %d char %d: %w", i, k, err)
+			}
+
+			var unk byte
+			if ascii != nil {
+				unk = ascii.Unk[k]
+			}
+
+			out = append(out, byte(width), byte(height), unk)
+			out = appendGlyphPixels(out, img, width, height)
+		}
+	}
+
+	if _, err := dst.Write(out); err != nil {
+		return fmt.Errorf("failed to write fonts.mul: %w", err)
+	}
+	return nil
+}
+
+// WriteFontUnicode encodes the Unicode font, as loaded by FontUnicode and with its glyphs
+// edited through Rune, into the unifont*.mul layout: a table of the offsets of the 65536
+// characters, followed by the offsets, size and bit-packed pixels of every glyph, where
+// any opaque pixel is drawn. Characters which are left unset are not written.
+func WriteFontUnicode(dst io.Writer, font Font) error {
+	if font == nil {
+		return fmt.Errorf("unicode font is nil")
+	}
+
+	table := make([]byte, unicodeFontSize*4)
+	var glyphs []byte
+	for i := 0; i < unicodeFontSize; i++ {
+		char := font.Rune(rune(i))
+		if char == nil || *char == (Rune{}) {
+			continue
+		}
+
+		img, width, height, err := glyphImage(char)
+		if err != nil {
+			return fmt.Errorf("char %d: %w", i, err)
+		}
+
+		binary.LittleEndian.PutUint32(table[i*4:], uint32(len(table)+len(glyphs)))
+		glyphs = append(glyphs, byte(char.XOffset), byte(char.YOffset), byte(width), byte(height))
+		glyphs = appendUnicodeBitmap(glyphs, img, width, height)
+	}
+
+	if _, err := dst.Write(append(table, glyphs...)); err != nil {
+		return fmt.Errorf("failed to write unifont.mul: %w", err)
+	}
+	return nil
+}
+
+// glyphImage returns the image of a glyph, if any, and its size given by the image or, if
+// it has none, by the width and height of the glyph
+func glyphImage(char *Rune) (image.Image, int, int, error) {
+	if char == nil {
+		return nil, 0, 0, nil
+	}
+
+	img := char.Image
+	if bmp, ok := img.(*bitmap.ARGB1555); ok && bmp == nil {
+		img = nil // Glyphs without pixels, as decoded by Font and FontUnicode
+	}
+
+	width, height := int(char.Width), int(char.Height)
+	if img != nil {
+		width, height = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+
+	if width < 0 || height < 0 || width > math.MaxInt8 || height > math.MaxInt8 {
+		return nil, 0, 0, fmt.Errorf("invalid glyph size %dx%d", width, height)
+	}
+	return img, width, height, nil
+}
+
+// appendGlyphPixels appends the ARGB1555 pixels of an ASCII glyph, where 0 is transparent
+// and opaque black is kept apart from it with the alpha bit. Glyphs without an image are
+// written as transparent.
+func appendGlyphPixels(dst []byte, img image.Image, width, height int) []byte {
+	if width == 0 || height == 0 {
+		return dst
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var value uint16
+			if img != nil {
+				bounds := img.Bounds()
+				switch v, opaque := encodeARGB1555(img.At(bounds.Min.X+x, bounds.Min.Y+y)); {
+				case opaque && v == 0:
+					value = 0x8000
+				case opaque:
+					value = v
+				}
+			}
+
+			dst = binary.LittleEndian.AppendUint16(dst, value)
+		}
+	}
+	return dst
+}
+
+// appendUnicodeBitmap appends the bit-packed rows of a Unicode glyph, the most significant
+// bit of every byte being its leftmost pixel
+func appendUnicodeBitmap(dst []byte, img image.Image, width, height int) []byte {
+	if width == 0 || height == 0 {
+		return dst
+	}
+
+	bytesPerRow := (width + 7) / 8
+	for y := 0; y < height; y++ {
+		row := make([]byte, bytesPerRow)
+		for x := 0; x < width && img != nil; x++ {
+			bounds := img.Bounds()
+			if _, opaque := encodeARGB1555(img.At(bounds.Min.X+x, bounds.Min.Y+y)); opaque {
+				row[x/8] |= 1 << (7 - x%8)
+			}
+		}
+		dst = append(dst, row...)
+	}
+	return dst
+}
+
 // unicodeFont implements Font for Unicode fonts (unifont*.mul)
 type unicodeFont struct {
 	Characters [unicodeFontSize]Rune
@@ -277,7 +426,13 @@ func (s *SDK) Text(font Font, text string, hue int) image.Image {
 	width, height := font.Size(text)
 	img := image.NewNRGBA(image.Rect(0, 0, width, height))
 
-	// Render each character
+	// Render each character, ASCII glyphs being shaded in full color
+	_, shaded := font.(*asciiFont)
+	var tint *Hue
+	if hue != 0 {
+		tint, _ = s.Hue(hue) // Text is left unchanged if the hue is not available
+	}
+
 	x := 0
 	runes := []rune(text)
 	for i, runeChar := range runes {
@@ -287,8 +442,15 @@ func (s *SDK) Text(font Font, text string, hue int) image.Image {
 			continue // Skip unsupported characters or characters without images
 		}
 
-		// Apply hue coloring to the character image
-		charImg := s.applyHueToImage(fontRune.Image, hue)
+		// Recolor the glyph through the hue: ASCII glyphs keep their shading, while the
+		// Unicode glyphs, which are single-color masks, take the brightest color of the hue
+		charImg := fontRune.Image
+		switch {
+		case tint != nil && shaded:
+			charImg = ApplyHueLuminance(charImg, tint, false)
+		case tint != nil:
+			charImg = ApplyHue(glyphMask(charImg), tint, false)
+		}
 
 		// Draw the character at the correct position
 		charX := x + int(fontRune.XOffset)
@@ -314,61 +476,11 @@ func (s *SDK) Text(font Font, text string, hue int) image.Image {
 	return img
 }
 
-// applyHueToImage applies a hue color to an image
-func (s *SDK) applyHueToImage(src image.Image, hueIndex int) image.Image {
-	if src == nil {
-		return nil
-	}
-	if hueIndex == 0 || s == nil {
-		return src
-	}
-
-	hue, err := s.Hue(hueIndex)
-	if err != nil || hue == nil {
-		// Fallback to original if hue not available
-		return src
-	}
-
+// glyphMask returns a white copy of the opaque pixels of a glyph, which ApplyHue recolors
+// with the brightest color of the hue
+func glyphMask(src image.Image) image.Image {
 	bounds := src.Bounds()
-	dst := image.NewNRGBA(bounds)
-
-	// Pick a palette color suited for text. Prefer the end of the table (brightest)
-	// falling back to a mid-range if out of bounds.
-	paletteIndex := int(hue.TableEnd)
-	if paletteIndex < 0 || paletteIndex >= 32 {
-		paletteIndex = 31
-	}
-	hueColor, herr := hue.GetColor(paletteIndex)
-	if herr != nil {
-		hueColor = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
-	}
-	hr, hg, hb, _ := hueColor.RGBA()
-
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			sr, sg, sb, sa := src.At(x, y).RGBA()
-			if sa == 0 {
-				// Keep transparent pixels
-				dst.Set(x, y, color.NRGBA{R: 0, G: 0, B: 0, A: 0})
-				continue
-			}
-
-			// For Unicode font glyphs, source pixels are bitmask black (opaque) or transparent.
-			// Apply the hue color directly and preserve the original alpha.
-			na := uint8(sa >> 8)
-			nr := uint8(hr >> 8)
-			ng := uint8(hg >> 8)
-			nb := uint8(hb >> 8)
-
-			// If the glyph image contains grayscale intensity, we could modulate.
-			// However, UO unicode fonts are binary; use full hue color for visibility.
-			_ = sr
-			_ = sg
-			_ = sb
-
-			dst.Set(x, y, color.NRGBA{R: nr, G: ng, B: nb, A: na})
-		}
-	}
-
-	return dst
+	mask := image.NewNRGBA(bounds)
+	draw.DrawMask(mask, bounds, image.White, image.Point{}, src, bounds.Min, draw.Src)
+	return mask
 }
@@ -4,9 +4,16 @@
 package ultima
 
 import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFont_Load(t *testing.T) {
@@ -61,3 +68,116 @@ func TestFont_Load(t *testing.T) {
 		})
 	})
 }
+
+func TestSDK_WriteFonts(t *testing.T) {
+	dir := t.TempDir()
+	fonts := make([]Font, asciiFontsCount)
+	for i := range fonts {
+		fonts[i] = newTestFont()
+	}
+
+	// A custom glyph with opaque black, which must not become transparent
+	custom := bitmap.NewARGB1555(image.Rect(0, 0, 3, 1))
+	custom.Set(0, 0, bitmap.ARGB1555Color(0x801F))
+	custom.Set(1, 0, bitmap.ARGB1555Color(0x8000))
+	first := fonts[0].(*asciiFont)
+	first.Header, first.Unk['z'-asciiFirstRune] = 3, 5
+	*first.Rune('z') = Rune{Image: custom, Width: 3, Height: 1}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteFonts(&buffer, fonts))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fonts.mul"), buffer.Bytes(), 0644))
+	assert.Error(t, WriteFonts(&buffer, fonts[:1]))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	loaded, err := sdk.Font()
+	require.NoError(t, err)
+	require.Len(t, loaded, asciiFontsCount)
+	assert.Equal(t, byte(3), loaded[0].(*asciiFont).Header)
+	assert.Equal(t, byte(5), loaded[0].(*asciiFont).Unk['z'-asciiFirstRune])
+
+	glyph := loaded[0].Rune('z')
+	assert.Equal(t, int8(3), glyph.Width)
+	assert.Equal(t, bitmap.ARGB1555Color(0x001F), glyph.Image.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0x8000), glyph.Image.At(1, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0), glyph.Image.At(2, 0))
+	assert.Equal(t, fonts[1].Rune('a').Image.At(1, 1).(bitmap.ARGB1555Color)&0x7FFF, loaded[1].Rune('a').Image.At(1, 1))
+}
+
+func TestSDK_WriteFontUnicode(t *testing.T) {
+	dir := t.TempDir()
+	glyph := bitmap.NewARGB1555(image.Rect(0, 0, 10, 2))
+	glyph.Set(0, 0, bitmap.ARGB1555Color(0xFC00))
+	glyph.Set(9, 1, bitmap.ARGB1555Color(0x8000))
+
+	font := &unicodeFont{}
+	*font.Rune('A') = Rune{Image: glyph, Width: 10, Height: 2, XOffset: 1, YOffset: 4}
+	*font.Rune(' ') = Rune{Width: 4}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteFontUnicode(&buffer, font))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unifont1.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	loaded, err := sdk.FontUnicode(1)
+	require.NoError(t, err)
+
+	char := loaded.Rune('A')
+	assert.Equal(t, int8(10), char.Width)
+	assert.Equal(t, int8(2), char.Height)
+	assert.Equal(t, int8(1), char.XOffset)
+	assert.Equal(t, int8(4), char.YOffset)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 10; x++ {
+			_, _, _, expect := glyph.At(x, y).RGBA()
+			_, _, _, actual := char.Image.At(x, y).RGBA()
+			assert.Equal(t, expect != 0, actual != 0, "pixel (%d,%d)", x, y)
+		}
+	}
+
+	assert.Equal(t, int8(4), loaded.Rune(' ').Width)
+	assert.Equal(t, Rune{}, *loaded.Rune('B'))
+}
+
+func TestSDK_TextHue(t *testing.T) {
+	red := &Hue{Index: 1, Name: "Red"}
+	for i := range red.Colors {
+		red.Colors[i] = uint16(i) << 10
+	}
+
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteHues(&buffer, []*Hue{red}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hues.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	// A white glyph with a dark gray shadow keeps its shading once recolored
+	glyph := bitmap.NewARGB1555(image.Rect(0, 0, 2, 1))
+	glyph.Set(0, 0, bitmap.ARGB1555Color(0xFFFF))
+	glyph.Set(1, 0, bitmap.ARGB1555Color(0xA108))
+	font := newTestFont()
+	*font.Rune('z') = Rune{Image: glyph, Width: 2, Height: 1}
+
+	img := sdk.Text(font, "z", 1)
+	require.NotNil(t, img)
+	assert.Equal(t, color.NRGBAModel.Convert(bitmap.ARGB1555Color(0xFC00)), img.At(0, 0))
+	assert.Equal(t, color.NRGBAModel.Convert(bitmap.ARGB1555Color(0xA000)), img.At(1, 0))
+	assert.Equal(t, color.NRGBAModel.Convert(bitmap.ARGB1555Color(0xA108)), sdk.Text(font, "z", 0).At(1, 0))
+
+	// A Unicode glyph, a black mask, takes the brightest color of the hue
+	unicode := &unicodeFont{}
+	unicode.Characters['z'] = Rune{Image: decodeUnicodeBitmap(2, 1, []byte{0x80}), Width: 2, Height: 1}
+	img = sdk.Text(unicode, "z", 1)
+	assert.Equal(t, color.NRGBAModel.Convert(bitmap.ARGB1555Color(0xFC00)), img.At(0, 0))
+	assert.Equal(t, color.NRGBA{}, img.At(1, 0))
+	assert.Equal(t, color.NRGBAModel.Convert(bitmap.ARGB1555Color(0x8000)), sdk.Text(unicode, "z", 0).At(0, 0))
+}
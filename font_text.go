@@ -0,0 +1,125 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+)
+
+// TextAlign is the horizontal alignment of the lines rendered by TextWithOptions
+type TextAlign int
+
+const (
+	AlignLeft   TextAlign = iota // Lines start at the left edge
+	AlignCenter                  // Lines are centered
+	AlignRight                   // Lines end at the right edge
+)
+
+// TextOptions configures the layout of the text rendered by TextWithOptions
+type TextOptions struct {
+	Hue         int       // Hue of the text, as given to Text
+	MaxWidth    int       // Width at which lines are wrapped between words, 0 to only break lines at "\n"
+	Align       TextAlign // Alignment of the lines within the image
+	LineSpacing int       // Extra pixels between consecutive lines
+}
+
+// TextWithOptions renders text over multiple lines, as books and gumps lay it out. Lines
+// are broken at "\n" and, if MaxWidth is set, wrapped between words so that they fit in
+// it, words wider than MaxWidth being broken between characters. The image is MaxWidth
+// wide (or as wide as the widest line without it), each line being aligned within it.
+func (s *SDK) TextWithOptions(font Font, text string, opts TextOptions) image.Image {
+	lines := wrapText(font, text, opts.MaxWidth)
+
+	// Every line has the height of the tallest one, so that lines are evenly spaced
+	width, lineHeight := opts.MaxWidth, 0
+	for _, line := range lines {
+		w, h := font.Size(line)
+		lineHeight = max(lineHeight, h)
+		if opts.MaxWidth <= 0 {
+			width = max(width, w)
+		}
+	}
+
+	height := len(lines)*lineHeight + (len(lines)-1)*opts.LineSpacing
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, line := range lines {
+		src := s.Text(font, line, opts.Hue)
+		if src == nil {
+			continue
+		}
+
+		x, y := 0, i*(lineHeight+opts.LineSpacing)
+		switch opts.Align {
+		case AlignCenter:
+			x = (width - src.Bounds().Dx()) / 2
+		case AlignRight:
+			x = width - src.Bounds().Dx()
+		}
+
+		draw.Draw(img, src.Bounds().Sub(src.Bounds().Min).Add(image.Pt(x, y)), src, src.Bounds().Min, draw.Over)
+	}
+	return img
+}
+
+// wrapText breaks the text into lines at "\n" and, if maxWidth is set, between words so
+// that every line fits in maxWidth
+func wrapText(font Font, text string, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		if maxWidth <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" {
+				if w, _ := font.Size(line + " " + word); w <= maxWidth {
+					line += " " + word
+					continue
+				}
+
+				lines = append(lines, line)
+				line = ""
+			}
+
+			// Break the words which do not fit on a line of their own
+			for {
+				if w, _ := font.Size(word); w <= maxWidth {
+					break
+				}
+
+				head := fitText(font, word, maxWidth)
+				if head == word {
+					break
+				}
+
+				lines = append(lines, head)
+				word = word[len(head):]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitText returns the longest prefix of the text which fits in maxWidth, keeping at least
+// one character so that wrapping always progresses
+func fitText(font Font, text string, maxWidth int) string {
+	end := 0
+	for i, r := range text {
+		if w, _ := font.Size(text[:i+len(string(r))]); w > maxWidth && end > 0 {
+			break
+		}
+		end = i + len(string(r))
+	}
+	return text[:end]
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFont returns an ASCII font whose letters are opaque 2x2 squares
+func newTestFont() *asciiFont {
+	font := &asciiFont{Height: 2}
+	for r := 'a'; r <= 'z'; r++ {
+		img := bitmap.NewARGB1555(image.Rect(0, 0, 2, 2))
+		for i := 1; i < len(img.Pix); i += 2 {
+			img.Pix[i] = 0xFC // Opaque red
+		}
+
+		*font.Rune(r) = Rune{Image: img, Width: 2, Height: 2}
+	}
+	return font
+}
+
+func TestWrapText(t *testing.T) {
+	font := newTestFont()
+	assert.Equal(t, []string{"ab cd", "ef"}, wrapText(font, "ab cd ef", 20))
+	assert.Equal(t, []string{"ab", "cd", "ef"}, wrapText(font, "ab  cd ef", 12))
+	assert.Equal(t, []string{"abcd", "efgh", "ij", ""}, wrapText(font, "abcdefgh ij\n", 12))
+	assert.Equal(t, []string{"a", "b"}, wrapText(font, "ab", 1))
+	assert.Equal(t, []string{"ab cd ef", "gh"}, wrapText(font, "ab cd ef\ngh", 0))
+}
+
+func TestSDK_TextWithOptions(t *testing.T) {
+	sdk := &SDK{}
+	font := newTestFont()
+
+	for align, x := range map[TextAlign]int{AlignLeft: 0, AlignCenter: 3, AlignRight: 7} {
+		img := sdk.TextWithOptions(font, "ab cd ef", TextOptions{
+			MaxWidth:    12,
+			Align:       align,
+			LineSpacing: 1,
+		})
+		require.NotNil(t, img)
+		assert.Equal(t, image.Rect(0, 0, 12, 8), img.Bounds())
+
+		// Every line is 5 pixels wide and 2 pixels tall, followed by a spacing of 1
+		for _, y := range []int{0, 3, 6} {
+			_, _, _, a := img.At(x, y).RGBA()
+			assert.NotZero(t, a, "align %d, line at %d", align, y)
+			_, _, _, a = img.At(x+5, y).RGBA()
+			assert.Zero(t, a, "align %d, line at %d", align, y)
+		}
+	}
+
+	// Without a maximum width, the image is as wide as the widest line
+	img := sdk.TextWithOptions(font, "ab\nabcd", TextOptions{})
+	require.NotNil(t, img)
+	assert.Equal(t, image.Rect(0, 0, 11, 4), img.Bounds())
+	assert.Nil(t, sdk.TextWithOptions(font, "", TextOptions{}))
+}
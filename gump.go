@@ -7,11 +7,16 @@ import (
 	"encoding/binary"
 	"fmt"
 	"image"
+	"image/draw"
+	"io"
 	"iter"
 	"math"
+	"slices"
 
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
 	"github.com/kelindar/ultima-sdk/internal/uofile"
+	"github.com/kelindar/ultima-sdk/internal/uop"
 )
 
 // Gump represents a UI element or graphic.
@@ -22,6 +27,21 @@ type Gump struct {
 	Image  image.Image // Image of the gump
 }
 
+// NRGBA returns the image of the gump as an image.NRGBA, converting the ARGB1555 pixels
+// in bulk rather than one by one through image/draw. Returns nil if there is no image.
+func (g *Gump) NRGBA() *image.NRGBA {
+	switch img := g.Image.(type) {
+	case nil:
+		return nil
+	case *bitmap.ARGB1555:
+		return img.NRGBA()
+	default:
+		out := image.NewNRGBA(img.Bounds())
+		draw.Draw(out, out.Rect, img, img.Bounds().Min, draw.Src)
+		return out
+	}
+}
+
 // Gump retrieves a specific gump graphic by its ID.
 // It handles reading from .mul or UOP files.
 // The returned Gump object allows for lazy loading of its image.
@@ -32,16 +52,67 @@ func (s *SDK) Gump(id int) (*Gump, error) {
 	}
 
 	g, err := uofile.Decode(file, uint32(id), decodeGump)
-	if err != nil {
+	switch {
+	case err != nil:
 		return nil, err
+	case g == nil:
+		return nil, fmt.Errorf("gump %d does not exist", id)
 	}
 
 	g.ID = id
 	return g, nil
 }
 
-// Gumps returns an iterator over metadata (ID, width, height) for all available gumps.
-// This is efficient for listing gumps without loading all their pixel data.
+// DecodeGumpInto decodes the gump with the given ID into the bitmap, resizing it to the
+// dimensions of the gump while reusing its pixel buffer, so that batch renderers avoid
+// allocating an image per gump.
+func (s *SDK) DecodeGumpInto(dst *Bitmap, id int) error {
+	file, err := s.loadGump()
+	if err != nil {
+		return err
+	}
+
+	found, err := uofile.Decode(file, uint32(id), func(data []byte, extra uint64) (bool, error) {
+		width, height, err := gumpSize(extra)
+		if err != nil {
+			return true, err
+		}
+
+		if err := decodeGumpInto(dst, data, width, height); err != nil {
+			return true, fmt.Errorf("%w: failed to decode gump: %v", ErrInvalidArtData, err)
+		}
+		return true, nil
+	})
+	switch {
+	case err != nil:
+		return err
+	case !found:
+		return fmt.Errorf("gump %d does not exist", id)
+	default:
+		return nil
+	}
+}
+
+// GumpWithHue retrieves a gump by its ID, recolored through the hue with the given
+// index (as accepted by Hue), as the client tints paperdoll and container gumps. A hue
+// of 0 leaves the gump unchanged. Only grayscale pixels are recolored if partial is set.
+func (s *SDK) GumpWithHue(id, hue int, partial bool) (*Gump, error) {
+	gump, err := s.Gump(id)
+	if err != nil || hue == 0 {
+		return gump, err
+	}
+
+	h, err := s.Hue(hue)
+	if err != nil {
+		return nil, err
+	}
+
+	gump.Image = ApplyHue(gump.Image, h, partial)
+	return gump, nil
+}
+
+// Gumps returns an iterator over all available gumps, decoding the image of each one.
+// Use GumpInfos to list gumps without loading their pixel data.
 func (s *SDK) Gumps() iter.Seq[*Gump] {
 	return func(yield func(*Gump) bool) {
 		file, err := s.loadGump()
@@ -63,9 +134,69 @@ func (s *SDK) Gumps() iter.Seq[*Gump] {
 	}
 }
 
-func decodeGump(data []byte, extra uint64) (*Gump, error) {
-	width := int(extra & 0xFFFF)
-	height := int((extra >> 32) & 0xFFFF)
+// GumpInfos returns an iterator over the metadata (ID, width and height) of all
+// available gumps, without their image. Unlike Gumps, only the index of the gump
+// files is read, which makes listing gumps near-instant.
+func (s *SDK) GumpInfos() iter.Seq[*Gump] {
+	return func(yield func(*Gump) bool) {
+		file, err := s.loadGump()
+		if err != nil {
+			return
+		}
+
+		for id := range file.Entries() {
+			width, height, err := gumpEntrySize(file, id)
+			if err != nil {
+				continue
+			}
+
+			if !yield(&Gump{ID: int(id), Width: width, Height: height}) {
+				break
+			}
+		}
+	}
+}
+
+// GumpExists returns whether the client has a gump with the given ID, looking up the
+// index of the gump files only, without decoding its image.
+func (s *SDK) GumpExists(id int) bool {
+	_, _, err := s.GumpSize(id)
+	return err == nil
+}
+
+// GumpSize returns the dimensions of the gump with the given ID, as stored in the index
+// of the gump files, without decoding its image.
+func (s *SDK) GumpSize(id int) (width, height int, err error) {
+	if id < 0 || id >= 0xFFFF {
+		return 0, 0, fmt.Errorf("gump ID %d out of range [0-%d]", id, 0xFFFF-1)
+	}
+
+	file, err := s.loadGump()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return gumpEntrySize(file, uint32(id))
+}
+
+// gumpEntrySize returns the dimensions of the gump entry, or an error if it is missing
+func gumpEntrySize(file *uofile.File, id uint32) (width, height int, err error) {
+	entry, err := file.Entry(id)
+	switch {
+	case err != nil:
+		return 0, 0, err
+	case entry == nil || entry.Len() == 0:
+		return 0, 0, fmt.Errorf("gump %d does not exist", id)
+	default:
+		return gumpSize(entry.Extra())
+	}
+}
+
+// gumpSize returns the dimensions of a gump from the extra data of its entry, packed
+// as width and height in 16 bits each in gumpidx.mul, or in 32 bits each in UOP files
+func gumpSize(extra uint64) (width, height int, err error) {
+	width = int(extra & 0xFFFF)
+	height = int((extra >> 32) & 0xFFFF)
 
 	if extra < math.MaxUint32 {
 		height = int(extra & 0xFFFF)
@@ -74,7 +205,15 @@ func decodeGump(data []byte, extra uint64) (*Gump, error) {
 
 	// Sanity check
 	if width <= 0 || height <= 0 || width > 2048 || height > 2048 {
-		return nil, fmt.Errorf("%w: invalid gump dimensions %dx%d", ErrInvalidArtData, width, height)
+		return 0, 0, fmt.Errorf("%w: invalid gump dimensions %dx%d", ErrInvalidArtData, width, height)
+	}
+	return width, height, nil
+}
+
+func decodeGump(data []byte, extra uint64) (*Gump, error) {
+	width, height, err := gumpSize(extra)
+	if err != nil {
+		return nil, err
 	}
 
 	img, err := decodeGumpData(data, width, height)
@@ -89,47 +228,161 @@ func decodeGump(data []byte, extra uint64) (*Gump, error) {
 	}, nil
 }
 
-// decodeGumpData converts raw gump data into an image.Image (RGBA8888).
+// decodeGumpData converts raw gump data into an image.Image (ARGB1555).
 func decodeGumpData(data []byte, width, height int) (image.Image, error) {
-	need := height * 4
-	if len(data) < need {
-		return nil, fmt.Errorf("data too short for lookup table")
+	img := new(bitmap.ARGB1555)
+	if err := decodeGumpInto(img, data, width, height); err != nil {
+		return nil, err
 	}
+	return img, nil
+}
 
-	// Save binary data to file
-	//	os.WriteFile("gump-KO.bin", data, 0644)
-
-	// Parse lookup table (height * uint32).
-	lookup := make([]uint32, height)
-	for y := 0; y < height; y++ {
-		lookup[y] = binary.LittleEndian.Uint32(data[y*4:])
+// decodeGumpInto decodes raw gump data into the bitmap, resized to the dimensions
+func decodeGumpInto(img *bitmap.ARGB1555, data []byte, width, height int) error {
+	need := height * 4
+	if len(data) < need {
+		return fmt.Errorf("data too short for lookup table")
 	}
 
-	// Stage-1: decode straight into 1555 buffer.
-	img1555 := bitmap.NewARGB1555(image.Rect(0, 0, width, height))
-
+	// Decode straight into the 1555 buffer, each line starting at the offset given by
+	// the lookup table (height * uint32)
+	resetBitmap(img, width, height)
 	for y := 0; y < height; y++ {
-		pos := int(lookup[y]) * 4 // byte offset from table start
+		pos := int(binary.LittleEndian.Uint32(data[y*4:])) * 4 // byte offset from table start
 		x := 0
 		for x < width {
 			if pos+3 >= len(data) {
-				return nil, fmt.Errorf("RLE overflow at line %d", y)
+				return fmt.Errorf("RLE overflow at line %d", y)
 			}
 			color16 := binary.LittleEndian.Uint16(data[pos:])
 			count := int(binary.LittleEndian.Uint16(data[pos+2:]))
 			pos += 4
 
 			for i := 0; i < count && x < width; i++ {
-				off := y*img1555.Stride + x*2
-				img1555.Pix[off] = byte(color16)
-				img1555.Pix[off+1] = byte(color16 >> 8)
+				off := y*img.Stride + x*2
+				img.Pix[off] = byte(color16)
+				img.Pix[off+1] = byte(color16 >> 8)
 				x++
 			}
 		}
 		if x != width {
-			return nil, fmt.Errorf("scan-line %d decoded %d/%d pixels", y, x, width)
+			return fmt.Errorf("scan-line %d decoded %d/%d pixels", y, x, width)
+		}
+	}
+
+	return nil
+}
+
+// WriteGumps encodes the gumps and writes them as a gumpart.mul/gumpidx.mul pair, the
+// dimensions of every gump being stored in its index entry. Gumps may be given in any
+// order, missing IDs are written as empty index entries.
+func WriteGumps(gumpMul, gumpIdx io.Writer, gumps []*Gump) error {
+	sorted, err := sortGumps(gumps)
+	if err != nil {
+		return err
+	}
+
+	w := mul.NewWriter(gumpMul, gumpIdx)
+	for _, gump := range sorted {
+		data, err := encodeGump(gump.Image)
+		if err != nil {
+			return fmt.Errorf("failed to encode gump %d: %w", gump.ID, err)
+		}
+
+		size := gump.Image.Bounds().Size()
+		if err := w.Write(uint32(gump.ID), data, uint32(size.X<<16|size.Y)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteGumpsUOP encodes the gumps like WriteGumps, but wrapped in the
+// gumpartLegacyMUL.uop format used by newer clients, where the dimensions of every
+// gump precede its data.
+func WriteGumpsUOP(dst io.Writer, gumps []*Gump) error {
+	sorted, err := sortGumps(gumps)
+	if err != nil {
+		return err
+	}
+
+	var entries [][]byte
+	if len(sorted) > 0 {
+		entries = make([][]byte, sorted[len(sorted)-1].ID+1)
+	}
+
+	for _, gump := range sorted {
+		data, err := encodeGump(gump.Image)
+		if err != nil {
+			return fmt.Errorf("failed to encode gump %d: %w", gump.ID, err)
+		}
+
+		size := gump.Image.Bounds().Size()
+		entry := binary.LittleEndian.AppendUint32(nil, uint32(size.X))
+		entry = binary.LittleEndian.AppendUint32(entry, uint32(size.Y))
+		entries[gump.ID] = append(entry, data...)
+	}
+
+	return uop.Write(dst, "gumpartlegacymul", ".tga", entries)
+}
+
+// sortGumps returns the gumps sorted by ID, validating their IDs
+func sortGumps(gumps []*Gump) ([]*Gump, error) {
+	sorted := slices.Clone(gumps)
+	slices.SortFunc(sorted, func(a, b *Gump) int {
+		return a.ID - b.ID
+	})
+
+	for i, gump := range sorted {
+		switch {
+		case gump.ID < 0 || gump.ID >= 0xFFFF:
+			return nil, fmt.Errorf("gump ID %d out of range [0-%d]", gump.ID, 0xFFFF-1)
+		case i > 0 && sorted[i-1].ID == gump.ID:
+			return nil, fmt.Errorf("duplicate gump ID %d", gump.ID)
+		}
+	}
+	return sorted, nil
+}
+
+// encodeGump converts an image into the gump layout: a lookup table with the offset of
+// every line (in 4-byte units), followed by the lines as runs of pixels of the same
+// color, each run being a color and its length. Transparent pixels are stored as 0.
+func encodeGump(img image.Image) ([]byte, error) {
+	if img == nil {
+		return nil, fmt.Errorf("%w: gump image is nil", ErrInvalidArtData)
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || width > 2048 || height > 2048 {
+		return nil, fmt.Errorf("%w: invalid gump dimensions %dx%d", ErrInvalidArtData, width, height)
+	}
+
+	colorAt := func(x, y int) uint16 {
+		value, opaque := encodeARGB1555(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		switch {
+		case !opaque:
+			return 0
+		case value == 0:
+			return 1 // Opaque black, as 0 is transparent
+		default:
+			return value
 		}
 	}
 
-	return img1555, nil
+	out := make([]byte, height*4)
+	for y := 0; y < height; y++ {
+		binary.LittleEndian.PutUint32(out[y*4:], uint32(len(out)/4))
+		for x := 0; x < width; {
+			value, count := colorAt(x, y), 1
+			for x+count < width && colorAt(x+count, y) == value {
+				count++
+			}
+
+			out = binary.LittleEndian.AppendUint16(out, value)
+			out = binary.LittleEndian.AppendUint16(out, uint16(count))
+			x += count
+		}
+	}
+	return out, nil
 }
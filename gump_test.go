@@ -4,9 +4,16 @@
 package ultima
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -86,3 +93,152 @@ func TestGump(t *testing.T) {
 		})
 	})
 }
+
+// writeTestGumps writes gumpart.mul and gumpidx.mul with the given images, keyed by
+// gump ID
+func writeTestGumps(t *testing.T, dir string, images map[int]*bitmap.ARGB1555) {
+	gumps := make([]*Gump, 0, len(images))
+	for id, img := range images {
+		gumps = append(gumps, &Gump{ID: id, Image: img})
+	}
+
+	var data, index bytes.Buffer
+	require.NoError(t, WriteGumps(&data, &index, gumps))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gumpart.mul"), data.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gumpidx.mul"), index.Bytes(), 0644))
+}
+
+func TestWriteGumps(t *testing.T) {
+	img := bitmap.NewARGB1555(image.Rect(0, 0, 5, 3))
+	img.Set(0, 0, bitmap.ARGB1555Color(0xFC00))
+	img.Set(1, 0, bitmap.ARGB1555Color(0xFC00))
+	img.Set(4, 1, bitmap.ARGB1555Color(0x801F))
+	img.Set(2, 2, color.NRGBA{A: 0xFF}) // Opaque black
+	gumps := []*Gump{{ID: 7, Image: img}, {ID: 3, Image: newTestGump(2, 4, image.Rect(0, 0, 2, 4), 0x83E0)}}
+
+	var data, index, packed bytes.Buffer
+	require.NoError(t, WriteGumps(&data, &index, gumps))
+	require.NoError(t, WriteGumpsUOP(&packed, gumps))
+
+	legacy, modern := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "gumpart.mul"), data.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "gumpidx.mul"), index.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(modern, "gumpartLegacyMUL.uop"), packed.Bytes(), 0644))
+
+	for _, dir := range []string{legacy, modern} {
+		sdk, err := Open(dir)
+		require.NoError(t, err)
+
+		gump, err := sdk.Gump(7)
+		require.NoError(t, err)
+		assert.Equal(t, 5, gump.Width)
+		assert.Equal(t, 3, gump.Height)
+		assert.Equal(t, bitmap.ARGB1555Color(0x7C00), gump.Image.At(0, 0))
+		assert.Equal(t, bitmap.ARGB1555Color(0x7C00), gump.Image.At(1, 0))
+		assert.Equal(t, bitmap.ARGB1555Color(0x001F), gump.Image.At(4, 1))
+		assert.Equal(t, bitmap.ARGB1555Color(0x0001), gump.Image.At(2, 2), "opaque black")
+		assert.Equal(t, bitmap.ARGB1555Color(0), gump.Image.At(2, 0), "transparent")
+
+		gump, err = sdk.Gump(3)
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 2, 4), gump.Image.Bounds())
+
+		_, err = sdk.Gump(5)
+		assert.Error(t, err)
+
+		// Only the dimensions are listed, without decoding the images
+		var infos []Gump
+		for info := range sdk.GumpInfos() {
+			infos = append(infos, *info)
+		}
+		assert.Equal(t, []Gump{{ID: 3, Width: 2, Height: 4}, {ID: 7, Width: 5, Height: 3}}, infos)
+
+		width, height, err := sdk.GumpSize(7)
+		require.NoError(t, err)
+		assert.Equal(t, [2]int{5, 3}, [2]int{width, height})
+		assert.True(t, sdk.GumpExists(3))
+		assert.False(t, sdk.GumpExists(5))
+		assert.False(t, sdk.GumpExists(100))
+		assert.False(t, sdk.GumpExists(-1))
+		_, _, err = sdk.GumpSize(5)
+		assert.Error(t, err)
+		require.NoError(t, sdk.Close())
+	}
+
+	assert.Error(t, WriteGumps(&data, &index, []*Gump{{ID: 1, Image: img}, {ID: 1, Image: img}}))
+	assert.Error(t, WriteGumps(&data, &index, []*Gump{{ID: 1}}))
+	assert.Error(t, WriteGumpsUOP(&packed, []*Gump{{ID: -1, Image: img}}))
+}
+
+func TestSDK_GumpWithHue(t *testing.T) {
+	img := newTestGump(3, 1, image.Rect(0, 0, 2, 1), 0xC210) // Gray
+	img.Set(2, 0, bitmap.ARGB1555Color(0xFC00))
+
+	dir := t.TempDir()
+	writeTestGumps(t, dir, map[int]*bitmap.ARGB1555{1: img})
+	writeTestHues(t, dir, map[int]uint16{33: 0x03E0})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	gump, err := sdk.GumpWithHue(1, 33, false)
+	require.NoError(t, err)
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), gump.Image.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), gump.Image.At(2, 0))
+
+	gump, err = sdk.GumpWithHue(1, 33, true)
+	require.NoError(t, err)
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), gump.Image.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), gump.Image.At(2, 0), "not gray")
+
+	gump, err = sdk.GumpWithHue(1, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, bitmap.ARGB1555Color(0x4210), gump.Image.At(0, 0), "unchanged")
+
+	_, err = sdk.GumpWithHue(1, 5000, false)
+	assert.Error(t, err)
+}
+
+func TestSDK_DecodeGumpInto(t *testing.T) {
+	dir := t.TempDir()
+	writeTestGumps(t, dir, map[int]*bitmap.ARGB1555{
+		1: newTestGump(8, 8, image.Rect(0, 0, 8, 8), 0xFC00),
+		2: newTestGump(3, 2, image.Rect(1, 1, 2, 2), 0x801F),
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	var dst Bitmap
+	require.NoError(t, sdk.DecodeGumpInto(&dst, 1))
+	assert.Equal(t, image.Rect(0, 0, 8, 8), dst.Bounds())
+	assert.Equal(t, bitmap.ARGB1555Color(0x7C00), dst.At(7, 7))
+	buffer := &dst.Pix[0]
+
+	// The smaller gump reuses the buffer of the first one
+	require.NoError(t, sdk.DecodeGumpInto(&dst, 2))
+	assert.Equal(t, image.Rect(0, 0, 3, 2), dst.Bounds())
+	assert.Equal(t, bitmap.ARGB1555Color(0x001F), dst.At(1, 1))
+	assert.Equal(t, bitmap.ARGB1555Color(0), dst.At(0, 0))
+	assert.Same(t, buffer, &dst.Pix[0])
+
+	assert.Error(t, sdk.DecodeGumpInto(&dst, 3))
+}
+
+func TestGump_NRGBA(t *testing.T) {
+	img := newTestGump(4, 3, image.Rect(1, 0, 3, 2), 0x7C1F)
+	gump := &Gump{Image: img}
+
+	expect := image.NewNRGBA(img.Rect)
+	draw.Draw(expect, expect.Rect, img, image.Point{}, draw.Src)
+	assert.Equal(t, expect, gump.NRGBA())
+	assert.Equal(t, color.NRGBA{R: 0xFF, B: 0xFF, A: 0xFF}, gump.NRGBA().NRGBAAt(1, 0))
+	assert.Equal(t, color.NRGBA{}, gump.NRGBA().NRGBAAt(0, 0))
+
+	// Other images are converted as well
+	gump.Image = expect
+	assert.Equal(t, expect, gump.NRGBA())
+	assert.Nil(t, (&Gump{}).NRGBA())
+}
@@ -6,10 +6,12 @@ package ultima
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
 	"image/color"
+	"io"
 	"iter"
 	"strings"
 
@@ -23,11 +25,11 @@ var (
 
 // Hue defines a color palette used for re-coloring game assets
 type Hue struct {
-	Index      int        // Index of this hue
-	Name       string     // Name of this hue
-	Colors     [32]uint16 // Raw 16-bit color values (ARGB1555)
-	TableStart uint16     // Start index of the hue table
-	TableEnd   uint16     // End index of the hue table
+	Index      int        `json:"index"`      // Index of this hue
+	Name       string     `json:"name"`       // Name of this hue
+	Colors     [32]uint16 `json:"colors"`     // Raw 16-bit color values (ARGB1555)
+	TableStart uint16     `json:"tableStart"` // Start index of the hue table
+	TableEnd   uint16     `json:"tableEnd"`   // End index of the hue table
 }
 
 // GetColor returns a standard Go color.Color for a specific entry in the hue's palette
@@ -42,6 +44,17 @@ func (h *Hue) GetColor(paletteIndex int) (color.Color, error) {
 	return bitmap.ARGB1555Color(colorValue), nil
 }
 
+// Palette returns the 32 colors of the hue as a color palette, followed by a transparent
+// color at index 32, so that recolored assets can be quantized or encoded as paletted
+// PNG and GIF images. The first 32 entries share their index with Colors.
+func (h *Hue) Palette() color.Palette {
+	palette := make(color.Palette, 0, len(h.Colors)+1)
+	for _, value := range h.Colors {
+		palette = append(palette, bitmap.ARGB1555Color(value|0x8000))
+	}
+	return append(palette, bitmap.ARGB1555Color(0))
+}
+
 // Image generates a small image.Image representing this hue's palette for visualization
 func (h *Hue) Image(widthPerColor, height int) image.Image {
 	width := widthPerColor * len(h.Colors)
@@ -65,6 +78,79 @@ func (h *Hue) Image(widthPerColor, height int) image.Image {
 	return img
 }
 
+// ApplyHue recolors an image (such as art, a gump or an animation frame) through the
+// hue's 32-color table, as the client does: the 5-bit red channel of each pixel, which
+// is its luminance for the grayscale art meant to be hued, selects the replacement
+// color. When partial is set, only grayscale pixels (equal red, green and blue) are
+// recolored. Transparent pixels are left transparent; a nil image or hue returns nil.
+func ApplyHue(src image.Image, hue *Hue, partial bool) *Bitmap {
+	return recolorImage(src, hue, partial, func(r, _, _ uint16) uint16 {
+		return r
+	})
+}
+
+// ApplyHueLuminance recolors an image through the hue's 32-color table as ApplyHue does,
+// but selects the replacement color by the luminance of each pixel rather than its red
+// channel. Grayscale pixels are recolored the same way, while colored pixels keep their
+// shading (a pure blue being dark, rather than the darkest color of the table).
+func ApplyHueLuminance(src image.Image, hue *Hue, partial bool) *Bitmap {
+	return recolorImage(src, hue, partial, luminance)
+}
+
+// luminance returns the perceived brightness of a color, from its 5-bit channels
+func luminance(r, g, b uint16) uint16 {
+	return uint16((uint32(r)*299 + uint32(g)*587 + uint32(b)*114 + 500) / 1000)
+}
+
+// recolorImage replaces the opaque pixels of the image by the color of the hue's table
+// at the index given by the shade of their 5-bit channels
+func recolorImage(src image.Image, hue *Hue, partial bool, shade func(r, g, b uint16) uint16) *Bitmap {
+	if src == nil || hue == nil {
+		return nil
+	}
+
+	bounds := src.Bounds()
+	dst := bitmap.NewARGB1555(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			value, opaque := encodeARGB1555(src.At(x, y))
+			if !opaque {
+				continue
+			}
+
+			r := (value >> 10) & 0x1F
+			g := (value >> 5) & 0x1F
+			b := value & 0x1F
+			if !partial || (r == g && g == b) {
+				value = hue.Colors[min(shade(r, g, b), 31)]
+			}
+
+			offset := dst.PixOffset(x, y)
+			value |= 0x8000 // Keep the pixel opaque
+			dst.Pix[offset] = byte(value)
+			dst.Pix[offset+1] = byte(value >> 8)
+		}
+	}
+	return dst
+}
+
+// applyHuePalette recolors a palette of ARGB1555 colors in place, as ApplyHue does for
+// the pixels of an image. Transparent colors are left unchanged.
+func applyHuePalette(palette []uint16, hue *Hue, partial bool) {
+	for i, value := range palette {
+		if value&0x8000 == 0 {
+			continue
+		}
+
+		r := (value >> 10) & 0x1F
+		g := (value >> 5) & 0x1F
+		b := value & 0x1F
+		if !partial || (r == g && g == b) {
+			palette[i] = hue.Colors[r] | 0x8000
+		}
+	}
+}
+
 // Hue retrieves a specific hue by its index
 func (s *SDK) Hue(index int) (*Hue, error) {
 	// Check for valid index range
@@ -72,69 +158,96 @@ func (s *SDK) Hue(index int) (*Hue, error) {
 		return nil, fmt.Errorf("%w: %d (must be between 0 and 2999)", ErrInvalidHueIndex, index)
 	}
 
+	table, err := s.hueTable()
+	if err != nil {
+		return nil, err
+	}
+
+	if index >= len(table) {
+		return nil, fmt.Errorf("invalid hue data: hue %d is beyond the %d hues of the file", index, len(table))
+	}
+
+	// Return a copy, so that callers can freely edit the hue
+	hue := table[index]
+	return &hue, nil
+}
+
+// Hues returns an iterator over all available hues
+func (s *SDK) Hues() iter.Seq[*Hue] {
+	return func(yield func(*Hue) bool) {
+		table, err := s.hueTable()
+		if err != nil {
+			return
+		}
+
+		for i := range table {
+			hue := table[i]
+			if !yield(&hue) {
+				break
+			}
+		}
+	}
+}
+
+// hueTable holds every hue of hues.mul, indexed by hue
+type hueTable []Hue
+
+// hueTable returns all of the hues, decoding the whole file on first use
+func (s *SDK) hueTable() (hueTable, error) {
+	if table := s.hues.Load(); table != nil {
+		return *table, nil
+	}
+
 	// Load the hues file
 	file, err := s.loadHues()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load hues: %w", err)
 	}
 
-	// With the chunk size set to 708 bytes, we need to calculate which block and which
-	// entry within the block contains our hue
-	blockIndex := index / 8
-	entryIndex := index % 8
-
 	// Each block contains 8 hues and starts with a 4-byte header
 	// Each hue entry is (708 - 4) / 8 = 88 bytes:
 	// - 32 colors * 2 bytes = 64 bytes
 	// - TableStart (2 bytes)
 	// - TableEnd (2 bytes)
 	// - Name (20 bytes)
+	table := make(hueTable, 0, 3000)
+	for block := 0; block < 3000/8; block++ {
+		blockData, err := file.ReadFull(uint32(block))
+		if err != nil || len(blockData) < 4 {
+			break // The file holds fewer hues
+		}
 
-	// Read the entire block
-	blockData, err := file.ReadFull(uint32(blockIndex))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read hue block: %w", err)
-	}
+		for entry := 0; entry < 8; entry++ {
+			offset := 4 + entry*88
+			if offset+88 > len(blockData) {
+				break
+			}
 
-	// Skip the 4-byte header and go to the correct entry
-	entrySize := 88 // bytes
-	entryOffset := 4 + (entryIndex * entrySize)
+			table = append(table, decodeHue(blockData[offset:offset+88], block*8+entry))
+		}
 
-	// Create a reader for the entry
-	if entryOffset+entrySize > len(blockData) {
-		return nil, fmt.Errorf("invalid hue data: block %d too small, expected at least %d bytes but got %d",
-			blockIndex, entryOffset+entrySize, len(blockData))
+		if len(table) < (block+1)*8 {
+			break // Truncated block
+		}
 	}
 
-	reader := bytes.NewReader(blockData[entryOffset:])
-
-	// Create a new hue and read the data
-	hue := &Hue{Index: index}
+	s.hues.CompareAndSwap(nil, &table)
+	return *s.hues.Load(), nil
+}
 
-	// Read the 32 color values
-	for i := 0; i < 32; i++ {
-		if err := binary.Read(reader, binary.LittleEndian, &hue.Colors[i]); err != nil {
-			return nil, fmt.Errorf("failed to read hue color: %w", err)
-		}
+// decodeHue reads the hue from an 88-byte entry of hues.mul
+func decodeHue(data []byte, index int) Hue {
+	hue := Hue{Index: index}
+	for i := range hue.Colors {
+		hue.Colors[i] = binary.LittleEndian.Uint16(data[i*2:])
 	}
 
-	// Read TableStart and TableEnd
-	if err := binary.Read(reader, binary.LittleEndian, &hue.TableStart); err != nil {
-		return nil, fmt.Errorf("failed to read hue TableStart: %w", err)
-	}
-	if err := binary.Read(reader, binary.LittleEndian, &hue.TableEnd); err != nil {
-		return nil, fmt.Errorf("failed to read hue TableEnd: %w", err)
-	}
+	hue.TableStart = binary.LittleEndian.Uint16(data[64:])
+	hue.TableEnd = binary.LittleEndian.Uint16(data[66:])
 
 	// Read the 20-byte name string, null-terminated ASCII
-	nameBytes := make([]byte, 20)
-	if _, err := reader.Read(nameBytes); err != nil {
-		return nil, fmt.Errorf("failed to read hue name: %w", err)
-	}
-
-	// Find the null terminator
-	nullTermPos := bytes.IndexByte(nameBytes, 0)
-	if nullTermPos != -1 {
+	nameBytes := data[68:88]
+	if nullTermPos := bytes.IndexByte(nameBytes, 0); nullTermPos != -1 {
 		nameBytes = nameBytes[:nullTermPos]
 	}
 
@@ -146,22 +259,140 @@ func (s *SDK) Hue(index int) (*Hue, error) {
 	if hue.Name == "" {
 		hue.Name = fmt.Sprintf("Hue %d", index)
 	}
+	return hue
+}
+
+// NearestHue searches every hue for the one whose palette holds the color closest to
+// the target (by squared distance in RGB), so that modern art can be converted back into
+// a hue. The first hue is returned when several match equally well.
+func (s *SDK) NearestHue(c color.Color) (*Hue, error) {
+	if _, err := s.hueTable(); err != nil {
+		return nil, err
+	}
 
-	return hue, nil
+	r, g, b, _ := c.RGBA()
+	target := [3]int{int(r >> 8), int(g >> 8), int(b >> 8)}
+
+	var nearest *Hue
+	best := -1
+	for hue := range s.Hues() {
+		if distance := hueDistance(hue, target); best < 0 || distance < best {
+			nearest, best = hue, distance
+		}
+	}
+
+	if nearest == nil {
+		return nil, fmt.Errorf("%w: no hues to search", ErrInvalidHueIndex)
+	}
+	return nearest, nil
 }
 
-// Hues returns an iterator over all available hues
-func (s *SDK) Hues() iter.Seq[*Hue] {
-	return func(yield func(*Hue) bool) {
-		for i := 0; i < 3000; i++ {
-			hue, err := s.Hue(i)
-			if err != nil {
-				continue // Skip any hues that can't be loaded
-			}
+// hueDistance returns the squared distance between the 8-bit RGB target and the
+// closest color of the hue's palette
+func hueDistance(hue *Hue, target [3]int) int {
+	best := -1
+	for _, value := range hue.Colors {
+		r, g, b, _ := bitmap.ARGB1555Color(value | 0x8000).RGBA()
+		dr, dg, db := int(r>>8)-target[0], int(g>>8)-target[1], int(b>>8)-target[2]
+		if distance := dr*dr + dg*dg + db*db; best < 0 || distance < best {
+			best = distance
+		}
+	}
+	return best
+}
 
-			if !yield(hue) {
-				break
+// HuesToJSON exports the hues as an indented JSON array, so that hue sets can be kept
+// under version control and read back with HuesFromJSON.
+func HuesToJSON(hues []*Hue) ([]byte, error) {
+	data, err := json.MarshalIndent(hues, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hues: %w", err)
+	}
+	return data, nil
+}
+
+// HuesFromJSON parses a JSON array of hues, as exported by HuesToJSON, checking that
+// their indices are within range so they can be given to WriteHues.
+func HuesFromJSON(data []byte) ([]*Hue, error) {
+	var hues []*Hue
+	if err := json.Unmarshal(data, &hues); err != nil {
+		return nil, fmt.Errorf("failed to decode hues: %w", err)
+	}
+
+	for _, hue := range hues {
+		if hue == nil || hue.Index < 0 || hue.Index >= 3000 {
+			return nil, fmt.Errorf("%w: hues must have an index between 0 and 2999", ErrInvalidHueIndex)
+		}
+	}
+	return hues, nil
+}
+
+// WriteHues encodes the hues, keyed by their Index, into the hues.mul format: blocks of
+// 8 hues, each block starting with a 4-byte header. Enough blocks are written to hold
+// the hue with the highest index, missing hues being left empty. Default names (as
+// given by Hue to unnamed hues) are written as empty names.
+func WriteHues(dst io.Writer, hues []*Hue) error {
+	count := 0
+	byIndex := make(map[int]*Hue, len(hues))
+	for _, hue := range hues {
+		switch {
+		case hue == nil:
+			return fmt.Errorf("%w: hue is nil", ErrInvalidHueIndex)
+		case hue.Index < 0 || hue.Index >= 3000:
+			return fmt.Errorf("%w: %d (must be between 0 and 2999)", ErrInvalidHueIndex, hue.Index)
+		case byIndex[hue.Index] != nil:
+			return fmt.Errorf("%w: duplicate hue %d", ErrInvalidHueIndex, hue.Index)
+		}
+
+		byIndex[hue.Index] = hue
+		count = max(count, hue.Index+1)
+	}
+
+	block := make([]byte, 708)
+	for start := 0; start < count; start += 8 {
+		clear(block)
+		for i := 0; i < 8; i++ {
+			if hue := byIndex[start+i]; hue != nil {
+				encodeHue(block[4+i*88:4+(i+1)*88], hue)
 			}
 		}
+
+		if _, err := dst.Write(block); err != nil {
+			return fmt.Errorf("failed to write hue block %d: %w", start/8, err)
+		}
+	}
+	return nil
+}
+
+// WriteHues writes every hue of the SDK into the hues.mul format, replacing the hues
+// with the same Index by the given ones, so that edited hues can be persisted.
+func (s *SDK) WriteHues(dst io.Writer, hues ...*Hue) error {
+	replaced := make(map[int]bool, len(hues))
+	for _, hue := range hues {
+		if hue != nil {
+			replaced[hue.Index] = true // Nil hues are reported by WriteHues
+		}
+	}
+
+	for hue := range s.Hues() {
+		if !replaced[hue.Index] {
+			hues = append(hues, hue)
+		}
+	}
+
+	return WriteHues(dst, hues)
+}
+
+// encodeHue writes the hue into an 88-byte entry of hues.mul: the 32 colors, the start
+// and end of the table and the name, as a null-terminated string of up to 20 bytes
+func encodeHue(dst []byte, hue *Hue) {
+	for i, c := range hue.Colors {
+		binary.LittleEndian.PutUint16(dst[i*2:], c)
+	}
+
+	binary.LittleEndian.PutUint16(dst[64:], hue.TableStart)
+	binary.LittleEndian.PutUint16(dst[66:], hue.TableEnd)
+	if hue.Name != fmt.Sprintf("Hue %d", hue.Index) {
+		copy(dst[68:88], hue.Name)
 	}
 }
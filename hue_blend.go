@@ -0,0 +1,70 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image/color"
+	"math"
+)
+
+// BlendHues returns a new hue whose colors are interpolated between the two hues, where
+// t of 0 gives the colors of a and t of 1 the colors of b. The blended hue takes the
+// index and name of a, which should be changed before writing it with WriteHues.
+func BlendHues(a, b *Hue, t float64) *Hue {
+	out := &Hue{Index: a.Index, Name: a.Name}
+	for i := range out.Colors {
+		out.Colors[i] = mixColor(a.Colors[i], b.Colors[i], t)
+	}
+
+	out.TableStart = mixColor(a.TableStart, b.TableStart, t)
+	out.TableEnd = mixColor(a.TableEnd, b.TableEnd, t)
+	return out
+}
+
+// Lighten returns a copy of the hue with its colors moved towards white by the amount,
+// between 0 (unchanged) and 1 (white)
+func (h *Hue) Lighten(amount float64) *Hue {
+	return BlendHues(h, &Hue{Colors: solidColors(0x7FFF), TableStart: 0x7FFF, TableEnd: 0x7FFF}, amount)
+}
+
+// Darken returns a copy of the hue with its colors moved towards black by the amount,
+// between 0 (unchanged) and 1 (black)
+func (h *Hue) Darken(amount float64) *Hue {
+	return BlendHues(h, &Hue{}, amount)
+}
+
+// HueGradient generates a hue whose 32 colors ramp from one color to the other, such as
+// from a dark to a light shade, as the client expects of the colors of a hue
+func HueGradient(from, to color.Color) *Hue {
+	start, _ := encodeARGB1555(from)
+	end, _ := encodeARGB1555(to)
+
+	out := &Hue{TableStart: start, TableEnd: end}
+	for i := range out.Colors {
+		out.Colors[i] = mixColor(start, end, float64(i)/float64(len(out.Colors)-1))
+	}
+	return out
+}
+
+// solidColors returns a palette of 32 times the same color
+func solidColors(value uint16) (colors [32]uint16) {
+	for i := range colors {
+		colors[i] = value
+	}
+	return
+}
+
+// mixColor interpolates each 5-bit channel of two 15-bit colors, t being clamped
+// between 0 (the first color) and 1 (the second color)
+func mixColor(a, b uint16, t float64) uint16 {
+	t = math.Max(0, math.Min(1, t))
+
+	var out uint16
+	for _, shift := range []uint16{10, 5, 0} {
+		ca := float64((a >> shift) & 0x1F)
+		cb := float64((b >> shift) & 0x1F)
+		out |= uint16(math.Round(ca+(cb-ca)*t)) << shift
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixColor(t *testing.T) {
+	assert.Equal(t, uint16(0x7C00), mixColor(0x7C00, 0x001F, 0))
+	assert.Equal(t, uint16(0x001F), mixColor(0x7C00, 0x001F, 1))
+	assert.Equal(t, uint16(16<<10|16), mixColor(0x7C00, 0x001F, 0.5))
+	assert.Equal(t, uint16(0x001F), mixColor(0x7C00, 0x001F, 2), "clamped")
+}
+
+func TestBlendHues(t *testing.T) {
+	red := &Hue{Index: 1, Name: "Red", TableStart: 0x7C00, TableEnd: 0x7C00}
+	blue := &Hue{Index: 2, Name: "Blue", TableStart: 0x001F, TableEnd: 0x001F}
+	for i := range red.Colors {
+		red.Colors[i] = uint16(i) << 10
+		blue.Colors[i] = uint16(i)
+	}
+
+	purple := BlendHues(red, blue, 0.5)
+	assert.Equal(t, 1, purple.Index)
+	assert.Equal(t, "Red", purple.Name)
+	assert.Equal(t, uint16(16<<10|16), purple.Colors[31])
+	assert.Equal(t, uint16(16<<10|16), purple.TableStart)
+	assert.Equal(t, uint16(0), purple.Colors[0])
+
+	// Lightening and darkening leave the original hue unchanged
+	assert.Equal(t, uint16(0x7FFF), red.Lighten(1).Colors[0])
+	assert.Equal(t, uint16(31<<10|16<<5|16), red.Lighten(0.5).Colors[31])
+	assert.Equal(t, uint16(16<<10), red.Darken(0.5).Colors[31])
+	assert.Equal(t, uint16(31<<10), red.Colors[31])
+}
+
+func TestHueGradient(t *testing.T) {
+	hue := HueGradient(color.Black, color.RGBA{R: 255, G: 255, A: 255})
+	assert.Equal(t, uint16(0), hue.Colors[0])
+	assert.Equal(t, uint16(0x7FE0), hue.Colors[31])
+	assert.Equal(t, uint16(0), hue.TableStart)
+	assert.Equal(t, uint16(0x7FE0), hue.TableEnd)
+	for i := 1; i < len(hue.Colors); i++ {
+		assert.GreaterOrEqual(t, hue.Colors[i], hue.Colors[i-1])
+	}
+
+	// The generated hue can be written
+	hue.Index, hue.Name = 5, "Yellow Ramp"
+	var buffer bytes.Buffer
+	require.NoError(t, WriteHues(&buffer, []*Hue{hue}))
+}
@@ -4,6 +4,15 @@
 package ultima
 
 import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
@@ -92,6 +101,94 @@ func TestHue_Image(t *testing.T) {
 	assert.Equal(t, bitmap.ARGB1555Color(0x801F), blueColor) // 0x001F + 0x8000 (alpha bit)
 }
 
+func TestApplyHue(t *testing.T) {
+	hue := &Hue{}
+	for i := range hue.Colors {
+		hue.Colors[i] = uint16(i) // Shades of blue
+	}
+
+	img := bitmap.NewARGB1555(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, bitmap.ARGB1555Color(0x8000|10<<10|10<<5|10)) // Gray
+	img.Set(1, 0, bitmap.ARGB1555Color(0x8000|20<<10|5<<5|1))   // Colored
+
+	t.Run("Full", func(t *testing.T) {
+		out := ApplyHue(img, hue, false)
+		assert.Equal(t, bitmap.ARGB1555Color(0x8000|10), out.At(0, 0))
+		assert.Equal(t, bitmap.ARGB1555Color(0x8000|20), out.At(1, 0))
+		assert.Equal(t, bitmap.ARGB1555Color(0), out.At(2, 0))
+	})
+
+	t.Run("Partial", func(t *testing.T) {
+		out := ApplyHue(img, hue, true)
+		assert.Equal(t, bitmap.ARGB1555Color(0x8000|10), out.At(0, 0))
+		assert.Equal(t, img.At(1, 0), out.At(1, 0))
+		assert.Equal(t, bitmap.ARGB1555Color(0), out.At(2, 0))
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		assert.Nil(t, ApplyHue(nil, hue, false))
+		assert.Nil(t, ApplyHue(img, nil, false))
+	})
+
+	t.Run("Luminance", func(t *testing.T) {
+		out := ApplyHueLuminance(img, hue, false)
+		assert.Equal(t, bitmap.ARGB1555Color(0x8000|10), out.At(0, 0))
+		assert.Equal(t, bitmap.ARGB1555Color(0x8000|9), out.At(1, 0)) // 20*.299 + 5*.587 + 1*.114
+		assert.Equal(t, bitmap.ARGB1555Color(0), out.At(2, 0))
+		assert.Equal(t, img.At(1, 0), ApplyHueLuminance(img, hue, true).At(1, 0))
+		assert.Nil(t, ApplyHueLuminance(nil, hue, false))
+	})
+}
+
+func TestApplyHuePalette(t *testing.T) {
+	hue := &Hue{}
+	for i := range hue.Colors {
+		hue.Colors[i] = uint16(i) // Shades of blue
+	}
+
+	palette := []uint16{
+		0,                            // Transparent
+		0x8000 | 10<<10 | 10<<5 | 10, // Gray
+		0x8000 | 20<<10 | 5<<5 | 1,   // Colored
+	}
+
+	full := slices.Clone(palette)
+	applyHuePalette(full, hue, false)
+	assert.Equal(t, []uint16{0, 0x8000 | 10, 0x8000 | 20}, full)
+
+	partial := slices.Clone(palette)
+	applyHuePalette(partial, hue, true)
+	assert.Equal(t, []uint16{0, 0x8000 | 10, palette[2]}, partial)
+}
+
+// writeTestHues writes a hues.mul file where each of the given hues turns every color
+// into a single one
+func writeTestHues(t *testing.T, dir string, hues map[int]uint16) {
+	size := 708 * (slices.Max(slices.Collect(maps.Keys(hues)))/8 + 1)
+	data := make([]byte, size)
+	for index, color := range hues {
+		offset := 708*(index/8) + 4 + 88*(index%8)
+		for i := 0; i < 32; i++ {
+			binary.LittleEndian.PutUint16(data[offset+i*2:], color)
+		}
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hues.mul"), data, 0644))
+}
+
+func TestSDK_ItemWithHue(t *testing.T) {
+	runWith(t, func(sdk *SDK) {
+		item, err := sdk.ItemWithHue(0x0E3D, 33, false)
+		require.NoError(t, err)
+		require.NotNil(t, item.Image)
+
+		original, err := sdk.Item(0x0E3D)
+		require.NoError(t, err)
+		assert.Equal(t, original.Image.Bounds(), item.Image.Bounds())
+		assert.NotEqual(t, original.Image, item.Image)
+	})
+}
+
 func TestSDK_HueAt(t *testing.T) {
 	runWith(t, func(sdk *SDK) {
 		hue, err := sdk.Hue(1337)
@@ -211,3 +308,157 @@ func TestHueImageRendering(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteHues(t *testing.T) {
+	red := &Hue{Index: 2, Name: "Bright Red", TableStart: 0x7C00, TableEnd: 0x7C00}
+	for i := range red.Colors {
+		red.Colors[i] = uint16(i) << 10
+	}
+
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteHues(&buffer, []*Hue{{Index: 9, Name: "Hue 9"}, red}))
+	assert.Equal(t, 708*2, buffer.Len(), "two blocks of 8 hues")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hues.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	hue, err := sdk.Hue(2)
+	require.NoError(t, err)
+	assert.Equal(t, red, hue)
+
+	hue, err = sdk.Hue(9)
+	require.NoError(t, err)
+	assert.Equal(t, "Hue 9", hue.Name)
+
+	// Replace a hue of the SDK, keeping the others
+	blue := &Hue{Index: 9, Name: "Blue", TableStart: 0x1F}
+	buffer.Reset()
+	require.NoError(t, sdk.WriteHues(&buffer, blue))
+	require.NoError(t, sdk.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hues.mul"), buffer.Bytes(), 0644))
+
+	sdk, err = Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	hue, err = sdk.Hue(9)
+	require.NoError(t, err)
+	assert.Equal(t, blue, hue)
+	hue, err = sdk.Hue(2)
+	require.NoError(t, err)
+	assert.Equal(t, red, hue)
+
+	assert.Error(t, WriteHues(&buffer, []*Hue{{Index: 3000}}))
+	assert.Error(t, WriteHues(&buffer, []*Hue{{Index: 1}, {Index: 1}}))
+	assert.ErrorIs(t, WriteHues(&buffer, []*Hue{{Index: 1}, nil}), ErrInvalidHueIndex)
+	assert.ErrorIs(t, sdk.WriteHues(&buffer, nil), ErrInvalidHueIndex)
+}
+
+func TestSDK_NearestHue(t *testing.T) {
+	red := &Hue{Index: 1, Name: "Red"}
+	blue := &Hue{Index: 2, Name: "Blue"}
+	for i := range red.Colors {
+		red.Colors[i] = uint16(i) << 10
+		blue.Colors[i] = uint16(i)
+	}
+
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteHues(&buffer, []*Hue{red, blue}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hues.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	hue, err := sdk.NearestHue(color.RGBA{R: 120, G: 10, B: 0, A: 255})
+	require.NoError(t, err)
+	assert.Equal(t, "Red", hue.Name)
+
+	hue, err = sdk.NearestHue(color.RGBA{R: 0, G: 0, B: 200, A: 255})
+	require.NoError(t, err)
+	assert.Equal(t, "Blue", hue.Name)
+
+	// Black is found in every palette, the first one being returned
+	hue, err = sdk.NearestHue(color.Black)
+	require.NoError(t, err)
+	assert.Equal(t, 0, hue.Index)
+}
+
+func TestSDK_HueTable(t *testing.T) {
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteHues(&buffer, []*Hue{{Index: 3, Name: "Green", TableStart: 0x03E0}}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hues.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	// The table is decoded once, every call returning its own copy
+	hue, err := sdk.Hue(3)
+	require.NoError(t, err)
+	assert.Equal(t, "Green", hue.Name)
+	hue.Name = "Edited"
+
+	hue, err = sdk.Hue(3)
+	require.NoError(t, err)
+	assert.Equal(t, "Green", hue.Name)
+	assert.Equal(t, 8, len(slices.Collect(sdk.Hues())))
+
+	// Hues beyond the end of the file do not exist
+	_, err = sdk.Hue(8)
+	assert.Error(t, err)
+}
+
+func TestHuesJSON(t *testing.T) {
+	red := &Hue{Index: 2, Name: "Bright Red", TableStart: 0x7C00, TableEnd: 0x7C00}
+	for i := range red.Colors {
+		red.Colors[i] = uint16(i) << 10
+	}
+
+	hues := []*Hue{red, {Index: 9, Name: "Hue 9"}}
+	data, err := HuesToJSON(hues)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"name": "Bright Red"`)
+
+	// The decoded hues are the same, and can be written back
+	decoded, err := HuesFromJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, hues, decoded)
+
+	var expect, actual bytes.Buffer
+	require.NoError(t, WriteHues(&expect, hues))
+	require.NoError(t, WriteHues(&actual, decoded))
+	assert.Equal(t, expect.Bytes(), actual.Bytes())
+
+	_, err = HuesFromJSON([]byte(`[{"index": 3000}]`))
+	assert.Error(t, err)
+	_, err = HuesFromJSON([]byte(`[null]`))
+	assert.Error(t, err)
+	_, err = HuesFromJSON([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestHue_Palette(t *testing.T) {
+	hue := &Hue{}
+	for i := range hue.Colors {
+		hue.Colors[i] = uint16(i) << 5 // Shades of green
+	}
+
+	palette := hue.Palette()
+	require.Len(t, palette, 33)
+	assert.Equal(t, bitmap.ARGB1555Color(0x8000), palette[0], "black is opaque")
+	assert.Equal(t, bitmap.ARGB1555Color(0x8000|31<<5), palette[31])
+	_, _, _, a := palette[32].RGBA()
+	assert.Zero(t, a)
+
+	// Recolored assets can be quantized through the palette
+	img := ApplyHue(image.NewNRGBA(image.Rect(0, 0, 2, 1)), hue, false)
+	img.Set(0, 0, bitmap.ARGB1555Color(0x8000|12<<5))
+	paletted := image.NewPaletted(img.Bounds(), palette)
+	draw.Draw(paletted, img.Bounds(), img, image.Point{}, draw.Src)
+	assert.Equal(t, uint8(12), paletted.ColorIndexAt(0, 0))
+	assert.Equal(t, uint8(32), paletted.ColorIndexAt(1, 0))
+}
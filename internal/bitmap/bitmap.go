@@ -158,3 +158,35 @@ func (p *ARGB1555) Opaque() bool {
 	}
 	return true
 }
+
+// NRGBA converts the image into an image.NRGBA of the same bounds, decoding the pixels
+// directly from the buffer rather than through the generic color model. Pixels of
+// color 0 are transparent, as in RGBA.
+func (p *ARGB1555) NRGBA() *image.NRGBA {
+	dst := image.NewNRGBA(p.Rect)
+	for y := 0; y < p.Rect.Dy(); y++ {
+		src := p.Pix[y*p.Stride : y*p.Stride+p.Rect.Dx()*2]
+		out := dst.Pix[y*dst.Stride : y*dst.Stride+p.Rect.Dx()*4]
+		for x := 0; x < len(src); x += 2 {
+			c := uint16(src[x]) | uint16(src[x+1])<<8
+			if c == 0 {
+				continue // Transparent
+			}
+
+			i := x * 2
+			out[i+0] = expand5[(c>>10)&0x1F]
+			out[i+1] = expand5[(c>>5)&0x1F]
+			out[i+2] = expand5[c&0x1F]
+			out[i+3] = 0xFF
+		}
+	}
+	return dst
+}
+
+// expand5 maps 5-bit channels to 8 bits, rounding as RGBA does
+var expand5 = func() (out [32]uint8) {
+	for i := range out {
+		out[i] = uint8(i * 255 / 31)
+	}
+	return
+}()
@@ -6,6 +6,7 @@ package bitmap
 import (
 	"image"
 	"image/color"
+	"image/draw"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -221,3 +222,24 @@ func TestARGB1555Image_Additional(t *testing.T) {
 	assert.Equal(t, ARGB1555Color(0), img.At(-1, -1))
 	assert.Equal(t, ARGB1555Color(0), img.At(100, 100))
 }
+
+func TestARGB1555_NRGBA(t *testing.T) {
+	img := NewARGB1555(image.Rect(2, 3, 66, 35))
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			img.Set(x, y, ARGB1555Color(uint16(x*1021+y*4093)))
+		}
+	}
+	img.Pix[0], img.Pix[1] = 0, 0
+
+	// Matches the generic conversion through the color model, including transparency
+	expect := image.NewNRGBA(img.Rect)
+	draw.Draw(expect, expect.Rect, img, img.Rect.Min, draw.Src)
+	assert.Equal(t, expect, img.NRGBA())
+	assert.Equal(t, color.NRGBA{}, img.NRGBA().NRGBAAt(2, 3))
+
+	sub := img.SubImage(image.Rect(10, 10, 20, 20)).(*ARGB1555)
+	expect = image.NewNRGBA(sub.Rect)
+	draw.Draw(expect, expect.Rect, sub, sub.Rect.Min, draw.Src)
+	assert.Equal(t, expect, sub.NRGBA())
+}
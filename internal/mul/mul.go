@@ -17,6 +17,7 @@ import (
 
 // Entry3D represents an entry in MUL index files
 type Entry3D struct {
+	key     uint32 // Key of the entry, as given when it was added
 	offset  uint32 // Offset where the entry data begins
 	length  uint32 // Size of the entry data
 	extra   uint32 // Extra data (can be split into Extra1/Extra2)
@@ -31,6 +32,7 @@ type Reader struct {
 	lookup    *intmap.Map // Lookup table for entry offsets
 	entrySize int         // Size of each entry in the index file
 	closed    bool        // Flag to track if reader is closed
+	err       error       // Error of the options, such as a failed decode
 }
 
 // Errors
@@ -66,6 +68,11 @@ func OpenOne(filename string, options ...Option) (*Reader, error) {
 		option(r)
 	}
 
+	if r.err != nil {
+		r.Close()
+		return nil, r.err
+	}
+
 	// If no index file is provided, we need to create a default entry
 	if len(r.entries) == 0 {
 		buffer := make([]byte, info.Size())
@@ -105,6 +112,11 @@ func Open(mulFilename, idxFilename string, options ...Option) (*Reader, error) {
 		option(r)
 	}
 
+	if r.err != nil {
+		r.Close()
+		return nil, r.err
+	}
+
 	// Cache index entries
 	if err := r.loadIndex(); err != nil {
 		r.Close() // Clean up both file handles if caching fails
@@ -153,6 +165,7 @@ func (r *Reader) loadIndex() error {
 func (r *Reader) add(id, offset, length, extra uint32, value []byte) {
 	index := uint32(len(r.entries))
 	r.entries = append(r.entries, Entry3D{
+		key:     id,
 		offset:  offset,
 		length:  length,
 		extra:   extra,
@@ -198,6 +211,29 @@ func (r *Reader) entryAt(key uint32) (*Entry3D, error) {
 	}
 }
 
+// Extent returns the offset and length of an entry in the data file, as recorded
+// by the index file. It reports false if there is no index file or the entry is invalid.
+func (r *Reader) Extent(key uint32) (offset, length uint32, ok bool) {
+	entry, err := r.entryAt(key)
+	switch {
+	case err != nil || r.index == nil:
+		return 0, 0, false
+	case entry.offset == 0xFFFFFFFF || entry.length == 0:
+		return 0, 0, false
+	}
+
+	return entry.offset, entry.length, true
+}
+
+// Size returns the size of the data file in bytes
+func (r *Reader) Size() int {
+	if r.closed || r.file == nil {
+		return 0
+	}
+
+	return r.file.Len()
+}
+
 // Entries returns an iterator over available entries
 func (r *Reader) Entries() iter.Seq[uint32] {
 	return func(yield func(uint32) bool) {
@@ -207,12 +243,12 @@ func (r *Reader) Entries() iter.Seq[uint32] {
 
 		// Return entries from cache if available
 		if r.entries != nil {
-			for i, entry := range r.entries {
+			for _, entry := range r.entries {
 				if entry.offset == 0xFFFFFFFF || entry.length == 0 {
 					continue // skip invalid entries
 				}
 
-				if !yield(uint32(i)) {
+				if !yield(entry.key) {
 					return
 				}
 			}
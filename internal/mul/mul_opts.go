@@ -21,11 +21,12 @@ func WithEntrySize(size int) Option {
 	}
 }
 
-// WithDecode sets a custom parser function for the reader
+// WithDecode sets a custom parser function for the reader, whose error is returned
+// when the reader is opened
 func WithDecode(fn func(file *mmap.File, add AddFn) error) Option {
 	return func(r *Reader) {
-		if err := fn(r.file, r.add); err != nil {
-			panic(fmt.Sprintf("failed to parse entries: %v", err))
+		if err := fn(r.file, r.add); err != nil && r.err == nil {
+			r.err = fmt.Errorf("failed to parse entries: %w", err)
 		}
 	}
 }
@@ -0,0 +1,76 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package mul
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Writer writes entries into a MUL data file along with its index file, where each
+// index entry is a 12-byte (offset, length, extra) triplet.
+type Writer struct {
+	data   io.Writer // Writer for the MUL file
+	index  io.Writer // Writer for the index file
+	offset uint32    // Offset of the next entry in the MUL file
+	count  uint32    // Number of index entries written so far
+	buffer [12]byte  // Scratch buffer for index entries
+}
+
+// NewWriter creates a new MUL writer over the data and index writers
+func NewWriter(data, index io.Writer) *Writer {
+	return &Writer{
+		data:  data,
+		index: index,
+	}
+}
+
+// Write appends an entry with the given key. Keys must be written in ascending order
+// and any skipped key is recorded in the index as an empty entry.
+func (w *Writer) Write(key uint32, value []byte, extra uint32) error {
+	if key < w.count {
+		return fmt.Errorf("%w: key %d written out of order (next key is %d)", ErrInvalidIndex, key, w.count)
+	}
+
+	// Fill the gap with empty entries
+	if err := w.Pad(key); err != nil {
+		return err
+	}
+
+	if len(value) == 0 {
+		return w.writeIndex(0xFFFFFFFF, 0, 0)
+	}
+
+	if _, err := w.data.Write(value); err != nil {
+		return fmt.Errorf("failed to write entry %d: %w", key, err)
+	}
+
+	offset := w.offset
+	w.offset += uint32(len(value))
+	return w.writeIndex(offset, uint32(len(value)), extra)
+}
+
+// Pad fills the index with empty entries until it holds the given number of entries
+func (w *Writer) Pad(count uint32) error {
+	for w.count < count {
+		if err := w.writeIndex(0xFFFFFFFF, 0, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIndex writes a single index entry
+func (w *Writer) writeIndex(offset, length, extra uint32) error {
+	binary.LittleEndian.PutUint32(w.buffer[0:4], offset)
+	binary.LittleEndian.PutUint32(w.buffer[4:8], length)
+	binary.LittleEndian.PutUint32(w.buffer[8:12], extra)
+	if _, err := w.index.Write(w.buffer[:]); err != nil {
+		return fmt.Errorf("failed to write index entry %d: %w", w.count, err)
+	}
+
+	w.count++
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package mul
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+	mulPath := filepath.Join(dir, "test.mul")
+	idxPath := filepath.Join(dir, "test.idx")
+
+	data, err := os.Create(mulPath)
+	require.NoError(t, err)
+	index, err := os.Create(idxPath)
+	require.NoError(t, err)
+
+	w := NewWriter(data, index)
+	assert.NoError(t, w.Write(0, []byte("hello"), 1))
+	assert.NoError(t, w.Write(3, []byte("world!"), 2))
+	assert.NoError(t, w.Write(4, nil, 0))
+	assert.Error(t, w.Write(2, []byte("late"), 0))
+	assert.NoError(t, w.Pad(8))
+	require.NoError(t, data.Close())
+	require.NoError(t, index.Close())
+
+	reader, err := Open(mulPath, idxPath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, []uint32{0, 3}, slices.Collect(reader.Entries()))
+	assert.Len(t, reader.entries, 8)
+
+	entry, err := reader.Entry(3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), entry.Extra())
+
+	buffer := make([]byte, entry.Len())
+	_, err = entry.ReadAt(buffer, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "world!", string(buffer))
+
+	empty, err := reader.Entry(1)
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+}
@@ -94,6 +94,14 @@ func WithExtra() Option {
 	}
 }
 
+// WithNames sets the function naming the entries of UOP files, for files whose entries
+// are not named after their index
+func WithNames(fn func(index int) string) Option {
+	return func(f *File) {
+		f.uopOpts = append(f.uopOpts, uop.WithNames(fn))
+	}
+}
+
 // WithChunks configures the reader to handle files with fixed-size chunks
 // This is useful for files like hues.mul where data is stored in fixed-size blocks
 func WithChunks(chunkSize int) Option {
@@ -117,8 +125,19 @@ func WithStrict() Option {
 }
 
 // New creates a new File instance with automatic format detection
-// It takes a base path, file names to check for, and options
+// It takes a base path, file names to check for, and options. It panics if the file
+// cannot be opened, see Open for a version returning the error.
 func New(basePath string, fileNames []string, length int, options ...Option) *File {
+	f, err := Open(basePath, fileNames, length, options...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Open creates a new File instance with automatic format detection, returning an error
+// if none of the files can be found or opened, such as an empty or truncated index
+func Open(basePath string, fileNames []string, length int, options ...Option) (*File, error) {
 	f := &File{
 		length: length,
 		base:   basePath,
@@ -134,9 +153,18 @@ func New(basePath string, fileNames []string, length int, options ...Option) *Fi
 
 	// Open the file
 	if err := f.open(); err != nil {
-		panic(err)
+		return nil, err
 	}
 
+	return f, nil
+}
+
+// FromReader returns a File reading its entries from the reader, for data decoded from
+// another layout, such as the sectors of the Enhanced Client's facets exposed as the
+// blocks of the classic map files. The path is only used to identify the file.
+func FromReader(path string, reader Reader) *File {
+	f := &File{reader: reader, path: path, base: filepath.Dir(path)}
+	f.state.Store(stateReady)
 	return f
 }
 
@@ -155,9 +183,9 @@ func detectFormat(f *File, basePath string, fileNames []string) {
 		}
 	}
 
-	// 1. Special case for cliloc files (cliloc.*)
+	// 1. Special case for standalone files (cliloc.*, *.def, *.txt)
 	for _, fileName := range fileNames {
-		if strings.HasPrefix(fileName, "cliloc.") {
+		if strings.HasPrefix(fileName, "cliloc.") || strings.HasSuffix(fileName, ".def") || strings.HasSuffix(fileName, ".txt") {
 			if path, ok := f.fileExists(fileName); ok {
 				useOne(path)
 				return
@@ -188,7 +216,7 @@ func detectFormat(f *File, basePath string, fileNames []string) {
 	for _, fileName := range fileNames {
 		if path, ok := f.fileExists(fileName); ok {
 			switch {
-			case strings.HasPrefix(fileName, "staidx") || strings.HasSuffix(fileName, "idx.mul") || strings.HasSuffix(fileName, ".idx"):
+			case strings.HasPrefix(fileName, "staidx") || strings.HasPrefix(fileName, "stadifi") || strings.HasSuffix(fileName, "idx.mul") || strings.HasSuffix(fileName, ".idx"):
 				idxPath = path
 			case strings.HasSuffix(fileName, ".mul") && !strings.HasSuffix(fileName, "idx.mul"):
 				mulPath = path
@@ -269,6 +297,25 @@ func (f *File) ReadFull(key uint32) ([]byte, error) {
 	return data, nil
 }
 
+// Extent returns the offset and length of an entry within the data file of an
+// indexed MUL file. It reports false for any other format or an invalid entry.
+func (f *File) Extent(key uint32) (offset, length uint32, ok bool) {
+	if reader, isMul := f.reader.(*mul.Reader); isMul {
+		return reader.Extent(key)
+	}
+
+	return 0, 0, false
+}
+
+// Size returns the size of the data file of a MUL file in bytes, or zero for any other format
+func (f *File) Size() int {
+	if reader, isMul := f.reader.(*mul.Reader); isMul {
+		return reader.Size()
+	}
+
+	return 0
+}
+
 // Entries returns a sequence of entry indices
 func (f *File) Entries() iter.Seq[uint32] {
 	return f.reader.Entries()
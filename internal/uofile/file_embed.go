@@ -12,6 +12,9 @@ import (
 //go:embed file_anim.json
 var fileAnimJSON []byte
 
+//go:embed file_multi.json
+var fileMultiJSON []byte
+
 // AnimationEntry represents a single animation entry from file_anim.json
 type AnimationEntry struct {
 	Name string `json:"name"`
@@ -24,7 +27,20 @@ type AnimationList struct {
 	Mobs []AnimationEntry `json:"Mobs"`
 }
 
+// MultiEntry represents a single multi entry from file_multi.json
+type MultiEntry struct {
+	Name string `json:"name"`
+	ID   int    `json:"id"`
+	Type int    `json:"type"`
+}
+
+// MultiList is the root structure for file_multi.json
+type MultiList struct {
+	Multis []MultiEntry `json:"Multis"`
+}
+
 var animNameByBody map[int]string
+var multiNameByID map[int]string
 
 func init() {
 	var animList AnimationList
@@ -35,9 +51,23 @@ func init() {
 	for _, mob := range animList.Mobs {
 		animNameByBody[mob.Body] = mob.Name
 	}
+
+	var multiList MultiList
+	if err := json.Unmarshal(fileMultiJSON, &multiList); err != nil {
+		panic(fmt.Errorf("failed to parse embedded file_multi.json: %w", err))
+	}
+	multiNameByID = make(map[int]string, len(multiList.Multis))
+	for _, multi := range multiList.Multis {
+		multiNameByID[multi.ID] = multi.Name
+	}
 }
 
 // AnimationNameByBody returns the animation name for a body ID, or "" if not found.
 func AnimationNameByBody(body int) string {
 	return animNameByBody[body]
 }
+
+// MultiNameByID returns the multi name for a multi ID, or "" if not found.
+func MultiNameByID(id int) string {
+	return multiNameByID[id]
+}
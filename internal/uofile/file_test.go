@@ -72,3 +72,8 @@ func TestAnimationNameByBody(t *testing.T) {
 	assert.Equal(t, "ogres_ogre (1)", AnimationNameByBody(1), "Body 1 should return correct name")
 	assert.Equal(t, "", AnimationNameByBody(99999), "Unknown body should return empty string")
 }
+
+func TestMultiNameByID(t *testing.T) {
+	assert.Equal(t, "Small Boat [north]", MultiNameByID(0), "Multi 0 should return correct name")
+	assert.Equal(t, "", MultiNameByID(99999), "Unknown multi should return empty string")
+}
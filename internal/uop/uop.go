@@ -42,14 +42,15 @@ type Entry6D struct {
 
 // Reader implements the interface for reading UOP files
 type Reader struct {
-	file     *mmap.File  // File handle
-	info     os.FileInfo // File information
-	entries  []Entry6D   // Map of entries by logical index or hash
-	length   int         // Length of the file
-	ext      string      // File extension
-	closed   bool        // Flag to track if reader is closed
-	hasextra bool        // Flag to indicate if extra data is present
-	strict   bool        // Flag to indicate if the reader should skip not found hashes
+	file     *mmap.File       // File handle
+	info     os.FileInfo      // File information
+	entries  []Entry6D        // Map of entries by logical index or hash
+	length   int              // Length of the file
+	ext      string           // File extension
+	closed   bool             // Flag to track if reader is closed
+	hasextra bool             // Flag to indicate if extra data is present
+	strict   bool             // Flag to indicate if the reader should skip not found hashes
+	names    func(int) string // Optional function returning the name of an entry
 }
 
 // Open creates a new UOP file reader
@@ -119,7 +120,13 @@ func (r *Reader) parseFile() error {
 	// Build the pattern name
 	hashes := make(map[uint64]int, r.length)
 	for i := 0; i < r.length; i++ {
-		name := fmt.Sprintf("build/%s/%08d%s", uopPattern, i, r.ext)
+		var name string
+		if r.names != nil {
+			name = r.names(i)
+		} else {
+			name = fmt.Sprintf("build/%s/%08d%s", uopPattern, i, r.ext)
+		}
+
 		hash := hashFileName(name)
 		hashes[hash] = i
 	}
@@ -244,7 +251,7 @@ func (r *Reader) Close() error {
 	return r.file.Close()
 }
 
-// Entry returns an entry reader
+// Entry returns an entry reader, compressed entries being decompressed in memory
 func (r *Reader) Entry(key uint32) (Entry, error) {
 	entry, err := r.entryAt(key)
 	switch {
@@ -258,6 +265,21 @@ func (r *Reader) Entry(key uint32) (Entry, error) {
 		return nil, nil
 	}
 
+	// Compressed entries are decompressed as a whole, as they cannot be read partially
+	if typ := CompressionType(entry.typ); typ == CompressionZlib || typ == CompressionMythic {
+		data := make([]byte, entry.length)
+		if _, err := r.file.ReadAt(data, int64(entry.offset)); err != nil {
+			return nil, fmt.Errorf("failed to read entry %d: %w", key, err)
+		}
+
+		data, err := decode(data, typ)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress entry %d: %w", key, err)
+		}
+
+		return decoded{data: data, extra: entry.extra}, nil
+	}
+
 	return reader{
 		reader: r.file,
 		entry:  entry,
@@ -298,3 +320,28 @@ func (r reader) Extra() uint64 {
 func (r reader) ReadAt(p []byte, off int64) (n int, err error) {
 	return r.reader.ReadAt(p, int64(r.entry.offset)+off)
 }
+
+// decoded is an entry whose data was decompressed in memory
+type decoded struct {
+	data  []byte
+	extra uint64
+}
+
+func (d decoded) Len() int {
+	return len(d.data)
+}
+
+func (d decoded) Extra() uint64 {
+	return d.extra
+}
+
+func (d decoded) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 || off >= int64(len(d.data)) {
+		return 0, io.EOF
+	}
+
+	if n = copy(p, d.data[off:]); n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -33,3 +33,11 @@ func WithStrict() Option {
 		r.strict = true
 	}
 }
+
+// WithNames sets the function returning the name of the entry at a given index, for
+// files whose entries are not named after the default "build/<file>/<index><ext>".
+func WithNames(fn func(index int) string) Option {
+	return func(r *Reader) {
+		r.names = fn
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package uop
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	headerSize    = 28         // Size of the file header
+	blockCapacity = 1000       // Maximum number of entries per table block
+	tableEntry    = 34         // Size of a single table entry
+	uopVersion    = 5          // Version written into the header
+	uopSignature  = 0xFD23EC43 // Signature written into the header
+)
+
+// Write encodes the entries as an uncompressed UOP file. Each entry is named after
+// the pattern, as in "build/<pattern>/<index><ext>", which must match the lowercase
+// name of the file (without extension) for the entries to be found when reading it.
+// Nil entries are skipped.
+func Write(dst io.Writer, pattern, ext string, entries [][]byte) error {
+	return WriteNamed(dst, func(index int) string {
+		return fmt.Sprintf("build/%s/%08d%s", pattern, index, ext)
+	}, entries)
+}
+
+// WriteNamed encodes the entries as an uncompressed UOP file, each entry being named by
+// the function, for files which do not follow the default naming pattern. The same
+// function must be given to the reader (see WithNames). Nil entries are skipped.
+func WriteNamed(dst io.Writer, name func(index int) string, entries [][]byte) error {
+	var present []int
+	for i, entry := range entries {
+		if entry != nil {
+			present = append(present, i)
+		}
+	}
+
+	// Each block is a table of entries followed by the data of those entries
+	blockCount := (len(present) + blockCapacity - 1) / blockCapacity
+	header := make([]byte, headerSize)
+	binary.LittleEndian.PutUint32(header[0:4], uopMagic)
+	binary.LittleEndian.PutUint32(header[4:8], uopVersion)
+	binary.LittleEndian.PutUint32(header[8:12], uopSignature)
+	binary.LittleEndian.PutUint32(header[20:24], blockCapacity)
+	binary.LittleEndian.PutUint32(header[24:28], uint32(len(present)))
+	if blockCount > 0 {
+		binary.LittleEndian.PutUint64(header[12:20], headerSize)
+	}
+
+	if _, err := dst.Write(header); err != nil {
+		return fmt.Errorf("failed to write UOP header: %w", err)
+	}
+
+	offset := uint64(headerSize)
+	for b := 0; b < blockCount; b++ {
+		batch := present[b*blockCapacity : min((b+1)*blockCapacity, len(present))]
+		table := make([]byte, 12+blockCapacity*tableEntry)
+		dataOffset := offset + uint64(len(table))
+
+		// Compute the location of the data of every entry within this block
+		next := dataOffset
+		for i, index := range batch {
+			entry := table[12+i*tableEntry:]
+			binary.LittleEndian.PutUint64(entry[0:8], next)
+			binary.LittleEndian.PutUint32(entry[12:16], uint32(len(entries[index])))
+			binary.LittleEndian.PutUint32(entry[16:20], uint32(len(entries[index])))
+			binary.LittleEndian.PutUint64(entry[20:28], hashFileName(name(index)))
+			next += uint64(len(entries[index]))
+		}
+
+		binary.LittleEndian.PutUint32(table[0:4], uint32(len(batch)))
+		if b+1 < blockCount {
+			binary.LittleEndian.PutUint64(table[4:12], next)
+		}
+
+		if _, err := dst.Write(table); err != nil {
+			return fmt.Errorf("failed to write UOP table: %w", err)
+		}
+
+		for _, index := range batch {
+			if _, err := dst.Write(entries[index]); err != nil {
+				return fmt.Errorf("failed to write UOP entry %d: %w", index, err)
+			}
+		}
+
+		offset = next
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package uop
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	entries := make([][]byte, 1500) // Spans two table blocks
+	for i := range entries {
+		if i != 3 {
+			entries[i] = []byte(fmt.Sprintf("entry %d", i))
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "test.uop")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, Write(file, "test", ".dat", entries))
+	require.NoError(t, file.Close())
+
+	reader, err := Open(path, len(entries), WithStrict())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for i, expect := range entries {
+		entry, err := reader.Entry(uint32(i))
+		if expect == nil {
+			assert.True(t, err != nil || entry == nil || entry.Len() == 0)
+			continue
+		}
+
+		require.NoError(t, err)
+		data := make([]byte, entry.Len())
+		_, err = entry.ReadAt(data, 0)
+		require.NoError(t, err)
+		assert.Equal(t, expect, data)
+	}
+}
+
+func TestWriteNamed(t *testing.T) {
+	name := func(index int) string {
+		return fmt.Sprintf("build/custom/%06d/%02d.bin", index/10, index%10)
+	}
+
+	entries := make([][]byte, 30)
+	entries[12] = []byte("body 1, action 2")
+	entries[25] = []byte("body 2, action 5")
+
+	path := filepath.Join(t.TempDir(), "test.uop")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, WriteNamed(file, name, entries))
+	require.NoError(t, file.Close())
+
+	reader, err := Open(path, len(entries), WithNames(name), WithStrict())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for i, expect := range entries {
+		entry, err := reader.Entry(uint32(i))
+		require.NoError(t, err)
+		if expect == nil {
+			assert.Nil(t, entry)
+			continue
+		}
+
+		data := make([]byte, entry.Len())
+		_, err = entry.ReadAt(data, 0)
+		require.NoError(t, err)
+		assert.Equal(t, expect, data)
+	}
+}
+
+func TestReader_Compressed(t *testing.T) {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	_, err := w.Write([]byte("hello, compressed world"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, "test", ".dat", [][]byte{compressed.Bytes()}))
+
+	// Flag the single entry of the table as zlib-compressed
+	data := out.Bytes()
+	binary.LittleEndian.PutUint32(data[headerSize+12+16:], 23)
+	binary.LittleEndian.PutUint16(data[headerSize+12+32:], uint16(CompressionZlib))
+
+	path := filepath.Join(t.TempDir(), "test.uop")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	reader, err := Open(path, 1)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	entry, err := reader.Entry(0)
+	require.NoError(t, err)
+	require.Equal(t, 23, entry.Len())
+
+	value := make([]byte, entry.Len())
+	_, err = entry.ReadAt(value, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, compressed world", string(value))
+}
@@ -7,8 +7,11 @@ import (
 	"encoding/binary"
 	"fmt"
 	"image"
+	"iter"
 
+	"codeberg.org/go-mmap/mmap"
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
 	"github.com/kelindar/ultima-sdk/internal/uofile"
 )
 
@@ -39,6 +42,31 @@ type Tile struct {
 	Statics []StaticItem // Statics located at this tile
 }
 
+// LandTile is a single land tile within a map block.
+type LandTile struct {
+	ID uint16 // Land tile ID
+	Z  int8   // Land tile elevation
+}
+
+// LandBlock is an 8x8 block of land tiles, as stored in mapX.mul.
+type LandBlock struct {
+	Header uint32       // Block header, unused by the client
+	Tiles  [64]LandTile // Land tiles, in row-major order (y*8 + x)
+}
+
+// StaticBlock holds the statics of an 8x8 block of the map, grouped by tile.
+type StaticBlock struct {
+	Tiles [64][]StaticItem // Statics of each tile, in row-major order (y*8 + x)
+}
+
+// PlacedStatic is a static located at world coordinates on the map.
+type PlacedStatic struct {
+	X, Y int    // World coordinates
+	Z    int8   // Elevation
+	ID   uint16 // Item ID
+	Hue  uint16 // Hue, 0 if none
+}
+
 // TileMap provides access to Ultima Online map data.
 type TileMap struct {
 	sdk           *SDK
@@ -46,6 +74,46 @@ type TileMap struct {
 	width, height int
 	mapFile       *uofile.File // internal: mapX.mul
 	staticsFile   *uofile.File // internal: staticsX.mul + staidxX.mul
+	patches       *mapPatches  // internal: mapdifX.mul + stadifX.mul patches, if any
+	unpatched     bool         // Whether the patches are ignored
+	edits         *mapEdits    // Pending in-memory modifications
+	cache         *blockCache  // Recently read blocks
+}
+
+// WithPatches returns a view of the map with the map difference patches (mapdifX.mul,
+// stadifX.mul) of classic clients applied or ignored. Patches are applied by default.
+func (m *TileMap) WithPatches(enabled bool) *TileMap {
+	view := *m
+	view.unpatched = !enabled
+	view.cache = newBlockCache(blockCacheSize)
+	return &view
+}
+
+// landOverride returns the modified or patched land block for the block index, if any
+func (m *TileMap) landOverride(blockIndex int) ([]byte, bool) {
+	if block, ok := m.editedLand(blockIndex); ok {
+		return block, true
+	}
+
+	return m.landPatch(blockIndex)
+}
+
+// landPatch returns the patched land block for the block index, if any
+func (m *TileMap) landPatch(blockIndex int) ([]byte, bool) {
+	if m.patches == nil || m.unpatched {
+		return nil, false
+	}
+
+	return m.patches.landBlock(blockIndex)
+}
+
+// staticsPatch returns the patched statics for the block index, if any
+func (m *TileMap) staticsPatch(blockIndex int) ([]StaticItem, bool) {
+	if m.patches == nil || m.unpatched {
+		return nil, false
+	}
+
+	return m.patches.staticsBlock(blockIndex)
 }
 
 // NewTileMap initializes a TileMap for a given map index and files.
@@ -56,16 +124,19 @@ func NewTileMap(mapID int, mapFile, staticsFile *uofile.File, width, height int)
 		height:      height,
 		mapFile:     mapFile,
 		staticsFile: staticsFile,
+		edits:       newMapEdits(),
+		cache:       newBlockCache(blockCacheSize),
 	}
 }
 
-// decodeMapTile parses a single tile from a 196-byte map block, including statics.
+// decodeMapTile parses a single tile from a 196-byte map block (including its 4-byte
+// header), along with its statics.
 func decodeMapTile(block []byte, tileIndex int, statics []StaticItem) (*Tile, error) {
-	if len(block) < 196 {
-		return nil, fmt.Errorf("decodeMapTile: expected 196 bytes, got %d", len(block))
+	if len(block) < mapBlockSize {
+		return nil, fmt.Errorf("decodeMapTile: expected %d bytes, got %d", mapBlockSize, len(block))
 	}
 
-	tileData := block[tileIndex*3 : tileIndex*3+3]
+	tileData := block[4+tileIndex*3 : 4+tileIndex*3+3]
 	x := tileIndex % 8
 	y := tileIndex / 8
 
@@ -91,46 +162,220 @@ func (m *TileMap) TileAt(x, y int) (*Tile, error) {
 		return nil, fmt.Errorf("TileAt: coordinates out of bounds (%d,%d)", x, y)
 	}
 
-	// Calculate the block index (column-major) and entry index
+	// Calculate the block index (column-major) and tile index within the block
 	blocksDown := m.height / 8
 	blockX, blockY := x/8, y/8
 	blockIndex := blockX*blocksDown + blockY
-	entryIndex := blockIndex / blocksPerEntry
-	blockOffset := blockIndex % blocksPerEntry
-	blockStart := 4 + blockOffset*196
 	tileIndex := (y%8)*8 + (x % 8)
 
-	// Read the entry and check if it's valid
-	entry, err := m.mapFile.Entry(uint32(entryIndex))
-	switch {
-	case err != nil:
-		return nil, fmt.Errorf("TileAt: failed reading UOP entry: %w", err)
-	case entry.Len() < 4+(blockOffset+1)*196:
-		return nil, fmt.Errorf("TileAt: entry too small for block offset (entry len=%d, needed=%d)", entry.Len(), 4+(blockOffset+1)*196)
+	// Get the block data, along with the statics of the block
+	block, err := m.readBlock(blockIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMapTile(block.land[:], tileIndex, block.statics)
+}
+
+// LandBlock returns the decoded land tiles of the 8x8 block at the given block
+// coordinates, including any modifications and enabled patches.
+func (m *TileMap) LandBlock(blockX, blockY int) (*LandBlock, error) {
+	blockIndex, err := m.blockIndex(blockX, blockY)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get the block data
-	buffer, release := uofile.Borrow(196)
+	buffer, release := uofile.Borrow(mapBlockSize)
 	defer release()
 
-	n, err := entry.ReadAt(buffer, int64(blockStart))
-	switch {
-	case err != nil:
-		return nil, fmt.Errorf("TileAt: failed reading entry: %w", err)
-	case n < 196:
-		return nil, fmt.Errorf("TileAt: entry too small for block offset (entry len=%d, needed=%d)", n, 196)
+	if err := m.readLandBlock(buffer, blockIndex); err != nil {
+		return nil, err
+	}
+
+	return decodeLandBlock(buffer), nil
+}
+
+// StaticBlock returns the statics of the 8x8 block at the given block coordinates,
+// grouped by tile, including any modifications and enabled patches.
+func (m *TileMap) StaticBlock(blockX, blockY int) (*StaticBlock, error) {
+	blockIndex, err := m.blockIndex(blockX, blockY)
+	if err != nil {
+		return nil, err
 	}
 
-	// Read statics for this block
 	statics, err := m.readStatics(blockIndex)
 	if err != nil {
-		return nil, fmt.Errorf("TileAt: failed to read statics: %w", err)
+		return nil, err
+	}
+
+	block := new(StaticBlock)
+	for _, s := range statics {
+		x, y, _ := s.Location()
+		if x < 8 && y < 8 {
+			block.Tiles[int(y)*8+int(x)] = append(block.Tiles[int(y)*8+int(x)], s)
+		}
+	}
+	return block, nil
+}
+
+// StaticsInRect iterates over the statics located within the rectangle (in tile
+// coordinates), including any modifications and enabled patches. Statics are yielded
+// block by block, in the order they are stored.
+func (m *TileMap) StaticsInRect(r image.Rectangle) iter.Seq[PlacedStatic] {
+	return func(yield func(PlacedStatic) bool) {
+		r = r.Intersect(image.Rect(0, 0, m.width, m.height))
+		if r.Empty() {
+			return
+		}
+
+		for blockX := r.Min.X / 8; blockX <= (r.Max.X-1)/8; blockX++ {
+			for blockY := r.Min.Y / 8; blockY <= (r.Max.Y-1)/8; blockY++ {
+				statics, err := m.readStatics(blockX*(m.height/8) + blockY)
+				if err != nil {
+					continue
+				}
+
+				for _, s := range statics {
+					x, y, z := s.Location()
+					placed := PlacedStatic{
+						X:   blockX*8 + int(x),
+						Y:   blockY*8 + int(y),
+						Z:   z,
+						ID:  s.ID(),
+						Hue: s.Hue(),
+					}
+
+					if image.Pt(placed.X, placed.Y).In(r) && !yield(placed) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Tiles iterates over the tiles covered by the rectangle (in tile coordinates) along
+// with their world coordinates, including any modifications and enabled patches. Each
+// block is read only once and its tiles are yielded together, so this is much faster
+// than calling TileAt for every tile of a large region.
+func (m *TileMap) Tiles(r image.Rectangle) iter.Seq2[image.Point, *Tile] {
+	return func(yield func(image.Point, *Tile) bool) {
+		r = r.Intersect(image.Rect(0, 0, m.width, m.height))
+		if r.Empty() {
+			return
+		}
+
+		for blockX := r.Min.X / 8; blockX <= (r.Max.X-1)/8; blockX++ {
+			for blockY := r.Min.Y / 8; blockY <= (r.Max.Y-1)/8; blockY++ {
+				block, err := m.readBlock(blockX*(m.height/8) + blockY)
+				if err != nil {
+					continue
+				}
+
+				// Group the statics of the block by tile
+				var statics [64][]StaticItem
+				for _, s := range block.statics {
+					if x, y, _ := s.Location(); x < 8 && y < 8 {
+						statics[int(y)*8+int(x)] = append(statics[int(y)*8+int(x)], s)
+					}
+				}
+
+				for i := 0; i < 64; i++ {
+					at := image.Pt(blockX*8+i%8, blockY*8+i/8)
+					if !at.In(r) {
+						continue
+					}
+
+					tile := &Tile{
+						ID:      binary.LittleEndian.Uint16(block.land[4+i*3:]),
+						Z:       int8(block.land[4+i*3+2]),
+						Statics: statics[i],
+					}
+
+					if !yield(at, tile) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// blockIndex returns the index of the block at the given block coordinates
+func (m *TileMap) blockIndex(blockX, blockY int) (int, error) {
+	if blockX < 0 || blockY < 0 || blockX >= m.width/8 || blockY >= m.height/8 {
+		return 0, fmt.Errorf("block out of bounds (%d,%d)", blockX, blockY)
+	}
+	return blockX*(m.height/8) + blockY, nil
+}
+
+// decodeLandBlock decodes a 196-byte land block, including its header
+func decodeLandBlock(data []byte) *LandBlock {
+	block := &LandBlock{Header: binary.LittleEndian.Uint32(data[0:4])}
+	for i := range block.Tiles {
+		block.Tiles[i] = LandTile{
+			ID: binary.LittleEndian.Uint16(data[4+i*3:]),
+			Z:  int8(data[4+i*3+2]),
+		}
+	}
+	return block
+}
+
+// readLandBlock reads the 196-byte land block (including its header) with the given
+// index into the buffer, using the modified or patched block if there is one.
+func (m *TileMap) readLandBlock(buffer []byte, blockIndex int) error {
+	if block, ok := m.editedLand(blockIndex); ok {
+		copy(buffer, block)
+		return nil
+	}
+
+	return m.readBaseLandBlock(buffer, blockIndex)
+}
+
+// readBaseLandBlock reads the land block with the given index from the patches or the
+// map file, ignoring any in-memory modifications.
+func (m *TileMap) readBaseLandBlock(buffer []byte, blockIndex int) error {
+	if block, ok := m.landPatch(blockIndex); ok {
+		copy(buffer, block)
+		return nil
+	}
+
+	entryIndex := blockIndex / blocksPerEntry
+	blockOffset := blockIndex % blocksPerEntry
+	entry, err := m.mapFile.Entry(uint32(entryIndex))
+	switch {
+	case err != nil:
+		return fmt.Errorf("TileAt: failed reading UOP entry: %w", err)
+	case entry == nil || entry.Len() < (blockOffset+1)*mapBlockSize:
+		return fmt.Errorf("TileAt: entry %d too small for block offset %d", entryIndex, blockOffset)
+	}
+
+	n, err := entry.ReadAt(buffer[:mapBlockSize], int64(blockOffset*mapBlockSize))
+	switch {
+	case err != nil:
+		return fmt.Errorf("TileAt: failed reading entry: %w", err)
+	case n < mapBlockSize:
+		return fmt.Errorf("TileAt: entry too small for block offset (read=%d, needed=%d)", n, mapBlockSize)
 	}
-	return decodeMapTile(buffer, tileIndex, statics)
+	return nil
 }
 
-// readStatics reads and parses statics for a given block index.
+// readStatics reads and parses statics for a given block index, including any
+// in-memory modifications.
 func (m *TileMap) readStatics(blockIndex int) ([]StaticItem, error) {
+	if statics, ok := m.editedStatics(blockIndex); ok {
+		return statics, nil
+	}
+
+	return m.readBlockStatics(blockIndex)
+}
+
+// readBlockStatics reads and parses statics for a given block index from the files.
+func (m *TileMap) readBlockStatics(blockIndex int) ([]StaticItem, error) {
+	if statics, ok := m.staticsPatch(blockIndex); ok {
+		return statics, nil
+	}
+
 	entry, err := m.staticsFile.Entry(uint32(blockIndex))
 	switch {
 	case err != nil:
@@ -153,13 +398,84 @@ func (m *TileMap) readStatics(blockIndex int) ([]StaticItem, error) {
 	return statics, nil
 }
 
-// Map returns the TileMap for the given map index, loading if necessary.
+// Map returns the TileMap for the given map index, loading if necessary. The dimensions
+// of the map are detected from the size of its file.
 func (s *SDK) Map(mapID int) (*TileMap, error) {
-	return s.loadTileMap(mapID)
+	return s.loadTileMap(mapID, 0, 0)
+}
+
+// MapWithSize returns the TileMap for the given map index with explicit dimensions (in
+// tiles, multiples of 8), for custom facets whose size cannot be detected reliably.
+func (s *SDK) MapWithSize(mapID, width, height int) (*TileMap, error) {
+	if width <= 0 || height <= 0 || width%8 != 0 || height%8 != 0 {
+		return nil, fmt.Errorf("MapWithSize: invalid map size %dx%d", width, height)
+	}
+
+	return s.loadTileMap(mapID, width, height)
+}
+
+// mapDefinition describes a custom facet registered with RegisterMap
+type mapDefinition struct {
+	width, height int
+	mapFile       string   // Land file, either .mul or .uop
+	staticsFiles  []string // Statics data and index files
+}
+
+// RegisterMap registers a custom facet, so that it can be loaded through Map. The first
+// file name is the land file of the map (a .mul or .uop file), optionally followed by
+// the statics data and index files, which default to staticsX.mul and staidxX.mul. The
+// registration replaces the files and dimensions of an existing map with the same ID,
+// which must be registered before it is loaded: an error is returned if the files of the
+// map are already loaded, as the TileMaps handed out keep reading them.
+func (s *SDK) RegisterMap(mapID, width, height int, fileNames ...string) error {
+	switch {
+	case mapID < 0:
+		return fmt.Errorf("RegisterMap: invalid map ID %d", mapID)
+	case s.isMapLoaded(mapID):
+		return fmt.Errorf("RegisterMap: map %d is already loaded", mapID)
+	case width <= 0 || height <= 0 || width%8 != 0 || height%8 != 0:
+		return fmt.Errorf("RegisterMap: invalid map size %dx%d", width, height)
+	case len(fileNames) == 0 || len(fileNames) == 2 || len(fileNames) > 3:
+		return fmt.Errorf("RegisterMap: expected a map file, optionally followed by statics and staidx files")
+	}
+
+	def := &mapDefinition{
+		width:        width,
+		height:       height,
+		mapFile:      fileNames[0],
+		staticsFiles: []string{fmt.Sprintf("statics%d.mul", mapID), fmt.Sprintf("staidx%d.mul", mapID)},
+	}
+
+	if len(fileNames) == 3 {
+		def.staticsFiles = fileNames[1:]
+	}
+
+	s.maps.Store(mapID, def)
+	return nil
+}
+
+// isMapLoaded returns whether the land, statics or facet files of the map are already loaded
+func (s *SDK) isMapLoaded(mapID int) bool {
+	land, statics := s.mapFileNames(mapID)
+	for _, fileNames := range [][]string{land, statics, {facetFileName(mapID)}} {
+		if _, ok := s.files.Load(cacheKey(fileNames[0])); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mapDefinition returns the custom facet registered for the map ID, if any
+func (s *SDK) mapDefinition(mapID int) (*mapDefinition, bool) {
+	if def, ok := s.maps.Load(mapID); ok {
+		return def.(*mapDefinition), true
+	}
+	return nil, false
 }
 
-// loadTileMap loads and returns a TileMap for the given map ID.
-func (s *SDK) loadTileMap(mapID int) (*TileMap, error) {
+// loadTileMap loads and returns a TileMap for the given map ID, detecting its
+// dimensions if they are not specified.
+func (s *SDK) loadTileMap(mapID, width, height int) (*TileMap, error) {
 	mapFile, err := s.loadMap(mapID)
 	if err != nil {
 		return nil, fmt.Errorf("loadTileMap: failed to load map file: %w", err)
@@ -168,7 +484,20 @@ func (s *SDK) loadTileMap(mapID int) (*TileMap, error) {
 	if err != nil {
 		return nil, fmt.Errorf("loadTileMap: failed to load statics file: %w", err)
 	}
-	width, height := detectMapSize(mapID)
+	patches, err := s.loadMapPatches(mapID)
+	if err != nil {
+		return nil, fmt.Errorf("loadTileMap: failed to load map patches: %w", err)
+	}
+
+	def, registered := s.mapDefinition(mapID)
+	switch {
+	case width > 0 && height > 0:
+	case registered:
+		width, height = def.width, def.height
+	default:
+		width, height = detectMapSize(mapID, countMapBlocks(mapFile))
+	}
+
 	return &TileMap{
 		sdk:         s,
 		mapID:       mapID,
@@ -176,27 +505,90 @@ func (s *SDK) loadTileMap(mapID int) (*TileMap, error) {
 		height:      height,
 		mapFile:     mapFile,
 		staticsFile: staticsFile,
+		patches:     patches,
+		edits:       newMapEdits(),
+		cache:       newBlockCache(blockCacheSize),
 	}, nil
 }
 
-// detectMapSize returns the width and height for a given map ID, checking for extended maps.
-func detectMapSize(mapID int) (width, height int) {
-	switch mapID {
-	case 0: // Felucca
-		return 6144, 4096
-	case 1: // Trammel
-		return 7168, 4096
-	case 2: // Ilshenar
-		return 2304, 1600
-	case 3: // Malas
-		return 2560, 2048
-	case 4: // Tokuno
-		return 1448, 1448
-	case 5: // TerMur
-		return 1280, 4096
-	default:
-		return 6144, 4096 // fallback
+// Size returns the width and height of the map, in tiles.
+func (m *TileMap) Size() (width, height int) {
+	return m.width, m.height
+}
+
+// decodeMapFile splits a mapX.mul file into entries of blocksPerEntry blocks each, so
+// that it can be addressed exactly like the map UOP files
+func decodeMapFile(file *mmap.File, add mul.AddFn) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	const entrySize = blocksPerEntry * mapBlockSize
+	for i, offset := uint32(0), int64(0); offset < info.Size(); i, offset = i+1, offset+entrySize {
+		add(i, uint32(offset), uint32(min(entrySize, info.Size()-offset)), 0, nil)
+	}
+	return nil
+}
+
+// knownMapSizes lists the dimensions of the facets shipped with the clients, the
+// first one for each map ID being the most recent
+var knownMapSizes = map[int][][2]int{
+	0: {{7168, 4096}, {6144, 4096}}, // Felucca
+	1: {{7168, 4096}, {6144, 4096}}, // Trammel
+	2: {{2304, 1600}},               // Ilshenar
+	3: {{2560, 2048}},               // Malas
+	4: {{1448, 1448}},               // Tokuno
+	5: {{1280, 4096}},               // TerMur
+}
+
+// countMapBlocks returns the number of 196-byte blocks stored in the map file
+func countMapBlocks(file *uofile.File) int {
+	size := 0
+	for key := range file.Entries() {
+		if entry, err := file.Entry(key); err == nil && entry != nil {
+			size += entry.Len()
+		}
+	}
+	return size / mapBlockSize
+}
+
+// detectMapSize returns the width and height of a map with the given number of blocks.
+// The known dimensions of the map ID are preferred, then those of any other facet with
+// the same number of blocks. Otherwise the dimensions are inferred from the number of
+// blocks, as the layout closest to a square which is at least as wide as it is tall.
+func detectMapSize(mapID, blocks int) (width, height int) {
+	if blocks <= 0 {
+		return 6144, 4096 // Unknown size, assume the classic Felucca
+	}
+
+	matches := func(size [2]int) bool {
+		return (size[0]/8)*(size[1]/8) == blocks
+	}
+
+	for _, size := range knownMapSizes[mapID] {
+		if matches(size) {
+			return size[0], size[1]
+		}
+	}
+
+	for id := range len(knownMapSizes) {
+		for _, size := range knownMapSizes[id] {
+			if matches(size) {
+				return size[0], size[1]
+			}
+		}
+	}
+
+	// Largest number of blocks down which divides the block count, without exceeding
+	// the number of blocks across
+	blocksDown := 1
+	for d := 1; d*d <= blocks; d++ {
+		if blocks%d == 0 {
+			blocksDown = d
+		}
 	}
+	return blocks / blocksDown * 8, blocksDown * 8
 }
 
 // Image renders the map as a radar-color overview (1 pixel per tile).
@@ -204,9 +596,9 @@ func (m *TileMap) Image() (image.Image, error) {
 	img := bitmap.NewARGB1555(image.Rect(0, 0, m.width, m.height))
 	blocksDown := m.height / 8
 
-	colors := make([]RadarColor, 0, totalRadarColors)
-	for c := range m.sdk.RadarColors() {
-		colors = append(colors, c)
+	colors, err := m.sdk.radarTable()
+	if err != nil {
+		return nil, fmt.Errorf("map.Image: %w", err)
 	}
 
 	buffer := make([]byte, 196*blocksPerEntry)
@@ -219,17 +611,20 @@ func (m *TileMap) Image() (image.Image, error) {
 			return nil, fmt.Errorf("map.Image: entry %d has invalid length (%d bytes)", entry, data.Len())
 		}
 
-		n, err := data.ReadAt(buffer, 0)
+		n, err := data.ReadAt(buffer[:min(data.Len(), len(buffer))], 0)
 		if err != nil {
 			return nil, fmt.Errorf("map.Image: failed reading entry %d: %w", entry, err)
 		}
 
 		length := n / 196
 		for blockIndex := 0; blockIndex < length; blockIndex++ {
-			blockAbs := int(entry)*length + blockIndex
+			blockAbs := int(entry)*blocksPerEntry + blockIndex
 			blockX := blockAbs / blocksDown
 			blockY := blockAbs % blocksDown
 			blockData := buffer[blockIndex*196 : blockIndex*196+196]
+			if block, ok := m.landOverride(blockAbs); ok {
+				blockData = block
+			}
 			if len(blockData) < 4+192 {
 				return nil, fmt.Errorf("map.Image: block %d too short (%d bytes)", blockAbs, len(blockData))
 			}
@@ -245,9 +640,16 @@ func (m *TileMap) Image() (image.Image, error) {
 					continue
 				}
 
-				img.Set(x0, y0, colors[tileID].GetColor())
+				setARGB1555(img, x0, y0, colors[tileID])
 			}
 		}
 	}
 	return img, nil
 }
+
+// ImageRect renders the tiles of the map covered by the rectangle (in tile coordinates)
+// as a radar-color overview (1 pixel per tile). The image is positioned at the origin,
+// so that the pixel (0, 0) corresponds to the top-left tile of the rectangle.
+func (m *TileMap) ImageRect(r image.Rectangle) (image.Image, error) {
+	return m.Overview(r, OverviewOptions{})
+}
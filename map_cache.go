@@ -0,0 +1,108 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"container/list"
+	"sync"
+)
+
+const blockCacheSize = 256 // Number of decoded blocks kept by each map
+
+// mapBlock is a block of the map, with its land (including the header) and statics
+type mapBlock struct {
+	index   int    // Block index
+	version uint64 // Version of the map edits the block was read at
+	land    [mapBlockSize]byte
+	statics []StaticItem
+}
+
+// blockCache is a least-recently-used cache of the blocks of a map
+type blockCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List            // Blocks, most recently used first
+	entries  map[int]*list.Element // Elements of the order list, by block index
+}
+
+// newBlockCache creates a cache keeping at most the given number of blocks
+func newBlockCache(capacity int) *blockCache {
+	return &blockCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[int]*list.Element, capacity),
+	}
+}
+
+// get returns the cached block with the given index, if it is still current
+func (c *blockCache) get(blockIndex int, version uint64) (*mapBlock, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[blockIndex]
+	if !ok {
+		return nil, false
+	}
+
+	block := elem.Value.(*mapBlock)
+	if block.version != version {
+		c.order.Remove(elem)
+		delete(c.entries, blockIndex)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return block, true
+}
+
+// put adds the block to the cache, evicting the least recently used one if full
+func (c *blockCache) put(block *mapBlock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[block.index]; ok {
+		elem.Value = block
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*mapBlock).index)
+	}
+
+	c.entries[block.index] = c.order.PushFront(block)
+}
+
+// readBlock returns the land and statics of the block with the given index, including
+// any modifications and enabled patches, from the cache if possible
+func (m *TileMap) readBlock(blockIndex int) (*mapBlock, error) {
+	var version uint64
+	if m.edits != nil {
+		version = m.edits.version.Load()
+	}
+
+	if m.cache != nil {
+		if block, ok := m.cache.get(blockIndex, version); ok {
+			return block, nil
+		}
+	}
+
+	block := &mapBlock{index: blockIndex, version: version}
+	if err := m.readLandBlock(block.land[:], blockIndex); err != nil {
+		return nil, err
+	}
+
+	statics, err := m.readStatics(blockIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	block.statics = statics
+	if m.cache != nil {
+		m.cache.put(block)
+	}
+	return block, nil
+}
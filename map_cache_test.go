@@ -0,0 +1,59 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockCache(t *testing.T) {
+	cache := newBlockCache(2)
+	cache.put(&mapBlock{index: 1})
+	cache.put(&mapBlock{index: 2})
+
+	// Reading the first block makes the second one the least recently used
+	_, ok := cache.get(1, 0)
+	assert.True(t, ok)
+
+	cache.put(&mapBlock{index: 3})
+	_, ok = cache.get(2, 0)
+	assert.False(t, ok)
+	_, ok = cache.get(3, 0)
+	assert.True(t, ok)
+
+	// Blocks read at another version are stale
+	_, ok = cache.get(1, 1)
+	assert.False(t, ok)
+	_, ok = cache.get(1, 0)
+	assert.False(t, ok)
+}
+
+func TestTileMap_BlockCache(t *testing.T) {
+	m := openTestMap(t, t.TempDir(), map[int][]StaticItem{
+		0: {NewStaticItem(0x100, 1, 1, 0, 0)},
+	})
+
+	tile, err := m.TileAt(1, 1)
+	require.NoError(t, err)
+	assert.Len(t, tile.Statics, 1)
+
+	// Modifications are visible through the cache
+	require.NoError(t, m.SetTile(1, 1, 0xA8, 4))
+	require.NoError(t, m.AddStatic(1, 1, 5, 0x101, 0))
+	tile, err = m.TileAt(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0xA8), tile.ID)
+	assert.Equal(t, int8(4), tile.Z)
+	assert.Len(t, tile.Statics, 2)
+
+	// Cached blocks are reused
+	first, err := m.readBlock(0)
+	require.NoError(t, err)
+	second, err := m.readBlock(0)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+}
@@ -0,0 +1,304 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/kelindar/ultima-sdk/internal/uofile"
+)
+
+// facetSectorSize is the size of the sectors of the Enhanced Client's facet files, in tiles
+const facetSectorSize = 64
+
+// facetFileName returns the name of the Enhanced Client's facet file of the map
+func facetFileName(mapID int) string {
+	return fmt.Sprintf("facet%d.uop", mapID)
+}
+
+// facetSectorName returns the function naming the sectors within the facet file of the map
+func facetSectorName(mapID int) func(index int) string {
+	return func(index int) string {
+		return fmt.Sprintf("build/sectors/facet_%02d/%08d.bin", mapID, index)
+	}
+}
+
+// facet reads the map from a facet file of the Enhanced Client (facetX.uop), whose
+// entries are sectors of 64x64 tiles in column-major order, and exposes them as the land
+// and statics files of the classic clients, so that the TileMap reads both alike.
+//
+// Every sector lists its tiles column by column, each tile being:
+//   - the elevation (int8) and the ID (uint16) of the land tile
+//   - the number of delimiters (uint8), followed by 4 bytes per delimiter, which are
+//     the map borders drawn by the Enhanced Client and are skipped
+//   - the number of statics (uint8), followed by 7 bytes per static: its ID (uint16),
+//     2 unused bytes, its elevation (int8) and its hue (uint16)
+type facet struct {
+	file          *uofile.File
+	path          string
+	width, height int // Size of the map, in tiles
+	mu            sync.Mutex
+	last          *facetSector // Last decoded sector, as blocks are mostly read in order
+}
+
+// facetSector is a sector of a facet, decoded into the 8x8 blocks of the classic files
+// in column-major order
+type facetSector struct {
+	index   int
+	land    [64][mapBlockSize]byte
+	statics [64][]byte
+}
+
+// newFacet returns the facet of the file, detecting its dimensions from the number of
+// sectors it holds
+func newFacet(mapID int, path string, file *uofile.File) *facet {
+	sectors := 0
+	for key := range file.Entries() {
+		sectors = max(sectors, int(key)+1)
+	}
+
+	width, height := detectFacetSize(mapID, sectors)
+	return &facet{file: file, path: path, width: width, height: height}
+}
+
+// detectFacetSize returns the width and height of a facet with the given number of
+// sectors: the known dimensions of the map ID if they match, or else the dimensions
+// inferred as for the classic files, with sectors in place of blocks.
+func detectFacetSize(mapID, sectors int) (width, height int) {
+	for _, size := range knownMapSizes[mapID] {
+		if facetSectors(size[0])*facetSectors(size[1]) == sectors {
+			return size[0], size[1]
+		}
+	}
+
+	width, height = detectMapSize(-1, sectors)
+	return width / 8 * facetSectorSize, height / 8 * facetSectorSize
+}
+
+// facetSectors returns the number of sectors covering a length in tiles
+func facetSectors(tiles int) int {
+	return (tiles + facetSectorSize - 1) / facetSectorSize
+}
+
+// blocks returns the number of 8x8 blocks of the map
+func (f *facet) blocks() int {
+	return (f.width / 8) * (f.height / 8)
+}
+
+// land returns the facet as a classic land file, of blocksPerEntry blocks per entry
+func (f *facet) land() *uofile.File {
+	return uofile.FromReader(f.path, facetLand{f})
+}
+
+// statics returns the facet as a classic statics file, with an entry per block
+func (f *facet) statics() *uofile.File {
+	return uofile.FromReader(f.path, facetStatics{f})
+}
+
+// block returns the sector containing the block, along with the position of the block
+// within the sector
+func (f *facet) block(blockIndex int) (*facetSector, int, error) {
+	blocksDown := f.height / 8
+	blockX, blockY := blockIndex/blocksDown, blockIndex%blocksDown
+	sectorIndex := (blockX/8)*facetSectors(f.height) + blockY/8
+
+	sector, err := f.sector(sectorIndex)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sector, (blockX%8)*8 + blockY%8, nil
+}
+
+// sector returns the decoded sector with the given index
+func (f *facet) sector(index int) (*facetSector, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.last != nil && f.last.index == index {
+		return f.last, nil
+	}
+
+	data, err := f.file.ReadFull(uint32(index))
+	if err != nil {
+		return nil, fmt.Errorf("facet: failed to read sector %d: %w", index, err)
+	}
+
+	sector, err := decodeFacetSector(index, data)
+	if err != nil {
+		return nil, err
+	}
+
+	f.last = sector
+	return sector, nil
+}
+
+// decodeFacetSector decodes the tiles of a sector into the blocks of the classic files
+func decodeFacetSector(index int, data []byte) (*facetSector, error) {
+	sector := &facetSector{index: index}
+	reader := bytes.NewReader(data)
+	var tile [3]byte
+	var static [7]byte
+	for x := 0; x < facetSectorSize; x++ {
+		for y := 0; y < facetSectorSize; y++ {
+			if _, err := io.ReadFull(reader, tile[:]); err != nil {
+				return nil, fmt.Errorf("facet: sector %d is truncated at tile (%d,%d)", index, x, y)
+			}
+
+			// Land tile, stored in the block as its ID followed by its elevation
+			block := (x/8)*8 + y/8
+			offset := 4 + ((y%8)*8+x%8)*3
+			copy(sector.land[block][offset:], tile[1:3])
+			sector.land[block][offset+2] = tile[0]
+
+			delimiters, err := reader.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("facet: sector %d is truncated at tile (%d,%d)", index, x, y)
+			}
+			if _, err := reader.Seek(int64(delimiters)*4, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+
+			count, err := reader.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("facet: sector %d is truncated at tile (%d,%d)", index, x, y)
+			}
+
+			for i := 0; i < int(count); i++ {
+				if _, err := io.ReadFull(reader, static[:]); err != nil {
+					return nil, fmt.Errorf("facet: sector %d is truncated at tile (%d,%d)", index, x, y)
+				}
+
+				// Static, stored in the block as its ID, position, elevation and hue
+				sector.statics[block] = append(sector.statics[block],
+					static[0], static[1], byte(x%8), byte(y%8), static[4], static[5], static[6])
+			}
+		}
+	}
+	return sector, nil
+}
+
+// facetLand exposes the land of a facet as a classic land file
+type facetLand struct {
+	facet *facet
+}
+
+// Entry returns the entry of blocksPerEntry blocks with the given index
+func (r facetLand) Entry(key uint32) (uofile.Entry, error) {
+	first := int(key) * blocksPerEntry
+	if first >= r.facet.blocks() {
+		return nil, fmt.Errorf("facet: land entry %d out of range", key)
+	}
+
+	return facetLandEntry{r.facet, first, min(blocksPerEntry, r.facet.blocks()-first)}, nil
+}
+
+// Entries returns the indices of the land entries
+func (r facetLand) Entries() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for key := 0; key*blocksPerEntry < r.facet.blocks(); key++ {
+			if !yield(uint32(key)) {
+				return
+			}
+		}
+	}
+}
+
+// Close does nothing, the facet file being closed with the SDK
+func (r facetLand) Close() error {
+	return nil
+}
+
+// facetLandEntry is a run of land blocks of a facet
+type facetLandEntry struct {
+	facet *facet
+	first int // Index of the first block
+	count int // Number of blocks
+}
+
+// Len returns the size of the blocks, in bytes
+func (e facetLandEntry) Len() int {
+	return e.count * mapBlockSize
+}
+
+// Extra returns no extra data
+func (e facetLandEntry) Extra() uint64 {
+	return 0
+}
+
+// ReadAt reads the blocks, as laid out in the classic land files
+func (e facetLandEntry) ReadAt(p []byte, off int64) (n int, err error) {
+	for n < len(p) && int(off)+n < e.Len() {
+		at := int(off) + n
+		sector, block, err := e.facet.block(e.first + at/mapBlockSize)
+		if err != nil {
+			return n, err
+		}
+
+		n += copy(p[n:], sector.land[block][at%mapBlockSize:])
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// facetStatics exposes the statics of a facet as a classic statics file
+type facetStatics struct {
+	facet *facet
+}
+
+// Entry returns the statics of the block with the given index, or nil if it has none
+func (r facetStatics) Entry(key uint32) (uofile.Entry, error) {
+	if int(key) >= r.facet.blocks() {
+		return nil, fmt.Errorf("facet: block %d out of range", key)
+	}
+
+	sector, block, err := r.facet.block(int(key))
+	switch {
+	case err != nil:
+		return nil, err
+	case len(sector.statics[block]) == 0:
+		return nil, nil
+	default:
+		return facetEntry(sector.statics[block]), nil
+	}
+}
+
+// Entries returns the indices of every block
+func (r facetStatics) Entries() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for key := 0; key < r.facet.blocks(); key++ {
+			if !yield(uint32(key)) {
+				return
+			}
+		}
+	}
+}
+
+// Close does nothing, the facet file being closed with the SDK
+func (r facetStatics) Close() error {
+	return nil
+}
+
+// facetEntry is an entry decoded from a facet
+type facetEntry []byte
+
+// Len returns the size of the entry, in bytes
+func (e facetEntry) Len() int {
+	return len(e)
+}
+
+// Extra returns no extra data
+func (e facetEntry) Extra() uint64 {
+	return 0
+}
+
+// ReadAt reads the entry
+func (e facetEntry) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(e).ReadAt(p, off)
+}
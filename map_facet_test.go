@@ -0,0 +1,112 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/uop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDK_MapFacet(t *testing.T) {
+	dir := t.TempDir()
+	sectors := [][]byte{
+		testFacetSector(func(x, y int) (uint16, int8, []StaticItem) {
+			if x == 3 && y == 60 {
+				return 0x03, 0, []StaticItem{{0x00, 0x01, 0, 0, 5, 0x21, 0}}
+			}
+			return 0x03, 0, nil
+		}),
+		testFacetSector(func(x, y int) (uint16, int8, []StaticItem) {
+			if x == 6 && y == 5 {
+				return 0xA8, -4, nil
+			}
+			return 0x04, 0, nil
+		}),
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, uop.WriteNamed(&buffer, facetSectorName(0), sectors))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "facet0.uop"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	m, err := sdk.Map(0)
+	require.NoError(t, err)
+	width, height := m.Size()
+	assert.Equal(t, 128, width)
+	assert.Equal(t, 64, height)
+
+	tile, err := m.TileAt(70, 5)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0xA8), tile.ID)
+	assert.Equal(t, int8(-4), tile.Z)
+
+	tile, err = m.TileAt(71, 63)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x04), tile.ID)
+
+	tile, err = m.TileAt(3, 60)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x03), tile.ID)
+	require.Len(t, tile.Statics, 1)
+	assert.Equal(t, uint16(0x100), tile.Statics[0].ID())
+	assert.Equal(t, uint16(0x21), tile.Statics[0].Hue())
+	x, y, z := tile.Statics[0].Location()
+	assert.Equal(t, []int{3, 4, 5}, []int{int(x), int(y), int(z)})
+
+	tile, err = m.TileAt(3, 59)
+	require.NoError(t, err)
+	assert.Empty(t, tile.Statics)
+
+	// The facet is loaded, so the map can no longer be registered
+	assert.Error(t, sdk.RegisterMap(0, 16, 8, "custom.mul"))
+}
+
+func TestDecodeFacetSector(t *testing.T) {
+	sector, err := decodeFacetSector(0, testFacetSector(func(x, y int) (uint16, int8, []StaticItem) {
+		return uint16(x*64 + y), int8(x - y), nil
+	}))
+	require.NoError(t, err)
+
+	tile, err := decodeMapTile(sector.land[1*8+2][:], 3*8+5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(13*64+19), tile.ID)
+	assert.Equal(t, int8(13-19), tile.Z)
+
+	_, err = decodeFacetSector(0, make([]byte, 100))
+	assert.Error(t, err)
+}
+
+// testFacetSector encodes a sector of a facet file, with a delimiter on every border tile
+func testFacetSector(tileAt func(x, y int) (id uint16, z int8, statics []StaticItem)) []byte {
+	var out []byte
+	for x := 0; x < facetSectorSize; x++ {
+		for y := 0; y < facetSectorSize; y++ {
+			id, z, statics := tileAt(x, y)
+			out = append(out, byte(z))
+			out = binary.LittleEndian.AppendUint16(out, id)
+
+			if x == 0 || y == 0 {
+				out = append(out, 1, 0, 0, 0x10, 0x20)
+			} else {
+				out = append(out, 0)
+			}
+
+			out = append(out, byte(len(statics)))
+			for _, static := range statics {
+				out = append(out, static[0], static[1], 0, 0, static[4], static[5], static[6])
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// meshHeightScale is the height of one elevation unit, relative to the side of a tile.
+// The client draws a tile 44 pixels wide (31 pixels a side) and an elevation unit 4
+// pixels high, so that 8 units are roughly one tile.
+const meshHeightScale = 4.0 / 31.0
+
+// ExportOBJ exports the terrain of the map covered by the rectangle (in tile
+// coordinates) as a Wavefront OBJ mesh, for 3D previews of the land. The mesh has a
+// vertex at every corner of the tiles, raised by the elevation of the tiles, with one
+// unit per tile along the X (east) and Z (south) axes and Y pointing up. The directory
+// receives name.obj, name.mtl and the textures of the tiles as PNG files in the
+// textures sub-directory; land tiles without a texture map are draped with their art.
+func (m *TileMap) ExportOBJ(dir, name string, rect image.Rectangle) error {
+	if m.sdk == nil {
+		return fmt.Errorf("ExportOBJ: map is not attached to an SDK")
+	}
+
+	rect = rect.Intersect(image.Rect(0, 0, m.width, m.height))
+	if rect.Empty() {
+		return fmt.Errorf("ExportOBJ: region is outside of the map")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "textures"), 0755); err != nil {
+		return fmt.Errorf("ExportOBJ: failed to create directory: %w", err)
+	}
+
+	// The corners on the south and east edges take the elevation of the next tiles
+	area := image.Rect(rect.Min.X, rect.Min.Y, min(rect.Max.X+1, m.width), min(rect.Max.Y+1, m.height))
+	tiles, err := m.landTiles(area)
+	if err != nil {
+		return fmt.Errorf("ExportOBJ: %w", err)
+	}
+
+	w, h := rect.Dx(), rect.Dy()
+	cornerZ := func(x, y int) int8 {
+		x, y = min(x, area.Dx()-1), min(y, area.Dy()-1)
+		return tiles[y*area.Dx()+x].Z
+	}
+
+	// Group the faces by material, exporting the image of each material on first use
+	type meshMaterial struct {
+		name     string // Name of the material, empty if the tile has no image
+		textured bool   // Whether the image is a square texture rather than the land art
+	}
+
+	faces := make(map[string][][2]int) // Material to the tiles and their kind of mapping
+	materials := make(map[uint16]meshMaterial)
+	material := func(id uint16) (meshMaterial, error) {
+		if mtl, ok := materials[id]; ok {
+			return mtl, nil
+		}
+
+		land, err := m.sdk.Land(int(id))
+		if err != nil || land == nil {
+			materials[id] = meshMaterial{}
+			return meshMaterial{}, nil
+		}
+
+		mtl := meshMaterial{name: fmt.Sprintf("land_%04X", id)}
+		img := land.Image
+		if land.LandInfo != nil && land.TextureID != 0 {
+			if texture, err := m.sdk.Texture(int(land.TextureID)); err == nil && texture != nil && texture.Image != nil {
+				mtl = meshMaterial{name: fmt.Sprintf("tex_%04X", land.TextureID), textured: true}
+				img = texture.Image
+			}
+		}
+
+		if img == nil || img.Bounds().Empty() {
+			mtl = meshMaterial{}
+		} else if err := writePNG(filepath.Join(dir, "textures", mtl.name+".png"), img); err != nil {
+			return meshMaterial{}, err
+		}
+
+		materials[id] = mtl
+		return mtl, nil
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			mtl, err := material(tiles[y*area.Dx()+x].ID)
+			switch {
+			case err != nil:
+				return fmt.Errorf("ExportOBJ: %w", err)
+			case mtl.name == "":
+				continue // Nothing to drape over the tile
+			case mtl.textured:
+				faces[mtl.name] = append(faces[mtl.name], [2]int{y*w + x, 0})
+			default:
+				faces[mtl.name] = append(faces[mtl.name], [2]int{y*w + x, 1})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(faces))
+	for mtl := range faces {
+		names = append(names, mtl)
+	}
+	slices.Sort(names)
+
+	if err := writeMTL(filepath.Join(dir, name+".mtl"), names); err != nil {
+		return fmt.Errorf("ExportOBJ: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, name+".obj"))
+	if err != nil {
+		return fmt.Errorf("ExportOBJ: %w", err)
+	}
+
+	out := bufio.NewWriter(f)
+	fmt.Fprintf(out, "mtllib %s.mtl\n", name)
+	for y := 0; y <= h; y++ {
+		for x := 0; x <= w; x++ {
+			fmt.Fprintf(out, "v %d %.4f %d\n", x, float64(cornerZ(x, y))*meshHeightScale, y)
+		}
+	}
+
+	// Texture coordinates of the top, right, bottom and left corners of a tile, first
+	// for a square texture and then for the diamond of the land art
+	fmt.Fprint(out, "vt 0 1\nvt 1 1\nvt 1 0\nvt 0 0\n")
+	fmt.Fprint(out, "vt 0.5 1\nvt 1 0.5\nvt 0.5 0\nvt 0 0.5\n")
+
+	for _, material := range names {
+		fmt.Fprintf(out, "usemtl %s\n", material)
+		for _, face := range faces[material] {
+			x, y := face[0]%w, face[0]/w
+			top, right := y*(w+1)+x+1, y*(w+1)+x+2
+			left, bottom := (y+1)*(w+1)+x+1, (y+1)*(w+1)+x+2
+			uv := face[1]*4 + 1
+			fmt.Fprintf(out, "f %d/%d %d/%d %d/%d\n", top, uv, left, uv+3, bottom, uv+2)
+			fmt.Fprintf(out, "f %d/%d %d/%d %d/%d\n", top, uv, bottom, uv+2, right, uv+1)
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("ExportOBJ: %w", err)
+	}
+	return f.Close()
+}
+
+// writeMTL writes the material library, with a diffuse texture for every material
+func writeMTL(path string, materials []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(f)
+	for _, name := range materials {
+		fmt.Fprintf(out, "newmtl %s\nKa 1 1 1\nKd 1 1 1\nmap_Kd textures/%s.png\n\n", name, name)
+	}
+
+	if err := out.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTileMap_ExportOBJ(t *testing.T) {
+	m := openTestWorld(t, nil, nil, nil)
+
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{
+		{ID: 0, Image: bitmap.NewARGB1555(image.Rect(0, 0, 44, 44))},
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "artidx.mul"), artIdx.Bytes(), 0644))
+	require.NoError(t, m.SetTile(3, 2, 0, 31))
+	require.NoError(t, m.SetTile(4, 4, 0xA8, 0)) // No art
+
+	dir := t.TempDir()
+	require.NoError(t, m.ExportOBJ(dir, "terrain", image.Rect(2, 2, 5, 5)))
+
+	data, err := os.ReadFile(filepath.Join(dir, "terrain.obj"))
+	require.NoError(t, err)
+
+	var vertices, faces []string
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case strings.HasPrefix(line, "v "):
+			vertices = append(vertices, line)
+		case strings.HasPrefix(line, "f "):
+			faces = append(faces, line)
+		}
+	}
+
+	assert.True(t, strings.HasPrefix(string(data), "mtllib terrain.mtl\n"))
+	assert.Contains(t, string(data), "usemtl land_0000\n")
+	assert.Len(t, vertices, 16)
+	assert.Len(t, faces, 2*(9-1))
+	assert.Equal(t, "v 1 4.0000 0", vertices[1])
+	assert.Equal(t, "f 1/5 5/8 6/7", faces[0])
+
+	mtl, err := os.ReadFile(filepath.Join(dir, "terrain.mtl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(mtl), "map_Kd textures/land_0000.png")
+	assert.FileExists(t, filepath.Join(dir, "textures", "land_0000.png"))
+}
@@ -0,0 +1,108 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+)
+
+// OverviewOptions configures how TileMap.Overview draws the radar-color overview.
+type OverviewOptions struct {
+	Shading bool         // Shade the land by its elevation difference with the north-west neighbour
+	Season  *SeasonTable // Tile swaps of the season to draw, nil for the art as-is
+}
+
+// Overview renders the tiles of the map covered by the rectangle (in tile coordinates)
+// as a radar-color overview (1 pixel per tile), like ImageRect. With shading enabled,
+// tiles rising from their north-west neighbour are lightened and falling tiles are
+// darkened, which makes hills and cliffs visible as in the map view of UOFiddler.
+func (m *TileMap) Overview(r image.Rectangle, opts OverviewOptions) (image.Image, error) {
+	r = r.Intersect(image.Rect(0, 0, m.width, m.height))
+	if r.Empty() {
+		return nil, fmt.Errorf("map.Overview: region is outside of the map")
+	}
+
+	colors, err := m.sdk.radarTable()
+	if err != nil {
+		return nil, fmt.Errorf("map.Overview: %w", err)
+	}
+
+	// Shading needs the elevation of the row above and the column to the left
+	area := r
+	if opts.Shading {
+		area.Min = image.Pt(max(r.Min.X-1, 0), max(r.Min.Y-1, 0))
+	}
+
+	tiles, err := m.landTiles(area)
+	if err != nil {
+		return nil, fmt.Errorf("map.Overview: %w", err)
+	}
+
+	img := bitmap.NewARGB1555(image.Rect(0, 0, r.Dx(), r.Dy()))
+	at := func(x, y int) LandTile {
+		return tiles[(y-area.Min.Y)*area.Dx()+(x-area.Min.X)]
+	}
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			tile := at(x, y)
+			id := opts.Season.land(tile.ID)
+			if int(id) >= len(colors) {
+				continue
+			}
+
+			color := colors[id]
+			if opts.Shading {
+				neighbour := at(max(x-1, area.Min.X), max(y-1, area.Min.Y))
+				color = shadeARGB1555(color, int(tile.Z)-int(neighbour.Z))
+			}
+
+			setARGB1555(img, x-r.Min.X, y-r.Min.Y, color)
+		}
+	}
+	return img, nil
+}
+
+// landTiles reads the land tiles covered by the rectangle, in row-major order
+func (m *TileMap) landTiles(r image.Rectangle) ([]LandTile, error) {
+	tiles := make([]LandTile, r.Dx()*r.Dy())
+	block := make([]byte, mapBlockSize)
+	for blockX := r.Min.X / 8; blockX <= (r.Max.X-1)/8; blockX++ {
+		for blockY := r.Min.Y / 8; blockY <= (r.Max.Y-1)/8; blockY++ {
+			if err := m.readLandBlock(block, blockX*(m.height/8)+blockY); err != nil {
+				return nil, err
+			}
+
+			for i := 0; i < 64; i++ {
+				x, y := blockX*8+i%8, blockY*8+i/8
+				if !image.Pt(x, y).In(r) {
+					continue
+				}
+
+				tiles[(y-r.Min.Y)*r.Dx()+(x-r.Min.X)] = LandTile{
+					ID: binary.LittleEndian.Uint16(block[4+i*3:]),
+					Z:  int8(block[4+i*3+2]),
+				}
+			}
+		}
+	}
+	return tiles, nil
+}
+
+// shadeARGB1555 lightens (positive slope) or darkens (negative slope) the 15-bit color
+// by up to half of its intensity, proportionally to the elevation difference
+func shadeARGB1555(value uint16, slope int) uint16 {
+	const steps = 20
+	factor := steps + max(-steps/2, min(steps/2, slope))
+	shade := func(channel uint16) uint16 {
+		return uint16(min(31, int(channel)*factor/steps))
+	}
+
+	r, g, b := (value>>10)&0x1F, (value>>5)&0x1F, value&0x1F
+	return shade(r)<<10 | shade(g)<<5 | shade(b)
+}
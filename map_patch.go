@@ -0,0 +1,229 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"codeberg.org/go-mmap/mmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
+	"github.com/kelindar/ultima-sdk/internal/uofile"
+)
+
+const mapBlockSize = 196 // 4-byte header followed by 64 tiles of 3 bytes
+
+// mapPatches holds the difference files of the classic clients, which replace land and
+// static blocks of the map. The lists (mapdiflX.mul, stadiflX.mul) are indexed by block,
+// each entry holding the position of the replacement within the data files (mapdifX.mul,
+// stadifX.mul with its stadifiX.mul index), which are read from the file on access.
+type mapPatches struct {
+	landList    *uofile.File // Position of the replacement of every patched land block, if any
+	land        *uofile.File // Replacement land blocks, including the block header
+	staticsList *uofile.File // Position of the replacement of every patched static block, if any
+	statics     *uofile.File // Replacement statics, an empty entry removing all of them
+}
+
+// loadMapPatches loads the difference files of the given map. Missing or empty files are
+// not an error, in which case the map simply has no patches.
+func (s *SDK) loadMapPatches(mapID int) (*mapPatches, error) {
+	patches := new(mapPatches)
+	for _, file := range []struct {
+		dst     **uofile.File
+		names   []string
+		options []uofile.Option
+	}{
+		{&patches.landList, []string{fmt.Sprintf("mapdifl%d.mul", mapID)}, []uofile.Option{uofile.WithDecodeMUL(decodeMapPatchList)}},
+		{&patches.land, []string{fmt.Sprintf("mapdif%d.mul", mapID)}, []uofile.Option{uofile.WithDecodeMUL(decodeMapPatchBlocks)}},
+		{&patches.staticsList, []string{fmt.Sprintf("stadifl%d.mul", mapID)}, []uofile.Option{uofile.WithDecodeMUL(decodeMapPatchList)}},
+		{&patches.statics, []string{fmt.Sprintf("stadif%d.mul", mapID), fmt.Sprintf("stadifi%d.mul", mapID)}, nil},
+	} {
+		loaded, err := s.loadMapPatch(file.names, file.options...)
+		if err != nil {
+			return nil, err
+		}
+		*file.dst = loaded
+	}
+	return patches, nil
+}
+
+// landBlock reads the replacement of a land block, if the block is patched
+func (p *mapPatches) landBlock(blockIndex int) ([]byte, bool) {
+	position, ok := patchPosition(p.landList, blockIndex)
+	if !ok || p.land == nil {
+		return nil, false
+	}
+
+	entry, err := p.land.Entry(position)
+	if err != nil || entry == nil || entry.Len() < mapBlockSize {
+		return nil, false
+	}
+
+	block := make([]byte, mapBlockSize)
+	if _, err := entry.ReadAt(block, 0); err != nil {
+		return nil, false
+	}
+	return block, true
+}
+
+// staticsBlock reads the replacement statics of a block, if the block is patched. The
+// statics are nil if the patch removes all of them.
+func (p *mapPatches) staticsBlock(blockIndex int) ([]StaticItem, bool) {
+	position, ok := patchPosition(p.staticsList, blockIndex)
+	if !ok || p.statics == nil {
+		return nil, false
+	}
+
+	entry, err := p.statics.Entry(position)
+	switch {
+	case err != nil:
+		return nil, false // No replacement, keep the original statics
+	case entry == nil:
+		return nil, true
+	}
+
+	data := make([]byte, entry.Len())
+	if _, err := entry.ReadAt(data, 0); err != nil {
+		return nil, false // Corrupt entry, keep the original statics
+	}
+
+	statics := make([]StaticItem, 0, len(data)/7)
+	for j := 0; j+7 <= len(data); j += 7 {
+		statics = append(statics, StaticItem(data[j:j+7]))
+	}
+	return statics, true
+}
+
+// patchPosition returns the position of the replacement of a block within the data of
+// the patches, if the block is listed
+func patchPosition(list *uofile.File, blockIndex int) (uint32, bool) {
+	if list == nil {
+		return 0, false
+	}
+
+	entry, err := list.Entry(uint32(blockIndex))
+	if err != nil || entry == nil {
+		return 0, false
+	}
+	return uint32(entry.Extra()), true
+}
+
+// decodeMapPatchList indexes a list of patched blocks (mapdiflX.mul, stadiflX.mul) by
+// block, each entry holding the position of the block in the list in its extra field.
+// Blocks listed several times are replaced by their last patch.
+func decodeMapPatchList(file *mmap.File, add mul.AddFn) error {
+	var block [4]byte
+	for offset := 0; offset+4 <= file.Len(); offset += 4 {
+		if _, err := file.ReadAt(block[:], int64(offset)); err != nil {
+			return err
+		}
+
+		add(binary.LittleEndian.Uint32(block[:]), uint32(offset), 4, uint32(offset/4), nil)
+	}
+	return nil
+}
+
+// decodeMapPatchBlocks indexes the replacement land blocks of mapdifX.mul by position
+func decodeMapPatchBlocks(file *mmap.File, add mul.AddFn) error {
+	for offset := 0; offset+mapBlockSize <= file.Len(); offset += mapBlockSize {
+		add(uint32(offset/mapBlockSize), uint32(offset), mapBlockSize, 0, nil)
+	}
+	return nil
+}
+
+// MapPatchFiles holds the destinations of the difference files of a map, as read by
+// the classic clients.
+type MapPatchFiles struct {
+	LandList     io.Writer // mapdiflX.mul, the indices of the patched land blocks
+	Land         io.Writer // mapdifX.mul, the replacement land blocks
+	StaticsList  io.Writer // stadiflX.mul, the indices of the patched static blocks
+	StaticsIndex io.Writer // stadifiX.mul, the index of the replacement statics
+	Statics      io.Writer // stadifX.mul, the replacement statics
+}
+
+// WritePatches compares the map, including any modifications and enabled patches, with
+// the base map and writes the blocks which differ as difference files, so that a small
+// patch can be distributed instead of the whole map. Both maps must have the same size.
+// It returns the number of land and static blocks written.
+func (m *TileMap) WritePatches(base *TileMap, files MapPatchFiles) (land, statics int, err error) {
+	if m.width != base.width || m.height != base.height {
+		return 0, 0, fmt.Errorf("WritePatches: map size %dx%d differs from the base %dx%d",
+			m.width, m.height, base.width, base.height)
+	}
+
+	var (
+		ours, theirs = make([]byte, mapBlockSize), make([]byte, mapBlockSize)
+		index        = make([]byte, 4)
+		writer       = mul.NewWriter(files.Statics, files.StaticsIndex)
+		buffer       []byte
+	)
+
+	blockCount := (m.width / 8) * (m.height / 8)
+	for blockIndex := 0; blockIndex < blockCount; blockIndex++ {
+		binary.LittleEndian.PutUint32(index, uint32(blockIndex))
+
+		// Land blocks are replaced as a whole, including their header
+		if err := m.readLandBlock(ours, blockIndex); err != nil {
+			return land, statics, fmt.Errorf("WritePatches: block %d: %w", blockIndex, err)
+		}
+		if err := base.readLandBlock(theirs, blockIndex); err != nil {
+			return land, statics, fmt.Errorf("WritePatches: base block %d: %w", blockIndex, err)
+		}
+
+		if !bytes.Equal(ours, theirs) {
+			if _, err := files.LandList.Write(index); err != nil {
+				return land, statics, fmt.Errorf("WritePatches: %w", err)
+			}
+			if _, err := files.Land.Write(ours); err != nil {
+				return land, statics, fmt.Errorf("WritePatches: %w", err)
+			}
+			land++
+		}
+
+		// Statics are replaced as a whole too, an empty entry removing all of them
+		current, err := m.readStatics(blockIndex)
+		if err != nil {
+			return land, statics, fmt.Errorf("WritePatches: block %d: %w", blockIndex, err)
+		}
+		original, err := base.readStatics(blockIndex)
+		if err != nil {
+			return land, statics, fmt.Errorf("WritePatches: base block %d: %w", blockIndex, err)
+		}
+
+		if equalStatics(current, original) {
+			continue
+		}
+
+		buffer = buffer[:0]
+		for _, item := range current {
+			buffer = append(buffer, item...)
+		}
+
+		if _, err := files.StaticsList.Write(index); err != nil {
+			return land, statics, fmt.Errorf("WritePatches: %w", err)
+		}
+		if err := writer.Write(uint32(statics), buffer, 0); err != nil {
+			return land, statics, fmt.Errorf("WritePatches: %w", err)
+		}
+		statics++
+	}
+
+	return land, statics, nil
+}
+
+// equalStatics returns whether both lists hold the same statics, in the same order
+func equalStatics(a, b []StaticItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,385 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Point3D is a location in the world, with its elevation.
+type Point3D struct {
+	X, Y, Z int
+}
+
+// Surface returns the elevation of the top walkable surface at the world coordinates.
+// The land counts with its average elevation over the corners of the tile, as the
+// server computes it, unless it is impassable; statics count when flagged as surface
+// or bridge (and not impassable), at their elevation plus their tiledata height. The
+// returned ok is false if there is no walkable surface at the location.
+func (m *TileMap) Surface(x, y int) (z int, ok bool, err error) {
+	if m.sdk == nil {
+		return 0, false, fmt.Errorf("Surface: map is not attached to an SDK")
+	}
+
+	tile, err := m.TileAt(x, y)
+	if err != nil {
+		return 0, false, fmt.Errorf("Surface: %w", err)
+	}
+
+	// The land is walkable unless it is impassable or one of the invisible tiles
+	if land, err := m.sdk.landInfo(int(tile.ID)); err == nil && land.Flags&TileFlagImpassable == 0 && !ignoredLand(tile.ID) {
+		if z, err = m.averageZ(x, y); err != nil {
+			return 0, false, fmt.Errorf("Surface: %w", err)
+		}
+		ok = true
+	}
+
+	index, err := m.sdk.itemIndex()
+	if err != nil {
+		return 0, false, fmt.Errorf("Surface: %w", err)
+	}
+
+	for _, static := range tile.Statics {
+		id := int(static.ID())
+		if id >= len(index.items) {
+			continue
+		}
+
+		info := index.items[id]
+		if !(info.Surface() || info.Bridge()) || info.Impassable() {
+			continue
+		}
+
+		_, _, sz := static.Location()
+		if top := int(sz) + info.CalcHeight(); !ok || top > z {
+			z, ok = top, true
+		}
+	}
+
+	return z, ok, nil
+}
+
+// LineOfSight returns whether the point to is visible from the point from. The line
+// between both points is walked tile by tile, and the sight is blocked by the land if
+// the line passes below its surface, or by a static flagged as NoShoot (but not as a
+// Window) if the line passes through its height. The tiles of both endpoints are not
+// checked, so that an observer or a target standing among statics remains visible.
+func (m *TileMap) LineOfSight(from, to Point3D) (bool, error) {
+	if m.sdk == nil {
+		return false, fmt.Errorf("LineOfSight: map is not attached to an SDK")
+	}
+
+	for _, p := range [2]Point3D{from, to} {
+		if p.X < 0 || p.Y < 0 || p.X >= m.width || p.Y >= m.height {
+			return false, fmt.Errorf("LineOfSight: coordinates out of bounds (%d,%d)", p.X, p.Y)
+		}
+	}
+
+	index, err := m.sdk.itemIndex()
+	if err != nil {
+		return false, fmt.Errorf("LineOfSight: %w", err)
+	}
+
+	dx, dy, dz := to.X-from.X, to.Y-from.Y, to.Z-from.Z
+	steps := max(abs(dx), abs(dy))
+	for i := 1; i < steps; i++ {
+		t := float64(i) / float64(steps)
+		x := from.X + int(math.Round(float64(dx)*t))
+		y := from.Y + int(math.Round(float64(dy)*t))
+		z := from.Z + int(math.Round(float64(dz)*t))
+
+		tile, err := m.TileAt(x, y)
+		if err != nil {
+			return false, fmt.Errorf("LineOfSight: %w", err)
+		}
+
+		// The line passes below the surface of the land
+		if !ignoredLand(tile.ID) {
+			landZ, err := m.averageZ(x, y)
+			if err != nil {
+				return false, fmt.Errorf("LineOfSight: %w", err)
+			}
+
+			if z < landZ {
+				return false, nil
+			}
+		}
+
+		// The line passes through a static which blocks the sight
+		for _, static := range tile.Statics {
+			id := int(static.ID())
+			if id >= len(index.items) {
+				continue
+			}
+
+			info := index.items[id]
+			if info.Flags&TileFlagNoShoot == 0 || info.Flags&TileFlagWindow != 0 {
+				continue
+			}
+
+			if _, _, sz := static.Location(); z >= int(sz) && z < int(sz)+info.CalcHeight() {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// MapReport summarizes the content of a map and the problems found in it.
+type MapReport struct {
+	Blocks           int      // Number of blocks of the map
+	Statics          int      // Number of statics placed on the map
+	BrokenBlocks     []int    // Indices of the blocks whose land or statics cannot be read
+	InvalidLand      int      // Number of land tiles without art
+	InvalidStatics   int      // Number of statics without tile data or art
+	MisplacedStatics int      // Number of statics positioned outside of their 8x8 block
+	UnindexedStatics int      // Number of statics in the statics file which no block's index entry covers
+	InvalidLandIDs   []uint16 // Distinct land tile IDs without art
+	InvalidItemIDs   []uint16 // Distinct static item IDs without tile data or art
+}
+
+// Valid returns whether no problem was found in the map.
+func (r *MapReport) Valid() bool {
+	return len(r.BrokenBlocks) == 0 && r.InvalidLand == 0 && r.InvalidStatics == 0 && r.MisplacedStatics == 0 && r.UnindexedStatics == 0
+}
+
+// Validate reads every block of the map, including any modifications and enabled
+// patches, and reports the number of blocks and statics along with the tiles which
+// reference missing tile data or art, the statics positioned outside of their block and
+// the statics stored in the statics file outside of the ranges its index refers to.
+// This is a quick sanity check for hand-edited or converted maps.
+func (m *TileMap) Validate() (*MapReport, error) {
+	if m.sdk == nil {
+		return nil, fmt.Errorf("Validate: map is not attached to an SDK")
+	}
+
+	index, err := m.sdk.itemIndex()
+	if err != nil {
+		return nil, fmt.Errorf("Validate: %w", err)
+	}
+
+	art, err := m.sdk.loadArt()
+	if err != nil {
+		return nil, fmt.Errorf("Validate: %w", err)
+	}
+
+	// Validity of each tile ID, checked lazily: 0 unknown, 1 valid, 2 invalid
+	landState := make([]byte, 0x10000)
+	itemState := make([]byte, 0x10000)
+	validLand := func(id uint16) bool {
+		if landState[id] == 0 {
+			landState[id] = 2
+			if int(id) < landTileMax && hasArtEntry(art, int(id)) {
+				landState[id] = 1
+			}
+		}
+		return landState[id] == 1
+	}
+
+	validItem := func(id uint16) bool {
+		if itemState[id] == 0 {
+			itemState[id] = 2
+			if int(id) < len(index.items) && hasArtEntry(art, int(id)+staticTileMinID) {
+				itemState[id] = 1
+			}
+		}
+		return itemState[id] == 1
+	}
+
+	report := &MapReport{Blocks: (m.width / 8) * (m.height / 8)}
+	land := make([]byte, mapBlockSize)
+	for blockIndex := 0; blockIndex < report.Blocks; blockIndex++ {
+		if err := m.readLandBlock(land, blockIndex); err != nil {
+			report.BrokenBlocks = append(report.BrokenBlocks, blockIndex)
+			continue
+		}
+
+		statics, err := m.readStatics(blockIndex)
+		if err != nil {
+			report.BrokenBlocks = append(report.BrokenBlocks, blockIndex)
+			continue
+		}
+
+		for i := 0; i < 64; i++ {
+			if !validLand(binary.LittleEndian.Uint16(land[4+i*3:])) {
+				report.InvalidLand++
+			}
+		}
+
+		report.Statics += len(statics)
+		for _, static := range statics {
+			if !validItem(static.ID()) {
+				report.InvalidStatics++
+			}
+
+			if x, y, _ := static.Location(); x >= 8 || y >= 8 {
+				report.MisplacedStatics++
+			}
+		}
+	}
+
+	report.UnindexedStatics = m.unindexedStatics(report.Blocks)
+	for id := range landState {
+		if landState[id] == 2 {
+			report.InvalidLandIDs = append(report.InvalidLandIDs, uint16(id))
+		}
+		if itemState[id] == 2 {
+			report.InvalidItemIDs = append(report.InvalidItemIDs, uint16(id))
+		}
+	}
+
+	return report, nil
+}
+
+// unindexedStatics counts the static records of the statics file which are not covered
+// by the index entry of any block of the map, such as leftovers of hand-edited maps.
+func (m *TileMap) unindexedStatics(blocks int) int {
+	if m.staticsFile == nil {
+		return 0
+	}
+
+	size := m.staticsFile.Size()
+	ranges := make([][2]int, 0, blocks)
+	for blockIndex := 0; blockIndex < blocks; blockIndex++ {
+		if offset, length, ok := m.staticsFile.Extent(uint32(blockIndex)); ok {
+			ranges = append(ranges, [2]int{int(offset), int(offset) + int(length)})
+		}
+	}
+
+	// Merge the overlapping ranges and sum up the bytes within the file they cover
+	slices.SortFunc(ranges, func(a, b [2]int) int { return a[0] - b[0] })
+	covered, end := 0, 0
+	for _, r := range ranges {
+		lo, hi := max(r[0], end), min(r[1], size)
+		if hi > lo {
+			covered += hi - lo
+			end = hi
+		}
+	}
+
+	return (size - covered) / 7
+}
+
+// TileOccurrence is a land tile or a static found on the map.
+type TileOccurrence struct {
+	X, Y   int    // World coordinates
+	Z      int8   // Elevation
+	ID     uint16 // Land tile or static item ID
+	Static bool   // Whether this is a static rather than a land tile
+}
+
+// FindTiles scans the land and statics of the whole map in parallel, including any
+// modifications and enabled patches, and returns every place where one of the given
+// IDs appears. Since land tiles and statics have distinct ID spaces, each ID is matched
+// against both, and the Static field of the occurrence tells them apart. Occurrences
+// are ordered by block column, then block and tile.
+func (m *TileMap) FindTiles(ids ...uint16) ([]TileOccurrence, error) {
+	wanted := make([]bool, 0x10000)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	type column struct {
+		found []TileOccurrence
+		err   error
+	}
+
+	// Each job scans a column of blocks, which are adjacent in the map file
+	blocksDown := m.height / 8
+	scan := func(blockX int) (column, bool) {
+		var out column
+		land := make([]byte, mapBlockSize)
+		for blockY := 0; blockY < blocksDown; blockY++ {
+			blockIndex := blockX*blocksDown + blockY
+			if err := m.readLandBlock(land, blockIndex); err != nil {
+				return column{err: err}, true
+			}
+
+			for i := 0; i < 64; i++ {
+				if id := binary.LittleEndian.Uint16(land[4+i*3:]); wanted[id] {
+					out.found = append(out.found, TileOccurrence{
+						X:  blockX*8 + i%8,
+						Y:  blockY*8 + i/8,
+						Z:  int8(land[4+i*3+2]),
+						ID: id,
+					})
+				}
+			}
+
+			statics, err := m.readStatics(blockIndex)
+			if err != nil {
+				return column{err: err}, true
+			}
+
+			for _, static := range statics {
+				if id := static.ID(); wanted[id] {
+					x, y, z := static.Location()
+					out.found = append(out.found, TileOccurrence{
+						X:      blockX*8 + int(x),
+						Y:      blockY*8 + int(y),
+						Z:      z,
+						ID:     id,
+						Static: true,
+					})
+				}
+			}
+		}
+		return out, len(out.found) > 0
+	}
+
+	var found []TileOccurrence
+	for result := range decodeParallel(m.width/8, 0, scan) {
+		if result.err != nil {
+			return nil, fmt.Errorf("FindTiles: %w", result.err)
+		}
+		found = append(found, result.found...)
+	}
+	return found, nil
+}
+
+// averageZ returns the average elevation of the land tile over its four corners, along
+// the diagonal with the smallest difference, as the server does for sloped terrain
+func (m *TileMap) averageZ(x, y int) (int, error) {
+	var corners [4]int // Top, left, right and bottom
+	for i, p := range [4][2]int{{x, y}, {x, y + 1}, {x + 1, y}, {x + 1, y + 1}} {
+		tile, err := m.TileAt(min(p[0], m.width-1), min(p[1], m.height-1))
+		if err != nil {
+			return 0, err
+		}
+		corners[i] = int(tile.Z)
+	}
+
+	top, left, right, bottom := corners[0], corners[1], corners[2], corners[3]
+	if abs(top-bottom) > abs(left-right) {
+		return floorDiv(left+right, 2), nil
+	}
+	return floorDiv(top+bottom, 2), nil
+}
+
+// ignoredLand returns whether the land tile is one of the invisible tiles (such as the
+// black void under caves), which the server ignores for movement
+func ignoredLand(id uint16) bool {
+	return id == 0x2 || id == 0x1DB || (id >= 0x1AE && id <= 0x1B5)
+}
+
+// abs returns the absolute value of the integer
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,244 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
+	"github.com/kelindar/ultima-sdk/internal/uofile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestTiledata writes a tiledata.mul with the given land flags and item data
+func writeTestTiledata(t *testing.T, dir string, lands map[int]TileFlag, items map[int]ItemInfo) {
+	var data []byte
+	for id := 0; id < 0x4000; id++ {
+		if id%32 == 0 {
+			data = append(data, 0, 0, 0, 0)
+		}
+
+		entry := make([]byte, 30)
+		binary.LittleEndian.PutUint64(entry, uint64(lands[id]))
+		data = append(data, entry...)
+	}
+
+	count := 0
+	for id := range items {
+		count = max(count, id+1)
+	}
+
+	for id := 0; id < (count+31)/32*32; id++ {
+		if id%32 == 0 {
+			data = append(data, 0, 0, 0, 0)
+		}
+
+		info := items[id]
+		entry := make([]byte, 41)
+		binary.LittleEndian.PutUint64(entry, uint64(info.Flags))
+		entry[9] = info.Quality
+		binary.LittleEndian.PutUint16(entry[14:], uint16(info.AnimationID))
+		entry[20] = info.Height
+		copy(entry[21:], info.Name)
+		data = append(data, entry...)
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tiledata.mul"), data, 0644))
+}
+
+// openTestWorld opens a 16x16 test map attached to an SDK with the given tile data
+func openTestWorld(t *testing.T, statics map[int][]StaticItem, lands map[int]TileFlag, items map[int]ItemInfo) *TileMap {
+	dir := t.TempDir()
+	writeTestTiledata(t, dir, lands, items)
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { sdk.Close() })
+
+	m := openTestMap(t, dir, statics)
+	m.sdk = sdk
+	return m
+}
+
+func TestTileMap_Surface(t *testing.T) {
+	m := openTestWorld(t, map[int][]StaticItem{
+		0: {
+			NewStaticItem(0x10, 1, 1, 5, 0),  // Table
+			NewStaticItem(0x11, 1, 1, 20, 0), // Wall, not walkable
+			NewStaticItem(0x12, 2, 2, 0, 0),  // Bridge
+		},
+	}, map[int]TileFlag{
+		0xA8: TileFlagImpassable | TileFlagWet,
+	}, map[int]ItemInfo{
+		0x10: {Flags: TileFlagSurface, Height: 6},
+		0x11: {Flags: TileFlagSurface | TileFlagImpassable, Height: 20},
+		0x12: {Flags: TileFlagBridge | TileFlagSurface, Height: 10},
+	})
+
+	// Flat land
+	z, ok, err := m.Surface(5, 5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 0, z)
+
+	// Sloped land is averaged over its corners
+	require.NoError(t, m.SetTile(6, 5, 0x3, 10))
+	require.NoError(t, m.SetTile(6, 6, 0x3, 10))
+	z, ok, err = m.Surface(5, 5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5, z)
+
+	// Statics stack on top of the land, ignoring the impassable ones
+	z, ok, err = m.Surface(1, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 11, z)
+
+	// Bridges count half of their height
+	z, _, err = m.Surface(2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, z)
+
+	// Impassable land has no surface
+	require.NoError(t, m.SetTile(9, 9, 0xA8, 0))
+	_, ok, err = m.Surface(9, 9)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = m.Surface(-1, 0)
+	assert.Error(t, err)
+}
+
+func TestTileMap_LineOfSight(t *testing.T) {
+	m := openTestWorld(t, map[int][]StaticItem{
+		0: {
+			NewStaticItem(0x20, 4, 0, 0, 0), // Wall at (4, 0)
+			NewStaticItem(0x21, 4, 2, 0, 0), // Window at (4, 2)
+			NewStaticItem(0x22, 4, 4, 0, 0), // Table at (4, 4)
+		},
+	}, nil, map[int]ItemInfo{
+		0x20: {Flags: TileFlagNoShoot | TileFlagImpassable, Height: 20},
+		0x21: {Flags: TileFlagNoShoot | TileFlagWindow, Height: 20},
+		0x22: {Flags: TileFlagSurface, Height: 6},
+	})
+
+	tests := []struct {
+		name     string
+		from, to Point3D
+		visible  bool
+	}{
+		{"open ground", Point3D{0, 6, 0}, Point3D{7, 6, 0}, true},
+		{"same tile", Point3D{1, 1, 0}, Point3D{1, 1, 0}, true},
+		{"wall", Point3D{0, 0, 10}, Point3D{7, 0, 10}, false},
+		{"over the wall", Point3D{0, 0, 30}, Point3D{7, 0, 30}, true},
+		{"window", Point3D{0, 2, 10}, Point3D{7, 2, 10}, true},
+		{"table", Point3D{0, 4, 2}, Point3D{7, 4, 2}, true},
+		{"underground", Point3D{0, 6, -10}, Point3D{7, 6, -10}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			visible, err := m.LineOfSight(tc.from, tc.to)
+			require.NoError(t, err)
+			assert.Equal(t, tc.visible, visible)
+		})
+	}
+
+	// Sight blocked by a hill
+	require.NoError(t, m.SetTile(3, 7, 0x3, 40))
+	require.NoError(t, m.SetTile(4, 7, 0x3, 40))
+	visible, err := m.LineOfSight(Point3D{0, 7, 0}, Point3D{7, 7, 0})
+	require.NoError(t, err)
+	assert.False(t, visible)
+
+	_, err = m.LineOfSight(Point3D{0, 0, 0}, Point3D{16, 0, 0})
+	assert.Error(t, err)
+}
+
+func TestTileMap_Validate(t *testing.T) {
+	m := openTestWorld(t, map[int][]StaticItem{
+		0: {
+			NewStaticItem(0x10, 1, 1, 0, 0),
+			NewStaticItem(0x11, 2, 2, 0, 0), // No art
+			NewStaticItem(0x40, 3, 3, 0, 0), // No tile data
+		},
+		2: {
+			NewStaticItem(0x10, 9, 1, 0, 0), // Outside of its block
+		},
+	}, nil, map[int]ItemInfo{
+		0x10: {Name: "valid"},
+		0x11: {Name: "no art"},
+	})
+
+	// Art for the land tile 0 and the item 0x10 only
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{
+		{ID: 0, Image: bitmap.NewARGB1555(image.Rect(0, 0, 44, 44))},
+		{ID: 0x4010, Image: bitmap.NewARGB1555(image.Rect(0, 0, 2, 2))},
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "artidx.mul"), artIdx.Bytes(), 0644))
+	require.NoError(t, m.SetTile(5, 5, 0x3, 0))
+
+	report, err := m.Validate()
+	require.NoError(t, err)
+	assert.False(t, report.Valid())
+	assert.Equal(t, 4, report.Blocks)
+	assert.Equal(t, 4, report.Statics)
+	assert.Empty(t, report.BrokenBlocks)
+	assert.Equal(t, 1, report.InvalidLand)
+	assert.Equal(t, 2, report.InvalidStatics)
+	assert.Equal(t, 1, report.MisplacedStatics)
+	assert.Equal(t, []uint16{0x3}, report.InvalidLandIDs)
+	assert.Equal(t, []uint16{0x11, 0x40}, report.InvalidItemIDs)
+	assert.Zero(t, report.UnindexedStatics)
+
+	// Statics indexed for a block outside of the map, followed by data no entry refers to
+	var staticsMul, staidxMul bytes.Buffer
+	w := mul.NewWriter(&staticsMul, &staidxMul)
+	require.NoError(t, w.Write(0, NewStaticItem(0x10, 1, 1, 0, 0), 0))
+	require.NoError(t, w.Write(4, NewStaticItem(0x10, 2, 2, 0, 0), 0))
+	staticsMul.Write(append(NewStaticItem(0x10, 3, 3, 0, 0), NewStaticItem(0x10, 4, 4, 0, 0)...))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "statics1.mul"), staticsMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "staidx1.mul"), staidxMul.Bytes(), 0644))
+
+	m.staticsFile = uofile.New(m.sdk.BasePath(), []string{"statics1.mul", "staidx1.mul"}, 0,
+		uofile.WithIndexLength(12), uofile.WithExtra())
+	t.Cleanup(func() { m.staticsFile.Close() })
+
+	report, err = m.Validate()
+	require.NoError(t, err)
+	assert.False(t, report.Valid())
+	assert.Equal(t, 1, report.Statics)
+	assert.Equal(t, 3, report.UnindexedStatics)
+}
+
+func TestTileMap_FindTiles(t *testing.T) {
+	m := openTestMap(t, t.TempDir(), map[int][]StaticItem{
+		1: {NewStaticItem(0xA8, 2, 3, 7, 0)},
+		2: {NewStaticItem(0x10, 1, 1, 0, 0)},
+	})
+	require.NoError(t, m.SetTile(9, 1, 0xA8, 4))
+	require.NoError(t, m.SetTile(3, 3, 0x3, 0))
+
+	found, err := m.FindTiles(0xA8, 0x3)
+	require.NoError(t, err)
+	assert.Equal(t, []TileOccurrence{
+		{X: 3, Y: 3, Z: 0, ID: 0x3},
+		{X: 2, Y: 11, Z: 7, ID: 0xA8, Static: true},
+		{X: 9, Y: 1, Z: 4, ID: 0xA8},
+	}, found)
+
+	found, err = m.FindTiles(0x1234)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
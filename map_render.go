@@ -0,0 +1,321 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"slices"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+)
+
+// RenderOptions configures how TileMap.Render draws a region of the map.
+type RenderOptions struct {
+	Textures    bool         // Stretch land textures over sloped terrain, as the client does
+	Hues        bool         // Recolor statics with their hue
+	SkipStatics bool         // Draw only the land, without any statics
+	Season      *SeasonTable // Tile swaps of the season to draw, nil for the art as-is
+}
+
+// renderItem is a single land tile or static to be drawn, in client drawing order
+type renderItem struct {
+	depth  int             // Distance from the top of the screen (x + y)
+	z      int             // Elevation
+	kind   int             // 0 for land, 1 for statics
+	order  int             // Insertion order, to keep the sort stable
+	bounds image.Rectangle // Screen area covered by the item
+	draw   func(dst *bitmap.ARGB1555, origin image.Point)
+}
+
+// Render draws the region of the map covered by the rectangle (in tile coordinates) in
+// the isometric projection of the client. Land tiles are drawn with their elevation and,
+// if enabled, their textures stretched over sloped terrain; statics are drawn on top in
+// depth and elevation order, optionally recolored with their hue.
+func (m *TileMap) Render(rect image.Rectangle, opts RenderOptions) (image.Image, error) {
+	if m.sdk == nil {
+		return nil, fmt.Errorf("Render: map is not attached to an SDK")
+	}
+
+	rect = rect.Intersect(image.Rect(0, 0, m.width, m.height))
+	if rect.Empty() {
+		return nil, fmt.Errorf("Render: region is outside of the map")
+	}
+
+	// Read the tiles, with one extra row and column for the corners of the slopes
+	w, h := rect.Dx()+1, rect.Dy()+1
+	tiles := make([]*Tile, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			tile, err := m.TileAt(min(rect.Min.X+x, m.width-1), min(rect.Min.Y+y, m.height-1))
+			if err != nil {
+				return nil, fmt.Errorf("Render: %w", err)
+			}
+			tiles[y*w+x] = tile
+		}
+	}
+
+	r := &mapRenderer{
+		sdk:      m.sdk,
+		opts:     opts,
+		lands:    make(map[uint16]*Land),
+		textures: make(map[uint16]image.Image),
+		statics:  make(map[[2]uint16]image.Image),
+	}
+
+	// Foliage is recognized by its tile data flags
+	if opts.Season != nil && opts.Season.HideFoliage && !opts.SkipStatics {
+		index, err := m.sdk.itemIndex()
+		if err != nil {
+			return nil, fmt.Errorf("Render: %w", err)
+		}
+		r.tiledata = index.items
+	}
+
+	for y := 0; y < h-1; y++ {
+		for x := 0; x < w-1; x++ {
+			corners := [4]int8{
+				tiles[y*w+x].Z,       // Top
+				tiles[y*w+x+1].Z,     // Right
+				tiles[(y+1)*w+x+1].Z, // Bottom
+				tiles[(y+1)*w+x].Z,   // Left
+			}
+
+			r.addLand(rect.Min.X+x, rect.Min.Y+y, tiles[y*w+x], corners)
+			if !opts.SkipStatics {
+				r.addStatics(rect.Min.X+x, rect.Min.Y+y, tiles[y*w+x].Statics)
+			}
+		}
+	}
+
+	return r.render()
+}
+
+// mapRenderer collects and draws the items of a map region, caching the decoded art
+type mapRenderer struct {
+	sdk      *SDK
+	opts     RenderOptions
+	items    []renderItem
+	lands    map[uint16]*Land
+	textures map[uint16]image.Image
+	statics  map[[2]uint16]image.Image
+	tiledata []ItemInfo // Tile data of the statics, to hide the foliage
+}
+
+// anchor returns the screen position of the bottom corner of a flat tile
+func anchor(x, y int) image.Point {
+	return image.Pt((x-y)*22, (x+y)*22)
+}
+
+// addLand adds a land tile, given the elevation of its four corners
+func (r *mapRenderer) addLand(x, y int, tile *Tile, corners [4]int8) {
+	id := r.opts.Season.land(tile.ID)
+	land, ok := r.lands[id]
+	if !ok {
+		land, _ = r.sdk.Land(int(id))
+		r.lands[id] = land
+	}
+
+	if land == nil || land.Image == nil {
+		return
+	}
+
+	item := renderItem{depth: x + y, z: int(tile.Z), order: len(r.items)}
+	base := anchor(x, y)
+
+	// Sloped land is drawn by stretching its texture over the corners
+	flat := corners[0] == corners[1] && corners[1] == corners[2] && corners[2] == corners[3]
+	if texture := r.texture(land); r.opts.Textures && !flat && texture != nil {
+		quad := [4]image.Point{
+			base.Add(image.Pt(0, -44-int(corners[0])*4)),
+			base.Add(image.Pt(22, -22-int(corners[1])*4)),
+			base.Add(image.Pt(0, -int(corners[2])*4)),
+			base.Add(image.Pt(-22, -22-int(corners[3])*4)),
+		}
+
+		item.bounds = image.Rectangle{Min: quad[0], Max: quad[0].Add(image.Pt(1, 1))}
+		for _, p := range quad[1:] {
+			item.bounds = item.bounds.Union(image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))})
+		}
+
+		item.draw = func(dst *bitmap.ARGB1555, origin image.Point) {
+			top, right, bottom, left := quad[0].Sub(origin), quad[1].Sub(origin), quad[2].Sub(origin), quad[3].Sub(origin)
+			drawTriangle(dst, texture, [3]image.Point{top, right, left}, [3][2]float64{{0, 0}, {1, 0}, {0, 1}})
+			drawTriangle(dst, texture, [3]image.Point{right, bottom, left}, [3][2]float64{{1, 0}, {1, 1}, {0, 1}})
+		}
+		r.items = append(r.items, item)
+		return
+	}
+
+	at := base.Add(image.Pt(-22, -44-int(tile.Z)*4))
+	item.bounds = land.Image.Bounds().Sub(land.Image.Bounds().Min).Add(at)
+	item.draw = func(dst *bitmap.ARGB1555, origin image.Point) {
+		blit(dst, land.Image, at.Sub(origin))
+	}
+	r.items = append(r.items, item)
+}
+
+// texture returns the texture mapped onto the land tile, if any
+func (r *mapRenderer) texture(land *Land) image.Image {
+	if land.LandInfo == nil || land.TextureID == 0 {
+		return nil
+	}
+
+	texture, ok := r.textures[land.TextureID]
+	if !ok {
+		if t, err := r.sdk.Texture(int(land.TextureID)); err == nil && t != nil {
+			texture = t.Image
+		}
+		r.textures[land.TextureID] = texture
+	}
+	return texture
+}
+
+// addStatics adds the statics located on a tile
+func (r *mapRenderer) addStatics(x, y int, statics []StaticItem) {
+	base := anchor(x, y)
+	for _, static := range statics {
+		id := r.opts.Season.item(static.ID())
+		if int(id) < len(r.tiledata) && r.tiledata[id].Flags&TileFlagFoliage != 0 {
+			continue // Trees lose their foliage in this season
+		}
+
+		img := r.static(id, static.Hue())
+		if img == nil {
+			continue
+		}
+
+		_, _, z := static.Location()
+		size := img.Bounds().Size()
+		at := base.Add(image.Pt(-size.X/2, -int(z)*4-size.Y))
+		r.items = append(r.items, renderItem{
+			depth:  x + y,
+			z:      int(z),
+			kind:   1,
+			order:  len(r.items),
+			bounds: image.Rectangle{Min: at, Max: at.Add(size)},
+			draw: func(dst *bitmap.ARGB1555, origin image.Point) {
+				blit(dst, img, at.Sub(origin))
+			},
+		})
+	}
+}
+
+// static returns the (optionally hued) art of a static
+func (r *mapRenderer) static(id, hue uint16) image.Image {
+	if !r.opts.Hues {
+		hue = 0
+	}
+
+	key := [2]uint16{id, hue}
+	if img, ok := r.statics[key]; ok {
+		return img
+	}
+
+	item, err := r.sdk.ItemWithHue(int(id), int(hue), false)
+	if err != nil && hue != 0 {
+		item, err = r.sdk.Item(int(id)) // Invalid hue, draw the art as-is
+	}
+
+	var img image.Image
+	if err == nil && item != nil {
+		img = item.Image
+	}
+
+	r.statics[key] = img
+	return img
+}
+
+// render draws all of the collected items in client order onto a new image
+func (r *mapRenderer) render() (image.Image, error) {
+	if len(r.items) == 0 {
+		return nil, fmt.Errorf("Render: nothing to draw")
+	}
+
+	slices.SortFunc(r.items, func(a, b renderItem) int {
+		return cmp.Or(
+			cmp.Compare(a.depth, b.depth),
+			cmp.Compare(a.z, b.z),
+			cmp.Compare(a.kind, b.kind),
+			cmp.Compare(a.order, b.order),
+		)
+	})
+
+	bounds := r.items[0].bounds
+	for _, item := range r.items[1:] {
+		bounds = bounds.Union(item.bounds)
+	}
+
+	dst := bitmap.NewARGB1555(image.Rectangle{Max: bounds.Size()})
+	for _, item := range r.items {
+		item.draw(dst, bounds.Min)
+	}
+	return dst, nil
+}
+
+// blit copies the opaque pixels of the image onto the destination at the given point
+func blit(dst *bitmap.ARGB1555, src image.Image, at image.Point) {
+	bounds := src.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := at.Add(image.Pt(x-bounds.Min.X, y-bounds.Min.Y))
+			if !p.In(dst.Rect) {
+				continue
+			}
+
+			if value, opaque := encodeARGB1555(src.At(x, y)); opaque {
+				setARGB1555(dst, p.X, p.Y, value)
+			}
+		}
+	}
+}
+
+// drawTriangle fills the triangle with the texture, mapping each of its vertices to the
+// texture coordinates (in [0,1]) and sampling the nearest texel for every pixel
+func drawTriangle(dst *bitmap.ARGB1555, tex image.Image, p [3]image.Point, uv [3][2]float64) {
+	area := edge(p[0], p[1], p[2])
+	if area == 0 {
+		return
+	}
+
+	minX := max(min(p[0].X, p[1].X, p[2].X), dst.Rect.Min.X)
+	minY := max(min(p[0].Y, p[1].Y, p[2].Y), dst.Rect.Min.Y)
+	maxX := min(max(p[0].X, p[1].X, p[2].X), dst.Rect.Max.X-1)
+	maxY := min(max(p[0].Y, p[1].Y, p[2].Y), dst.Rect.Max.Y-1)
+
+	texBounds := tex.Bounds()
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			pt := image.Pt(x, y)
+			w0 := edge(p[1], p[2], pt) / area
+			w1 := edge(p[2], p[0], pt) / area
+			w2 := edge(p[0], p[1], pt) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+
+			u := w0*uv[0][0] + w1*uv[1][0] + w2*uv[2][0]
+			v := w0*uv[0][1] + w1*uv[1][1] + w2*uv[2][1]
+			tx := texBounds.Min.X + min(int(u*float64(texBounds.Dx())), texBounds.Dx()-1)
+			ty := texBounds.Min.Y + min(int(v*float64(texBounds.Dy())), texBounds.Dy()-1)
+			if value, opaque := encodeARGB1555(tex.At(tx, ty)); opaque {
+				setARGB1555(dst, x, y, value)
+			}
+		}
+	}
+}
+
+// edge returns the doubled signed area of the triangle (a, b, c)
+func edge(a, b, c image.Point) float64 {
+	return float64((b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X))
+}
+
+// setARGB1555 writes an opaque 15-bit color into the destination pixel
+func setARGB1555(dst *bitmap.ARGB1555, x, y int, value uint16) {
+	offset := dst.PixOffset(x, y)
+	value |= 0x8000
+	dst.Pix[offset] = byte(value)
+	dst.Pix[offset+1] = byte(value >> 8)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTileMap_Render(t *testing.T) {
+	runWith(t, func(sdk *SDK) {
+		m, err := sdk.Map(1)
+		require.NoError(t, err)
+
+		// Britain bank, with land, statics and slopes
+		rect := image.Rect(1420, 1680, 1440, 1700)
+		img, err := m.Render(rect, RenderOptions{Textures: true, Hues: true})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, img.Bounds().Dx(), rect.Dx()*44)
+
+		land, err := m.Render(rect, RenderOptions{SkipStatics: true})
+		require.NoError(t, err)
+		assert.NotEqual(t, img, land)
+
+		_, err = m.Render(image.Rect(-10, -10, -1, -1), RenderOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestTileMap_RenderDetached(t *testing.T) {
+	m := NewTileMap(0, nil, nil, 8, 8)
+	_, err := m.Render(image.Rect(0, 0, 8, 8), RenderOptions{})
+	assert.Error(t, err)
+}
+
+func TestDrawTriangle(t *testing.T) {
+	tex := bitmap.NewARGB1555(image.Rect(0, 0, 2, 2))
+	tex.Set(0, 0, bitmap.ARGB1555Color(0xFC00)) // Red
+	tex.Set(1, 0, bitmap.ARGB1555Color(0x83E0)) // Green
+	tex.Set(0, 1, bitmap.ARGB1555Color(0x801F)) // Blue
+
+	dst := bitmap.NewARGB1555(image.Rect(0, 0, 20, 20))
+	drawTriangle(dst, tex,
+		[3]image.Point{{0, 0}, {19, 0}, {0, 19}},
+		[3][2]float64{{0, 0}, {1, 0}, {0, 1}},
+	)
+
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), dst.At(1, 1))
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), dst.At(17, 1))
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), dst.At(1, 17))
+	assert.Equal(t, bitmap.ARGB1555Color(0), dst.At(18, 18)) // Outside of the triangle
+}
+
+func TestBlit(t *testing.T) {
+	src := bitmap.NewARGB1555(image.Rect(0, 0, 2, 2))
+	src.Set(0, 0, bitmap.ARGB1555Color(0xFC00))
+
+	dst := bitmap.NewARGB1555(image.Rect(0, 0, 4, 4))
+	dst.Set(3, 3, bitmap.ARGB1555Color(0x801F))
+	blit(dst, src, image.Pt(2, 2))
+	blit(dst, src, image.Pt(-1, -1)) // Clipped
+
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), dst.At(2, 2))
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), dst.At(3, 3)) // Transparent pixels are skipped
+	assert.Equal(t, bitmap.ARGB1555Color(0), dst.At(0, 0))
+}
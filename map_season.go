@@ -0,0 +1,116 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import "maps"
+
+// Season is a season of the world, numbered as in the season packet of the protocol.
+type Season uint8
+
+// Seasons supported by the client. The art of the client files depicts the summer.
+const (
+	SeasonSpring Season = iota
+	SeasonSummer
+	SeasonFall
+	SeasonWinter
+	SeasonDesolation
+)
+
+// SeasonTable lists the tile swaps applied when drawing the world in a season.
+type SeasonTable struct {
+	Land        map[uint16]uint16 // Replacement of land tile IDs
+	Items       map[uint16]uint16 // Replacement of static item IDs
+	HideFoliage bool              // Whether statics flagged as foliage are hidden
+}
+
+// Table returns a new table with the rules of the season: the tile swaps hard-coded in
+// the client, as recreated by the open source clients, and the foliage of the trees
+// dropped in winter and in the desolation. The returned table can be edited to match the
+// in-game appearance of a particular client or shard.
+func (s Season) Table() *SeasonTable {
+	return &SeasonTable{
+		Land:        copySwaps(seasonLand[s]),
+		Items:       copySwaps(seasonItems[s]),
+		HideFoliage: s == SeasonWinter || s == SeasonDesolation,
+	}
+}
+
+// seasonLand lists the land tile swaps of each season: the grass and the dirt are
+// covered with snow in winter.
+var seasonLand = map[Season]map[uint16]uint16{
+	SeasonWinter: {
+		0x0003: 0x011A, 0x0004: 0x011B, 0x0005: 0x011C, 0x0006: 0x011D,
+		0x00C4: 0x011A, 0x00C5: 0x011B, 0x00C6: 0x011C, 0x00C7: 0x011D,
+		0x00C8: 0x011A, 0x00C9: 0x011B, 0x00CA: 0x011C, 0x00CB: 0x011D,
+		0x00CC: 0x011A, 0x00CD: 0x011B, 0x00CE: 0x011C, 0x00CF: 0x011D,
+		0x00D0: 0x011A, 0x00D1: 0x011B, 0x00D2: 0x011C, 0x00D3: 0x011D,
+		0x00D4: 0x011A, 0x00D5: 0x011B, 0x00D6: 0x011C, 0x00D7: 0x011D,
+		0x00F8: 0x011A, 0x00F9: 0x011B, 0x00FA: 0x011C, 0x00FB: 0x011D,
+		0x015D: 0x03A9, 0x015E: 0x03AC, 0x015F: 0x03AA, 0x0160: 0x03AB,
+		0x06A1: 0x011A, 0x06A2: 0x011B, 0x06A3: 0x011C, 0x06A4: 0x011D,
+		0x06AF: 0x011A, 0x06B0: 0x011B, 0x06B1: 0x011C, 0x06B2: 0x011D,
+		0x06B3: 0x011A, 0x06B4: 0x011B, 0x06B5: 0x011C, 0x06B6: 0x011D,
+		0x06B7: 0x011A, 0x06B8: 0x011B, 0x06B9: 0x011C, 0x06BA: 0x011D,
+		0x06BB: 0x011A, 0x06BC: 0x011B, 0x06BD: 0x011C, 0x06BE: 0x011D,
+		0x06BF: 0x011A, 0x06C0: 0x011B, 0x06C1: 0x011C, 0x06C2: 0x011D,
+	},
+}
+
+// seasonItems lists the static item swaps of each season: the trees blossom in spring,
+// turn yellow in fall, carry snow in winter and wither in the desolation.
+var seasonItems = map[Season]map[uint16]uint16{
+	SeasonSpring: {
+		0x0C4A: 0x0CB5, 0x0CA7: 0x0C84, 0x0CAC: 0x0C46, 0x0CAD: 0x0C48,
+		0x0CAE: 0x0CB5, 0x0CAF: 0x0C4E, 0x0CB0: 0x0C4D, 0x0CB6: 0x0D2B,
+		0x0D0C: 0x0D29, 0x0D0D: 0x0D2B, 0x0D0E: 0x0CBE, 0x0D0F: 0x0CBF,
+		0x0D10: 0x0CC0, 0x0D11: 0x0C87, 0x0D12: 0x0C38, 0x0D13: 0x0D2F,
+		0x0D14: 0x0D2B,
+	},
+	SeasonFall: {
+		0x0CCE: 0x0CCF, 0x0CD1: 0x0CD2, 0x0CD4: 0x0CD5, 0x0CDB: 0x0CDC,
+		0x0CDE: 0x0CDF, 0x0CE1: 0x0CE2, 0x0CE4: 0x0CE5, 0x0CE7: 0x0CE8,
+		0x0CE9: 0x0C9E, 0x0CEA: 0x0D3F, 0x0D95: 0x0D97, 0x0D99: 0x0D9B,
+	},
+	SeasonWinter: {
+		0x0CA7: 0x0CC6, 0x0CAC: 0x0D3D, 0x0CAD: 0x0D33, 0x0CAE: 0x0D33,
+		0x0CAF: 0x0D32, 0x0CB0: 0x0D33, 0x0CB1: 0x0D33, 0x0CB2: 0x0D33,
+		0x0CB3: 0x0D33, 0x0CB4: 0x0D33, 0x0CB5: 0x0D33, 0x0CB6: 0x0D33,
+	},
+	SeasonDesolation: {
+		0x0C84: 0x1B84, 0x0C8A: 0x1B8D, 0x0C8B: 0x1B84, 0x0C8E: 0x1B8D,
+		0x0C99: 0x1B8D, 0x0C9A: 0x1B8D, 0x0C9B: 0x1B8D, 0x0C9C: 0x1B8D,
+		0x0C9D: 0x1B8D, 0x0CA6: 0x1B8D, 0x0CA7: 0x1B8D, 0x0CB8: 0x1CEA,
+		0x0CB9: 0x1B9C, 0x0CBA: 0x1B9C, 0x0CBB: 0x1B9C, 0x0CBC: 0x1B9C,
+		0x0CBD: 0x1B9C, 0x0CBE: 0x1B9C, 0x0CC7: 0x1B9C, 0x0CE9: 0x0ED7,
+		0x0CEA: 0x0D3F, 0x0D0F: 0x1B9C, 0x0D11: 0x122B, 0x0D14: 0x122B,
+		0x0D16: 0x1B9C, 0x0D17: 0x122B, 0x0D2B: 0x1B9C, 0x1B7E: 0x1E34,
+	},
+}
+
+// copySwaps returns a copy of the swaps, which callers are free to edit
+func copySwaps(swaps map[uint16]uint16) map[uint16]uint16 {
+	out := make(map[uint16]uint16, len(swaps))
+	maps.Copy(out, swaps)
+	return out
+}
+
+// land returns the land tile ID to draw for the season
+func (t *SeasonTable) land(id uint16) uint16 {
+	if t != nil {
+		if swap, ok := t.Land[id]; ok {
+			return swap
+		}
+	}
+	return id
+}
+
+// item returns the static item ID to draw for the season
+func (t *SeasonTable) item(id uint16) uint16 {
+	if t != nil {
+		if swap, ok := t.Items[id]; ok {
+			return swap
+		}
+	}
+	return id
+}
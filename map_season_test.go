@@ -0,0 +1,103 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeason_Table(t *testing.T) {
+	assert.False(t, SeasonSummer.Table().HideFoliage)
+	assert.False(t, SeasonSpring.Table().HideFoliage)
+	assert.True(t, SeasonWinter.Table().HideFoliage)
+	assert.True(t, SeasonDesolation.Table().HideFoliage)
+
+	// Grass is covered with snow and the trees carry snow in winter
+	table := SeasonWinter.Table()
+	assert.Equal(t, uint16(0x11A), table.land(0x3))
+	assert.Equal(t, uint16(0x11D), table.land(0x6))
+	assert.Equal(t, uint16(0xCC6), table.item(0xCA7))
+	assert.Equal(t, uint16(0x1), table.land(0x1))
+
+	// The art of the client files depicts the summer
+	summer := SeasonSummer.Table()
+	assert.Empty(t, summer.Land)
+	assert.Empty(t, summer.Items)
+	assert.Equal(t, uint16(0x3), summer.land(0x3))
+	assert.Equal(t, uint16(0xC84), SeasonSpring.Table().item(0xCA7))
+
+	// Tables are copies which can be edited
+	summer.Land[0x3] = 0x4
+	table.Land[0x3] = 0x4
+	assert.Equal(t, uint16(0x4), table.land(0x3))
+	assert.Equal(t, uint16(0x11A), SeasonWinter.Table().land(0x3))
+
+	var none *SeasonTable
+	assert.Equal(t, uint16(0x3), none.land(0x3))
+	assert.Equal(t, uint16(0xCA7), none.item(0xCA7))
+}
+
+func TestTileMap_OverviewSeason(t *testing.T) {
+	dir := t.TempDir()
+	radar := make([]byte, totalRadarColors*2)
+	binary.LittleEndian.PutUint16(radar[0:], 0x03E0)       // Green grass
+	binary.LittleEndian.PutUint16(radar[0x11A*2:], 0x7FFF) // White snow
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "radarcol.mul"), radar, 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	m := openTestMap(t, dir, nil)
+	m.sdk = sdk
+
+	winter := SeasonWinter.Table()
+	winter.Land[0] = 0x11A
+
+	summer, err := m.Overview(image.Rect(0, 0, 4, 4), OverviewOptions{})
+	require.NoError(t, err)
+	snow, err := m.Overview(image.Rect(0, 0, 4, 4), OverviewOptions{Season: winter})
+	require.NoError(t, err)
+
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), summer.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0xFFFF), snow.At(0, 0))
+}
+
+func TestTileMap_RenderSeason(t *testing.T) {
+	m := openTestWorld(t, map[int][]StaticItem{
+		0: {NewStaticItem(0x10, 1, 1, 0, 0)},
+	}, nil, map[int]ItemInfo{
+		0x10: {Name: "leaves", Flags: TileFlagFoliage},
+	})
+
+	tree := bitmap.NewARGB1555(image.Rect(0, 0, 20, 120))
+	for y := 0; y < 120; y++ {
+		tree.Set(10, y, bitmap.ARGB1555Color(0x83E0))
+	}
+
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{
+		{ID: 0, Image: bitmap.NewARGB1555(image.Rect(0, 0, 44, 44))},
+		{ID: 0x4010, Image: tree},
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "artidx.mul"), artIdx.Bytes(), 0644))
+
+	summer, err := m.Render(image.Rect(0, 0, 3, 3), RenderOptions{})
+	require.NoError(t, err)
+	winter, err := m.Render(image.Rect(0, 0, 3, 3), RenderOptions{Season: SeasonWinter.Table()})
+	require.NoError(t, err)
+
+	// The tree sticks out above the land, unless its foliage is dropped
+	assert.Greater(t, summer.Bounds().Dy(), winter.Bounds().Dy())
+}
@@ -4,10 +4,18 @@
 package ultima
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"image"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTileMap_TileAt(t *testing.T) {
@@ -67,3 +75,373 @@ func TestTileMap_Image(t *testing.T) {
 		assert.NoError(t, savePng(img, "test/map.png"))
 	})
 }
+
+func TestTileMap_Patches(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+
+	block := func(tileID uint16, z int8) []byte {
+		out := make([]byte, mapBlockSize)
+		for i := 0; i < 64; i++ {
+			binary.LittleEndian.PutUint16(out[4+i*3:], tileID)
+			out[4+i*3+2] = byte(z)
+		}
+		return out
+	}
+
+	static := func(id uint16, x, y uint8) []byte {
+		return []byte{byte(id), byte(id >> 8), x, y, 0, 0, 0}
+	}
+
+	u32s := func(values ...uint32) (out []byte) {
+		for _, v := range values {
+			out = binary.LittleEndian.AppendUint32(out, v)
+		}
+		return out
+	}
+
+	// Original map with three blocks and a single static in the first one
+	var statics, staidx bytes.Buffer
+	w := mul.NewWriter(&statics, &staidx)
+	require.NoError(t, w.Write(0, static(0x100, 0, 0), 0))
+	require.NoError(t, w.Pad(3))
+	write("map0.mul", bytes.Join([][]byte{block(1, 0), block(2, 0), block(3, 0)}, nil))
+	write("statics0.mul", statics.Bytes())
+	write("staidx0.mul", staidx.Bytes())
+
+	// Patch the land of the second block, remove the statics of the first one and add a
+	// static to the second one
+	write("mapdifl0.mul", u32s(1))
+	write("mapdif0.mul", block(7, 5))
+	write("stadifl0.mul", u32s(0, 1))
+	write("stadifi0.mul", u32s(0xFFFFFFFF, 0, 0, 0, 7, 0))
+	write("stadif0.mul", static(0x200, 1, 1))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	patched, err := sdk.MapWithSize(0, 8, 24)
+	require.NoError(t, err)
+	original := patched.WithPatches(false)
+
+	// Land patches
+	tile, err := patched.TileAt(0, 8)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(7), tile.ID)
+	assert.Equal(t, int8(5), tile.Z)
+
+	tile, err = original.TileAt(0, 8)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(2), tile.ID)
+
+	// Static patches
+	tile, err = patched.TileAt(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1), tile.ID)
+	assert.Empty(t, tile.Statics)
+
+	tile, err = original.TileAt(0, 0)
+	require.NoError(t, err)
+	require.Len(t, tile.Statics, 1)
+	assert.Equal(t, uint16(0x100), tile.Statics[0].ID())
+
+	tile, err = patched.TileAt(1, 9)
+	require.NoError(t, err)
+	require.Len(t, tile.Statics, 1)
+	assert.Equal(t, uint16(0x200), tile.Statics[0].ID())
+
+	// Empty difference files, as shipped for maps without patches, are ignored
+	write("map1.mul", bytes.Join([][]byte{block(1, 0), block(2, 0), block(3, 0)}, nil))
+	write("statics1.mul", statics.Bytes())
+	write("staidx1.mul", staidx.Bytes())
+	for _, name := range []string{"mapdifl1.mul", "mapdif1.mul", "stadifl1.mul", "stadifi1.mul", "stadif1.mul"} {
+		write(name, nil)
+	}
+
+	unpatched, err := sdk.MapWithSize(1, 8, 24)
+	require.NoError(t, err)
+	tile, err = unpatched.TileAt(0, 8)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(2), tile.ID)
+}
+
+func TestTileMap_ImageRect(t *testing.T) {
+	runWith(t, func(sdk *SDK) {
+		m, err := sdk.Map(1)
+		require.NoError(t, err)
+
+		full, err := m.Image()
+		require.NoError(t, err)
+
+		rect := image.Rect(1420, 1680, 1450, 1700)
+		img, err := m.ImageRect(rect)
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 30, 20), img.Bounds())
+		for y := 0; y < rect.Dy(); y++ {
+			for x := 0; x < rect.Dx(); x++ {
+				assert.Equal(t, full.At(rect.Min.X+x, rect.Min.Y+y), img.At(x, y))
+			}
+		}
+
+		_, err = m.ImageRect(image.Rect(-10, -10, 0, 0))
+		assert.Error(t, err)
+	})
+}
+
+func TestTileMap_StaticsInRect(t *testing.T) {
+	m := openTestMap(t, t.TempDir(), map[int][]StaticItem{
+		1: {NewStaticItem(0x200, 2, 3, 1, 0)},
+		3: {
+			NewStaticItem(0x100, 0, 0, 0, 0x21),
+			NewStaticItem(0x101, 7, 7, 5, 0),
+		},
+	})
+
+	var found []PlacedStatic
+	for s := range m.StaticsInRect(image.Rect(2, 3, 12, 12)) {
+		found = append(found, s)
+	}
+
+	assert.Equal(t, []PlacedStatic{
+		{X: 2, Y: 11, Z: 1, ID: 0x200},
+		{X: 8, Y: 8, Z: 0, ID: 0x100, Hue: 0x21},
+	}, found)
+
+	// Stopping early and regions outside of the map
+	for range m.StaticsInRect(image.Rect(0, 0, 16, 16)) {
+		break
+	}
+	for range m.StaticsInRect(image.Rect(20, 20, 30, 30)) {
+		t.Fatal("unexpected static")
+	}
+}
+
+func TestDetectMapSize(t *testing.T) {
+	tests := []struct {
+		mapID, blocks int
+		width, height int
+	}{
+		{0, 896 * 512, 7168, 4096},
+		{0, 768 * 512, 6144, 4096},
+		{1, 768 * 512, 6144, 4096},
+		{2, 288 * 200, 2304, 1600},
+		{3, 320 * 256, 2560, 2048},
+		{5, 160 * 512, 1280, 4096},
+		{9, 181 * 181, 1448, 1448},
+		{9, 640 * 512, 5120, 4096},
+		{9, 3, 24, 8},
+		{9, 0, 6144, 4096},
+	}
+
+	for _, tc := range tests {
+		width, height := detectMapSize(tc.mapID, tc.blocks)
+		assert.Equal(t, tc.width, width, "map %d with %d blocks", tc.mapID, tc.blocks)
+		assert.Equal(t, tc.height, height, "map %d with %d blocks", tc.mapID, tc.blocks)
+	}
+}
+
+func TestSDK_MapSize(t *testing.T) {
+	dir := t.TempDir()
+	var statics, staidx bytes.Buffer
+	w := mul.NewWriter(&statics, &staidx)
+	require.NoError(t, w.Write(1, []byte{0x00, 0x01, 2, 3, 4, 0, 0}, 0))
+	require.NoError(t, w.Pad(32*16))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "map7.mul"), make([]byte, 32*16*mapBlockSize), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "statics7.mul"), statics.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staidx7.mul"), staidx.Bytes(), 0644))
+
+	// A map with empty statics files fails to load, rather than panicking
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "map8.mul"), make([]byte, 32*16*mapBlockSize), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "statics8.mul"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staidx8.mul"), nil, 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	_, err = sdk.Map(8)
+	assert.Error(t, err)
+
+	m, err := sdk.Map(7)
+	require.NoError(t, err)
+	width, height := m.Size()
+	assert.Equal(t, 256, width)
+	assert.Equal(t, 128, height)
+
+	m, err = sdk.MapWithSize(7, 128, 256)
+	require.NoError(t, err)
+	width, height = m.Size()
+	assert.Equal(t, 128, width)
+	assert.Equal(t, 256, height)
+
+	_, err = sdk.MapWithSize(7, 100, 256)
+	assert.Error(t, err)
+}
+
+func TestSDK_RegisterMap(t *testing.T) {
+	dir := t.TempDir()
+	var statics, staidx bytes.Buffer
+	w := mul.NewWriter(&statics, &staidx)
+	require.NoError(t, w.Write(1, []byte{0x00, 0x01, 2, 3, 4, 0, 0}, 0))
+	require.NoError(t, w.Pad(2))
+
+	land := make([]byte, 2*mapBlockSize)
+	binary.LittleEndian.PutUint16(land[mapBlockSize+4:], 0xA8)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom.mul"), land, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "customstatics.mul"), statics.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "customidx.mul"), staidx.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	require.NoError(t, sdk.RegisterMap(6, 16, 8, "custom.mul", "customstatics.mul", "customidx.mul"))
+	m, err := sdk.Map(6)
+	require.NoError(t, err)
+
+	width, height := m.Size()
+	assert.Equal(t, 16, width)
+	assert.Equal(t, 8, height)
+
+	tile, err := m.TileAt(8, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0xA8), tile.ID)
+
+	tile, err = m.TileAt(10, 3)
+	require.NoError(t, err)
+	require.Len(t, tile.Statics, 1)
+	assert.Equal(t, uint16(0x100), tile.Statics[0].ID())
+
+	assert.Error(t, sdk.RegisterMap(-1, 16, 8, "custom.mul"))
+	assert.Error(t, sdk.RegisterMap(6, 10, 8, "custom.mul"))
+	assert.Error(t, sdk.RegisterMap(6, 16, 8))
+	assert.Error(t, sdk.RegisterMap(6, 16, 8, "custom.mul", "customstatics.mul"))
+
+	// Maps can only be registered before they are loaded
+	assert.Error(t, sdk.RegisterMap(6, 8, 16, "custom.mul", "customstatics.mul", "customidx.mul"))
+	assert.NoError(t, sdk.RegisterMap(5, 8, 16, "custom.mul", "customstatics.mul", "customidx.mul"))
+}
+
+func TestTileMap_Tiles(t *testing.T) {
+	m := openTestMap(t, t.TempDir(), map[int][]StaticItem{
+		3: {
+			NewStaticItem(0x100, 1, 1, 0, 0),
+			NewStaticItem(0x101, 1, 1, 5, 0),
+		},
+	})
+	require.NoError(t, m.SetTile(9, 9, 0xA8, 3))
+
+	r := image.Rect(6, 6, 12, 10)
+	count := 0
+	for at, tile := range m.Tiles(r) {
+		assert.True(t, at.In(r))
+		expect, err := m.TileAt(at.X, at.Y)
+		require.NoError(t, err)
+		assert.Equal(t, expect, tile, "tile at %v", at)
+		count++
+	}
+	assert.Equal(t, r.Dx()*r.Dy(), count)
+
+	// Stopping early and regions outside of the map
+	for range m.Tiles(image.Rect(0, 0, 16, 16)) {
+		break
+	}
+	for range m.Tiles(image.Rect(20, 20, 30, 30)) {
+		t.Fatal("unexpected tile")
+	}
+}
+
+func TestTileMap_Overview(t *testing.T) {
+	dir := t.TempDir()
+	radar := make([]byte, totalRadarColors*2)
+	binary.LittleEndian.PutUint16(radar[0:], 0x4210) // Gray, for the land tile 0
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "radarcol.mul"), radar, 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	m := openTestMap(t, dir, nil)
+	m.sdk = sdk
+	require.NoError(t, m.SetTile(5, 5, 0, 10))
+
+	flat, err := m.Overview(image.Rect(4, 4, 8, 8), OverviewOptions{})
+	require.NoError(t, err)
+	shaded, err := m.Overview(image.Rect(4, 4, 8, 8), OverviewOptions{Shading: true})
+	require.NoError(t, err)
+
+	gray := bitmap.ARGB1555Color(0xC210)
+	assert.Equal(t, gray, flat.At(1, 1))
+	assert.Equal(t, gray, shaded.At(0, 0))
+	assert.Equal(t, bitmap.ARGB1555Color(0x8000|shadeARGB1555(0x4210, 10)), shaded.At(1, 1))
+	assert.Equal(t, bitmap.ARGB1555Color(0x8000|shadeARGB1555(0x4210, -10)), shaded.At(2, 2))
+}
+
+func TestShadeARGB1555(t *testing.T) {
+	assert.Equal(t, uint16(0x4210), shadeARGB1555(0x4210, 0))
+	assert.Equal(t, uint16(0x6318), shadeARGB1555(0x4210, 10))
+	assert.Equal(t, uint16(0x2108), shadeARGB1555(0x4210, -10))
+	assert.Equal(t, uint16(0x7FFF), shadeARGB1555(0x7FFF, 50))
+}
+
+func TestTileMap_WritePatches(t *testing.T) {
+	dir := t.TempDir()
+	statics := map[int][]StaticItem{
+		0: {NewStaticItem(0x100, 1, 1, 0, 0)},
+		3: {NewStaticItem(0x101, 2, 2, 0, 0)},
+	}
+
+	base := openTestMap(t, dir, statics)
+	modified := openTestMap(t, t.TempDir(), statics)
+	require.NoError(t, modified.SetTile(9, 1, 0xA8, 4))
+	require.NoError(t, modified.AddStatic(1, 9, 5, 0x200, 0x21))
+	_, err := modified.RemoveStatic(10, 10, 0x101)
+	require.NoError(t, err)
+
+	// Write the difference files next to the base map
+	var landList, land, staticsList, staticsIndex, staticsData bytes.Buffer
+	landCount, staticsCount, err := modified.WritePatches(base, MapPatchFiles{
+		LandList:     &landList,
+		Land:         &land,
+		StaticsList:  &staticsList,
+		StaticsIndex: &staticsIndex,
+		Statics:      &staticsData,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, landCount)
+	assert.Equal(t, 2, staticsCount)
+
+	for name, data := range map[string][]byte{
+		"mapdifl0.mul": landList.Bytes(),
+		"mapdif0.mul":  land.Bytes(),
+		"stadifl0.mul": staticsList.Bytes(),
+		"stadifi0.mul": staticsIndex.Bytes(),
+		"stadif0.mul":  staticsData.Bytes(),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	patched, err := sdk.MapWithSize(0, 16, 16)
+	require.NoError(t, err)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			expect, err := modified.TileAt(x, y)
+			require.NoError(t, err)
+			tile, err := patched.TileAt(x, y)
+			require.NoError(t, err)
+			assert.Equal(t, expect, tile, "tile at (%d,%d)", x, y)
+		}
+	}
+
+	// Maps of different sizes cannot be compared
+	_, _, err = modified.WritePatches(NewTileMap(0, nil, nil, 8, 8), MapPatchFiles{})
+	assert.Error(t, err)
+}
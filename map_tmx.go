@@ -0,0 +1,237 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tmxMap is the root element of a Tiled map (.tmx) file
+type tmxMap struct {
+	XMLName      xml.Name      `xml:"map"`
+	Version      string        `xml:"version,attr"`
+	Orientation  string        `xml:"orientation,attr"`
+	RenderOrder  string        `xml:"renderorder,attr"`
+	Width        int           `xml:"width,attr"`
+	Height       int           `xml:"height,attr"`
+	TileWidth    int           `xml:"tilewidth,attr"`
+	TileHeight   int           `xml:"tileheight,attr"`
+	Infinite     int           `xml:"infinite,attr"`
+	NextLayerID  int           `xml:"nextlayerid,attr"`
+	NextObjectID int           `xml:"nextobjectid,attr"`
+	Tileset      tmxTilesetRef `xml:"tileset"`
+	Layers       []tmxLayer    `xml:"layer"`
+}
+
+// tmxTilesetRef references an external tileset from a map
+type tmxTilesetRef struct {
+	FirstGID int    `xml:"firstgid,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// tmxLayer is a layer of tiles, encoded as comma-separated global tile IDs
+type tmxLayer struct {
+	ID     int    `xml:"id,attr"`
+	Name   string `xml:"name,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Data   struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+}
+
+// tmxTileset is the root element of a Tiled tileset (.tsx) file, as a collection of
+// images with one image per tile
+type tmxTileset struct {
+	XMLName    xml.Name `xml:"tileset"`
+	Version    string   `xml:"version,attr"`
+	Name       string   `xml:"name,attr"`
+	TileWidth  int      `xml:"tilewidth,attr"`
+	TileHeight int      `xml:"tileheight,attr"`
+	TileCount  int      `xml:"tilecount,attr"`
+	Columns    int      `xml:"columns,attr"`
+	Grid       struct {
+		Orientation string `xml:"orientation,attr"`
+		Width       int    `xml:"width,attr"`
+		Height      int    `xml:"height,attr"`
+	} `xml:"grid"`
+	Tiles []tmxTile `xml:"tile"`
+}
+
+// tmxTile is a single tile of a tileset, with its image and the art it comes from
+type tmxTile struct {
+	ID         int           `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Image      struct {
+		Width  int    `xml:"width,attr"`
+		Height int    `xml:"height,attr"`
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+}
+
+// tmxProperty is a custom property of a tile
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:"value,attr"`
+}
+
+// ExportTMX exports the region of the map covered by the rectangle (in tile
+// coordinates) as an isometric Tiled map, so that it can be inspected with standard
+// level design tools. The directory receives name.tmx with a land layer and as many
+// statics layers as there are statics stacked on a single tile, name.tsx with a tile
+// for every art used, and the art itself as PNG files in the art sub-directory. Tiles
+// carry the art ID in their "art" property; elevations are not represented.
+func (m *TileMap) ExportTMX(dir, name string, rect image.Rectangle) error {
+	if m.sdk == nil {
+		return fmt.Errorf("ExportTMX: map is not attached to an SDK")
+	}
+
+	rect = rect.Intersect(image.Rect(0, 0, m.width, m.height))
+	if rect.Empty() {
+		return fmt.Errorf("ExportTMX: region is outside of the map")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "art"), 0755); err != nil {
+		return fmt.Errorf("ExportTMX: failed to create directory: %w", err)
+	}
+
+	tileset := &tmxTileset{Version: "1.10", Name: name}
+	tileset.Grid.Orientation = "isometric"
+	tileset.Grid.Width, tileset.Grid.Height = 44, 44
+
+	// Export the art of every tile on first use, mapping art IDs to global tile IDs
+	gids := make(map[int]int)
+	gid := func(artID int) (int, error) {
+		if id, ok := gids[artID]; ok {
+			return id, nil
+		}
+
+		var img image.Image
+		if artID < staticTileMinID {
+			if land, err := m.sdk.Land(artID); err == nil && land != nil {
+				img = land.Image
+			}
+		} else if item, err := m.sdk.Item(artID - staticTileMinID); err == nil && item != nil {
+			img = item.Image
+		}
+
+		if img == nil || img.Bounds().Empty() {
+			gids[artID] = 0 // No art, leave the cell empty
+			return 0, nil
+		}
+
+		source := fmt.Sprintf("art/%05X.png", artID)
+		if err := writePNG(filepath.Join(dir, filepath.FromSlash(source)), img); err != nil {
+			return 0, err
+		}
+
+		tile := tmxTile{
+			ID:         len(tileset.Tiles),
+			Properties: []tmxProperty{{Name: "art", Type: "int", Value: strconv.Itoa(artID)}},
+		}
+		tile.Image.Width, tile.Image.Height = img.Bounds().Dx(), img.Bounds().Dy()
+		tile.Image.Source = source
+
+		tileset.Tiles = append(tileset.Tiles, tile)
+		tileset.TileWidth = max(tileset.TileWidth, tile.Image.Width)
+		tileset.TileHeight = max(tileset.TileHeight, tile.Image.Height)
+		gids[artID] = tile.ID + 1
+		return tile.ID + 1, nil
+	}
+
+	// Layers of global tile IDs, the first one for the land and the others for the
+	// statics, in drawing order on each tile
+	w, h := rect.Dx(), rect.Dy()
+	layers := [][]int{make([]int, w*h)}
+	for at, tile := range m.Tiles(rect) {
+		cell := (at.Y-rect.Min.Y)*w + (at.X - rect.Min.X)
+		id, err := gid(int(tile.ID))
+		if err != nil {
+			return fmt.Errorf("ExportTMX: %w", err)
+		}
+		layers[0][cell] = id
+
+		statics := slices.Clone(tile.Statics)
+		slices.SortStableFunc(statics, func(a, b StaticItem) int {
+			_, _, za := a.Location()
+			_, _, zb := b.Location()
+			return cmp.Compare(za, zb)
+		})
+
+		for i, static := range statics {
+			if i+1 >= len(layers) {
+				layers = append(layers, make([]int, w*h))
+			}
+
+			if layers[i+1][cell], err = gid(int(static.ID()) + staticTileMinID); err != nil {
+				return fmt.Errorf("ExportTMX: %w", err)
+			}
+		}
+	}
+
+	tileset.TileCount = len(tileset.Tiles)
+	out := &tmxMap{
+		Version:      "1.10",
+		Orientation:  "isometric",
+		RenderOrder:  "right-down",
+		Width:        w,
+		Height:       h,
+		TileWidth:    44,
+		TileHeight:   44,
+		NextLayerID:  len(layers) + 1,
+		NextObjectID: 1,
+		Tileset:      tmxTilesetRef{FirstGID: 1, Source: name + ".tsx"},
+	}
+
+	for i, cells := range layers {
+		layer := tmxLayer{ID: i + 1, Name: "land", Width: w, Height: h}
+		if i > 0 {
+			layer.Name = fmt.Sprintf("statics %d", i)
+		}
+
+		var sb strings.Builder
+		for j, cell := range cells {
+			switch {
+			case j == 0:
+			case j%w == 0:
+				sb.WriteString(",\n")
+			default:
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.Itoa(cell))
+		}
+
+		layer.Data.Encoding = "csv"
+		layer.Data.Value = "\n" + sb.String() + "\n"
+		out.Layers = append(out.Layers, layer)
+	}
+
+	if err := writeXML(filepath.Join(dir, name+".tsx"), tileset); err != nil {
+		return fmt.Errorf("ExportTMX: %w", err)
+	}
+	if err := writeXML(filepath.Join(dir, name+".tmx"), out); err != nil {
+		return fmt.Errorf("ExportTMX: %w", err)
+	}
+	return nil
+}
+
+// writeXML writes the value as an indented XML document
+func writeXML(path string, value any) error {
+	data, err := xml.MarshalIndent(value, "", " ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	return os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
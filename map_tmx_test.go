@@ -0,0 +1,76 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTileMap_ExportTMX(t *testing.T) {
+	m := openTestWorld(t, map[int][]StaticItem{
+		0: {
+			NewStaticItem(0x10, 1, 1, 10, 0),
+			NewStaticItem(0x11, 1, 1, 0, 0),
+		},
+	}, nil, map[int]ItemInfo{
+		0x10: {Name: "lamp"},
+		0x11: {Name: "table"},
+	})
+
+	static := bitmap.NewARGB1555(image.Rect(0, 0, 10, 30))
+	static.Set(5, 5, bitmap.ARGB1555Color(0x801F))
+
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{
+		{ID: 0, Image: bitmap.NewARGB1555(image.Rect(0, 0, 44, 44))},
+		{ID: 0x4010, Image: static},
+		{ID: 0x4011, Image: static},
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(m.sdk.BasePath(), "artidx.mul"), artIdx.Bytes(), 0644))
+
+	dir := t.TempDir()
+	require.NoError(t, m.ExportTMX(dir, "region", image.Rect(0, 0, 3, 2)))
+
+	// The map holds the land and a layer per stacked static, lowest first
+	var tmx tmxMap
+	data, err := os.ReadFile(filepath.Join(dir, "region.tmx"))
+	require.NoError(t, err)
+	require.NoError(t, xml.Unmarshal(data, &tmx))
+	assert.Equal(t, "isometric", tmx.Orientation)
+	assert.Equal(t, 3, tmx.Width)
+	assert.Equal(t, 2, tmx.Height)
+	assert.Equal(t, "region.tsx", tmx.Tileset.Source)
+	require.Len(t, tmx.Layers, 3)
+	assert.Equal(t, "1,1,1,\n1,1,1", strings.TrimSpace(tmx.Layers[0].Data.Value))
+	assert.Equal(t, "0,0,0,\n0,2,0", strings.TrimSpace(tmx.Layers[1].Data.Value))
+	assert.Equal(t, "0,0,0,\n0,3,0", strings.TrimSpace(tmx.Layers[2].Data.Value))
+
+	// The tileset references the exported art
+	var tsx tmxTileset
+	data, err = os.ReadFile(filepath.Join(dir, "region.tsx"))
+	require.NoError(t, err)
+	require.NoError(t, xml.Unmarshal(data, &tsx))
+	require.Len(t, tsx.Tiles, 3)
+	assert.Equal(t, "art/04011.png", tsx.Tiles[1].Image.Source)
+	assert.Equal(t, "16401", tsx.Tiles[1].Properties[0].Value)
+	assert.Equal(t, 44, tsx.TileWidth)
+	for _, tile := range tsx.Tiles {
+		assert.FileExists(t, filepath.Join(dir, tile.Image.Source))
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "art", "04010.png"))
+	assert.NoError(t, err)
+	assert.Error(t, m.ExportTMX(dir, "region", image.Rect(20, 20, 30, 30)))
+}
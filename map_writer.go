@@ -0,0 +1,258 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kelindar/ultima-sdk/internal/mul"
+	"github.com/kelindar/ultima-sdk/internal/uop"
+)
+
+// encodeLandBlock encodes the block into its 196-byte representation
+func encodeLandBlock(block *LandBlock) []byte {
+	out := make([]byte, mapBlockSize)
+	binary.LittleEndian.PutUint32(out[0:4], block.Header)
+	for i, tile := range block.Tiles {
+		binary.LittleEndian.PutUint16(out[4+i*3:], tile.ID)
+		out[4+i*3+2] = byte(tile.Z)
+	}
+	return out
+}
+
+// mapEdits holds the pending in-memory modifications of a tile map, keyed by block index
+type mapEdits struct {
+	mu      sync.RWMutex
+	version atomic.Uint64 // Incremented on every modification, to invalidate cached blocks
+	land    map[int][]byte
+	statics map[int][]StaticItem
+}
+
+// newMapEdits creates an empty set of map modifications
+func newMapEdits() *mapEdits {
+	return &mapEdits{
+		land:    make(map[int][]byte),
+		statics: make(map[int][]StaticItem),
+	}
+}
+
+// SetTile changes the land tile ID and elevation at the world coordinates. The change
+// is kept in memory until written with Write or WriteUOP.
+func (m *TileMap) SetTile(x, y int, id uint16, z int8) error {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return fmt.Errorf("SetTile: coordinates out of bounds (%d,%d)", x, y)
+	}
+
+	blockIndex := (x/8)*(m.height/8) + y/8
+	m.edits.mu.Lock()
+	defer m.edits.mu.Unlock()
+
+	block, ok := m.edits.land[blockIndex]
+	if !ok {
+		block = make([]byte, mapBlockSize)
+		if err := m.readBaseLandBlock(block, blockIndex); err != nil {
+			return err
+		}
+	} else {
+		block = slices.Clone(block) // Readers may hold on to the previous version
+	}
+
+	offset := 4 + ((y%8)*8+x%8)*3
+	binary.LittleEndian.PutUint16(block[offset:], id)
+	block[offset+2] = byte(z)
+	m.edits.land[blockIndex] = block
+	m.edits.version.Add(1)
+	return nil
+}
+
+// SetLandBlock replaces the land tiles of the block at the given block coordinates. The
+// change is kept in memory until written with Write or WriteUOP.
+func (m *TileMap) SetLandBlock(blockX, blockY int, block *LandBlock) error {
+	blockIndex, err := m.blockIndex(blockX, blockY)
+	if err != nil {
+		return fmt.Errorf("SetLandBlock: %w", err)
+	}
+
+	m.edits.mu.Lock()
+	defer m.edits.mu.Unlock()
+	m.edits.land[blockIndex] = encodeLandBlock(block)
+	m.edits.version.Add(1)
+	return nil
+}
+
+// editedLand returns the modified land block, if it was modified
+func (m *TileMap) editedLand(blockIndex int) ([]byte, bool) {
+	if m.edits == nil {
+		return nil, false
+	}
+
+	m.edits.mu.RLock()
+	defer m.edits.mu.RUnlock()
+	block, ok := m.edits.land[blockIndex]
+	return block, ok
+}
+
+// NewStaticItem creates a static with the given item ID, position within its 8x8
+// block, elevation and hue.
+func NewStaticItem(id uint16, x, y uint8, z int8, hue uint16) StaticItem {
+	return StaticItem{byte(id), byte(id >> 8), x, y, byte(z), byte(hue), byte(hue >> 8)}
+}
+
+// AddStatic places a new static with the given item ID, elevation and hue at the world
+// coordinates. The change is kept in memory until written with WriteStatics.
+func (m *TileMap) AddStatic(x, y int, z int8, id, hue uint16) error {
+	return m.editStatics(x, y, func(statics []StaticItem) ([]StaticItem, error) {
+		return append(statics, NewStaticItem(id, uint8(x%8), uint8(y%8), z, hue)), nil
+	})
+}
+
+// RemoveStatic removes every static with the given item ID at the world coordinates and
+// returns the number of statics removed.
+func (m *TileMap) RemoveStatic(x, y int, id uint16) (int, error) {
+	removed := 0
+	err := m.editStatics(x, y, func(statics []StaticItem) ([]StaticItem, error) {
+		return slices.DeleteFunc(statics, func(s StaticItem) bool {
+			if sx, sy, _ := s.Location(); s.ID() == id && int(sx) == x%8 && int(sy) == y%8 {
+				removed++
+				return true
+			}
+			return false
+		}), nil
+	})
+	return removed, err
+}
+
+// MoveStatic moves the first static with the given item ID at the world coordinates to
+// another location and elevation, keeping its hue.
+func (m *TileMap) MoveStatic(fromX, fromY int, id uint16, toX, toY int, z int8) error {
+	if toX < 0 || toY < 0 || toX >= m.width || toY >= m.height {
+		return fmt.Errorf("MoveStatic: coordinates out of bounds (%d,%d)", toX, toY)
+	}
+
+	var hue uint16
+	err := m.editStatics(fromX, fromY, func(statics []StaticItem) ([]StaticItem, error) {
+		for i, s := range statics {
+			if sx, sy, _ := s.Location(); s.ID() == id && int(sx) == fromX%8 && int(sy) == fromY%8 {
+				hue = s.Hue()
+				return slices.Delete(statics, i, i+1), nil
+			}
+		}
+		return nil, fmt.Errorf("MoveStatic: no static %#x at (%d,%d)", id, fromX, fromY)
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.AddStatic(toX, toY, z, id, hue)
+}
+
+// editStatics applies the modification to the statics of the block containing the world
+// coordinates, starting from the current (possibly already modified) statics.
+func (m *TileMap) editStatics(x, y int, fn func([]StaticItem) ([]StaticItem, error)) error {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return fmt.Errorf("editStatics: coordinates out of bounds (%d,%d)", x, y)
+	}
+
+	blockIndex := (x/8)*(m.height/8) + y/8
+	m.edits.mu.Lock()
+	defer m.edits.mu.Unlock()
+
+	current, ok := m.edits.statics[blockIndex]
+	if !ok {
+		original, err := m.readBlockStatics(blockIndex)
+		if err != nil {
+			return err
+		}
+		current = original
+	}
+
+	updated, err := fn(slices.Clone(current))
+	if err != nil {
+		return err
+	}
+
+	m.edits.statics[blockIndex] = updated
+	m.edits.version.Add(1)
+	return nil
+}
+
+// editedStatics returns the modified statics of the block, if it was modified
+func (m *TileMap) editedStatics(blockIndex int) ([]StaticItem, bool) {
+	if m.edits == nil {
+		return nil, false
+	}
+
+	m.edits.mu.RLock()
+	defer m.edits.mu.RUnlock()
+	statics, ok := m.edits.statics[blockIndex]
+	return statics, ok
+}
+
+// WriteStatics writes the statics of every block of the map, including any in-memory
+// modifications and enabled patches, as a staticsX.mul and staidxX.mul pair. The data
+// is written contiguously in block order, so unused space of the source files is
+// dropped and blocks without statics get an empty index entry.
+func (m *TileMap) WriteStatics(statics, staidx io.Writer) error {
+	blockCount := (m.width / 8) * (m.height / 8)
+	writer := mul.NewWriter(statics, staidx)
+
+	var buffer []byte
+	for blockIndex := 0; blockIndex < blockCount; blockIndex++ {
+		items, err := m.readStatics(blockIndex)
+		if err != nil {
+			return fmt.Errorf("WriteStatics: block %d: %w", blockIndex, err)
+		}
+
+		buffer = buffer[:0]
+		for _, item := range items {
+			buffer = append(buffer, item...)
+		}
+
+		if err := writer.Write(uint32(blockIndex), buffer, 0); err != nil {
+			return fmt.Errorf("WriteStatics: block %d: %w", blockIndex, err)
+		}
+	}
+
+	return writer.Pad(uint32(blockCount))
+}
+
+// Write writes the land of every block of the map, including any in-memory
+// modifications and enabled patches, in the mapX.mul format.
+func (m *TileMap) Write(dst io.Writer) error {
+	blockCount := (m.width / 8) * (m.height / 8)
+	buffer := make([]byte, mapBlockSize)
+	for blockIndex := 0; blockIndex < blockCount; blockIndex++ {
+		if err := m.readLandBlock(buffer, blockIndex); err != nil {
+			return fmt.Errorf("Write: block %d: %w", blockIndex, err)
+		}
+
+		if _, err := dst.Write(buffer); err != nil {
+			return fmt.Errorf("Write: block %d: %w", blockIndex, err)
+		}
+	}
+	return nil
+}
+
+// WriteUOP writes the land of every block of the map, like Write, but wrapped in the
+// mapXLegacyMUL.uop format used by newer clients.
+func (m *TileMap) WriteUOP(dst io.Writer) error {
+	blockCount := (m.width / 8) * (m.height / 8)
+	entries := make([][]byte, 0, (blockCount+blocksPerEntry-1)/blocksPerEntry)
+	for start := 0; start < blockCount; start += blocksPerEntry {
+		entry := make([]byte, min(blocksPerEntry, blockCount-start)*mapBlockSize)
+		for i := 0; i*mapBlockSize < len(entry); i++ {
+			if err := m.readLandBlock(entry[i*mapBlockSize:], start+i); err != nil {
+				return fmt.Errorf("WriteUOP: block %d: %w", start+i, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return uop.Write(dst, fmt.Sprintf("map%dlegacymul", m.mapID), ".dat", entries)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/mul"
+	"github.com/kelindar/ultima-sdk/internal/uofile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestMap writes a 16x16 map (2x2 blocks) with the given statics per block into the
+// directory and opens it as a TileMap
+func openTestMap(t *testing.T, dir string, statics map[int][]StaticItem) *TileMap {
+	var staticsMul, staidxMul bytes.Buffer
+	w := mul.NewWriter(&staticsMul, &staidxMul)
+	for block := 0; block < 4; block++ {
+		var data []byte
+		for _, s := range statics[block] {
+			data = append(data, s...)
+		}
+		require.NoError(t, w.Write(uint32(block), data, 0))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "map0.mul"), make([]byte, 4*mapBlockSize), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "statics0.mul"), staticsMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staidx0.mul"), staidxMul.Bytes(), 0644))
+
+	mapFile := uofile.New(dir, []string{"map0.mul"}, 0, uofile.WithDecodeMUL(decodeMapFile))
+	staticsFile := uofile.New(dir, []string{"statics0.mul", "staidx0.mul"}, 0,
+		uofile.WithIndexLength(12), uofile.WithExtra())
+	t.Cleanup(func() {
+		mapFile.Close()
+		staticsFile.Close()
+	})
+
+	return NewTileMap(0, mapFile, staticsFile, 16, 16)
+}
+
+// staticIDs returns the item IDs of the statics at the given world coordinates
+func staticIDs(t *testing.T, m *TileMap, x, y int) []uint16 {
+	tile, err := m.TileAt(x, y)
+	require.NoError(t, err)
+
+	var ids []uint16
+	for _, s := range tile.Statics {
+		ids = append(ids, s.ID())
+	}
+	return ids
+}
+
+func TestNewStaticItem(t *testing.T) {
+	item := NewStaticItem(0x1234, 3, 7, -5, 0x21)
+	x, y, z := item.Location()
+	assert.Equal(t, uint16(0x1234), item.ID())
+	assert.Equal(t, uint16(0x21), item.Hue())
+	assert.Equal(t, []int{3, 7, -5}, []int{int(x), int(y), int(z)})
+}
+
+func TestTileMap_EditStatics(t *testing.T) {
+	m := openTestMap(t, t.TempDir(), map[int][]StaticItem{
+		0: {NewStaticItem(0x100, 0, 0, 0, 7)},
+	})
+
+	// Add, move and remove statics
+	require.NoError(t, m.AddStatic(9, 1, 5, 0x200, 0))
+	require.NoError(t, m.AddStatic(9, 1, 6, 0x201, 0))
+	require.NoError(t, m.MoveStatic(0, 0, 0x100, 10, 10, 20))
+	removed, err := m.RemoveStatic(9, 1, 0x201)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	assert.Empty(t, staticIDs(t, m, 0, 0))
+	assert.Equal(t, []uint16{0x200}, staticIDs(t, m, 9, 1))
+	assert.Equal(t, []uint16{0x100}, staticIDs(t, m, 10, 10))
+
+	// Invalid edits
+	assert.Error(t, m.AddStatic(16, 0, 0, 0x100, 0))
+	assert.Error(t, m.MoveStatic(0, 0, 0x100, 1, 1, 0))
+	assert.Error(t, m.MoveStatic(10, 10, 0x100, -1, 1, 0))
+
+	// Write the statics back and read them again
+	var statics, staidx bytes.Buffer
+	require.NoError(t, m.WriteStatics(&statics, &staidx))
+	assert.Equal(t, 4*12, staidx.Len())
+	assert.Equal(t, 2*7, statics.Len())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "statics0.mul"), statics.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staidx0.mul"), staidx.Bytes(), 0644))
+	staticsFile := uofile.New(dir, []string{"statics0.mul", "staidx0.mul"}, 0,
+		uofile.WithIndexLength(12), uofile.WithExtra())
+	defer staticsFile.Close()
+
+	reloaded := NewTileMap(0, m.mapFile, staticsFile, 16, 16)
+	assert.Empty(t, staticIDs(t, reloaded, 0, 0))
+	assert.Equal(t, []uint16{0x200}, staticIDs(t, reloaded, 9, 1))
+	assert.Equal(t, []uint16{0x100}, staticIDs(t, reloaded, 10, 10))
+
+	tile, err := reloaded.TileAt(10, 10)
+	require.NoError(t, err)
+	_, _, z := tile.Statics[0].Location()
+	assert.Equal(t, int8(20), z)
+	assert.Equal(t, uint16(7), tile.Statics[0].Hue())
+}
+
+func TestTileMap_EditLand(t *testing.T) {
+	m := openTestMap(t, t.TempDir(), nil)
+
+	block := &LandBlock{}
+	for i := range block.Tiles {
+		block.Tiles[i] = LandTile{ID: 0x10, Z: 1}
+	}
+
+	// Modify a single tile and a whole block
+	require.NoError(t, m.SetTile(3, 4, 0xA8, -5))
+	require.NoError(t, m.SetTile(4, 4, 0xA9, 0))
+	require.NoError(t, m.SetLandBlock(1, 1, block))
+	assert.Error(t, m.SetTile(-1, 0, 0, 0))
+	assert.Error(t, m.SetLandBlock(2, 0, block))
+
+	verify := func(m *TileMap) {
+		tile, err := m.TileAt(3, 4)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(0xA8), tile.ID)
+		assert.Equal(t, int8(-5), tile.Z)
+
+		tile, err = m.TileAt(4, 4)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(0xA9), tile.ID)
+
+		tile, err = m.TileAt(15, 15)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(0x10), tile.ID)
+		assert.Equal(t, int8(1), tile.Z)
+
+		tile, err = m.TileAt(8, 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(0), tile.ID)
+	}
+
+	verify(m)
+
+	// Write the map as MUL and read it back
+	var mapMul bytes.Buffer
+	require.NoError(t, m.Write(&mapMul))
+	assert.Equal(t, 4*mapBlockSize, mapMul.Len())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "map0.mul"), mapMul.Bytes(), 0644))
+	mapFile := uofile.New(dir, []string{"map0.mul"}, 0, uofile.WithDecodeMUL(decodeMapFile))
+	defer mapFile.Close()
+	verify(NewTileMap(0, mapFile, m.staticsFile, 16, 16))
+
+	// Write the map as UOP and read it back
+	var mapUop bytes.Buffer
+	require.NoError(t, m.WriteUOP(&mapUop))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "map0LegacyMUL.uop"), mapUop.Bytes(), 0644))
+	uopFile := uofile.New(dir, []string{"map0LegacyMUL.uop"}, 0, uofile.WithStrict())
+	defer uopFile.Close()
+	verify(NewTileMap(0, uopFile, m.staticsFile, 16, 16))
+}
+
+func TestDecodeMapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map9.mul")
+	require.NoError(t, os.WriteFile(path, make([]byte, (blocksPerEntry+10)*mapBlockSize), 0644))
+
+	reader, err := mul.OpenOne(path, mul.WithDecode(decodeMapFile))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	first, err := reader.Entry(0)
+	require.NoError(t, err)
+	assert.Equal(t, blocksPerEntry*mapBlockSize, first.Len())
+
+	last, err := reader.Entry(1)
+	require.NoError(t, err)
+	assert.Equal(t, 10*mapBlockSize, last.Len())
+}
+
+func TestTileMap_Blocks(t *testing.T) {
+	m := openTestMap(t, t.TempDir(), map[int][]StaticItem{
+		3: {
+			NewStaticItem(0x100, 0, 0, 0, 0),
+			NewStaticItem(0x101, 7, 7, 5, 0),
+			NewStaticItem(0x102, 7, 7, 10, 0),
+		},
+	})
+
+	require.NoError(t, m.SetTile(9, 10, 0xA8, 3))
+	land, err := m.LandBlock(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, LandTile{ID: 0xA8, Z: 3}, land.Tiles[2*8+1])
+	assert.Equal(t, LandTile{}, land.Tiles[0])
+
+	statics, err := m.StaticBlock(1, 1)
+	require.NoError(t, err)
+	assert.Len(t, statics.Tiles[0], 1)
+	assert.Len(t, statics.Tiles[63], 2)
+	assert.Equal(t, uint16(0x102), statics.Tiles[63][1].ID())
+
+	_, err = m.LandBlock(2, 0)
+	assert.Error(t, err)
+	_, err = m.StaticBlock(0, -1)
+	assert.Error(t, err)
+}
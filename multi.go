@@ -9,10 +9,20 @@ import (
 	"encoding/csv"
 	"fmt"
 	"image"
+	"io"
+	"maps"
+	"slices"
 	"sort"
 	"strconv"
 
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
+)
+
+const (
+	multiCount         = 0x2200 // Number of entries in the multi index
+	multiEntrySize     = 12     // Size of an item entry in the original format
+	multiEntrySizeUOAH = 16     // Size of an item entry since High Seas, with the cliloc
 )
 
 // MultiItem represents a single item within a multi-structure.
@@ -28,13 +38,28 @@ type MultiItem struct {
 // Multi represents a multi-structure (e.g., house, boat) in Ultima Online.
 type Multi struct {
 	sdk   *SDK
+	ID    int // Multi ID, if loaded from the client files, kept by the multis derived from it
 	Items []MultiItem
 }
 
+// MultiImageOptions controls how a multi is drawn by ImageWith.
+type MultiImageOptions struct {
+	MaxZ             int                    // Draw only the items below this elevation (e.g. the lower floors), 0 to draw all
+	Hue              func(MultiItem) uint16 // Hue of each item, nil to draw the art as-is
+	TransparentRoofs bool                   // Blend the items flagged as roof with what lies beneath
+}
+
 // Image renders the multi structure as a full image using art tiles for each MultiItem.
 // The image bounds are computed from the offsets of all items. Each item's art is fetched using sdk.ArtTile,
 // and composited at the correct position. The method returns an ARGB1555 image.
 func (m *Multi) Image() (image.Image, error) {
+	return m.ImageWith(MultiImageOptions{})
+}
+
+// ImageWith renders the multi structure like Image, with the given options, so that a
+// structure can be displayed floor by floor, hued, or with see-through roofs as house
+// design tools do. The image bounds only cover the items which are drawn.
+func (m *Multi) ImageWith(opts MultiImageOptions) (image.Image, error) {
 	if len(m.Items) == 0 {
 		return nil, fmt.Errorf("multi has no items")
 	}
@@ -48,11 +73,24 @@ func (m *Multi) Image() (image.Image, error) {
 	tilePositions := make([]struct {
 		drawX, drawY, artW, artH int
 		item                     MultiItem
+		art                      *Item
 	}, 0, len(m.Items))
 
 	for _, item := range m.Items {
-		art, err := m.sdk.Item(int(item.Item))
-		if err != nil || art == nil {
+		if opts.MaxZ != 0 && int(item.Z) >= opts.MaxZ {
+			continue
+		}
+
+		var hue uint16
+		if opts.Hue != nil {
+			hue = opts.Hue(item)
+		}
+
+		art, err := m.sdk.ItemWithHue(int(item.Item), int(hue), false)
+		if err != nil && hue != 0 {
+			art, err = m.sdk.Item(int(item.Item)) // Invalid hue, draw the art as-is
+		}
+		if err != nil || art == nil || art.Image == nil {
 			continue
 		}
 
@@ -83,7 +121,8 @@ func (m *Multi) Image() (image.Image, error) {
 		tilePositions = append(tilePositions, struct {
 			drawX, drawY, artW, artH int
 			item                     MultiItem
-		}{drawX, drawY, artW, artH, item})
+			art                      *Item
+		}{drawX, drawY, artW, artH, item, art})
 	}
 
 	width := maxDrawX - minDrawX
@@ -114,14 +153,11 @@ func (m *Multi) Image() (image.Image, error) {
 
 	// Second pass: draw tiles at adjusted positions
 	for _, pos := range tilePositions {
-		art, err := m.sdk.Item(int(pos.item.Item))
-		if err != nil || art == nil {
-			continue
-		}
-
+		art := pos.art
 		tileBounds := art.Image.Bounds()
 		drawX := pos.drawX - minDrawX
 		drawY := pos.drawY - minDrawY
+		roof := opts.TransparentRoofs && art.ItemInfo != nil && art.Flags&TileFlagRoof != 0
 
 		for ty := 0; ty < pos.artH; ty++ {
 			for tx := 0; tx < pos.artW; tx++ {
@@ -130,10 +166,15 @@ func (m *Multi) Image() (image.Image, error) {
 				if px < 0 || py < 0 || px >= width || py >= height {
 					continue
 				}
-				col := art.Image.At(tileBounds.Min.X+tx, tileBounds.Min.Y+ty)
-				if c, ok := col.(bitmap.ARGB1555Color); ok && c != 0 {
-					img.Set(px, py, c)
+
+				value, opaque := encodeARGB1555(art.Image.At(tileBounds.Min.X+tx, tileBounds.Min.Y+ty))
+				switch {
+				case !opaque:
+					continue
+				case roof:
+					value = blendARGB1555(uint16(img.At(px, py).(bitmap.ARGB1555Color)), value)
 				}
+				setARGB1555(img, px, py, value)
 			}
 		}
 	}
@@ -141,6 +182,21 @@ func (m *Multi) Image() (image.Image, error) {
 	return img, nil
 }
 
+// blendARGB1555 mixes the color in equal parts with the pixel beneath it, as the client
+// draws translucent items; a transparent pixel beneath leaves the color unchanged
+func blendARGB1555(beneath, value uint16) uint16 {
+	if beneath&0x8000 == 0 {
+		return value
+	}
+
+	var out uint16
+	for shift := 0; shift < 15; shift += 5 {
+		a, b := (beneath>>shift)&0x1F, (value>>shift)&0x1F
+		out |= ((a + b) / 2) << shift
+	}
+	return out
+}
+
 // ToCSV exports all MultiItems to CSV format with headers: item, x, y, z, flags, cliloc.
 // Returns the CSV data as bytes following the standard Go marshaling pattern.
 func (m *Multi) ToCSV() ([]byte, error) {
@@ -177,6 +233,87 @@ func (m *Multi) ToCSV() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Write encodes the items of the multi as they are stored in multi.mul, with 16-byte
+// entries (including the cliloc) if extended is set, or the 12-byte entries of the
+// clients prior to High Seas otherwise.
+func (m *Multi) Write(dst io.Writer, extended bool) error {
+	if _, err := dst.Write(m.encode(extended)); err != nil {
+		return fmt.Errorf("multi: failed to write items: %w", err)
+	}
+	return nil
+}
+
+// encode encodes the items of the multi in the multi.mul layout
+func (m *Multi) encode(extended bool) []byte {
+	entrySize := multiEntrySize
+	if extended {
+		entrySize = multiEntrySizeUOAH
+	}
+
+	out := make([]byte, len(m.Items)*entrySize)
+	for i, item := range m.Items {
+		entry := out[i*entrySize:]
+		binary.LittleEndian.PutUint16(entry[0:], item.Item)
+		binary.LittleEndian.PutUint16(entry[2:], uint16(item.X))
+		binary.LittleEndian.PutUint16(entry[4:], uint16(item.Y))
+		binary.LittleEndian.PutUint16(entry[6:], uint16(item.Z))
+		binary.LittleEndian.PutUint32(entry[8:], item.Flags)
+		if extended {
+			binary.LittleEndian.PutUint32(entry[12:], item.Cliloc)
+		}
+	}
+	return out
+}
+
+// WriteMultis encodes the multis, keyed by their ID, into a multi.mul/multi.idx pair.
+// Items are written with 16-byte entries if extended is set, or with the 12-byte
+// entries of the clients prior to High Seas otherwise.
+func WriteMultis(multiMul, multiIdx io.Writer, multis map[int]*Multi, extended bool) error {
+	w := mul.NewWriter(multiMul, multiIdx)
+	for _, id := range slices.Sorted(maps.Keys(multis)) {
+		if id < 0 || id >= multiCount {
+			return fmt.Errorf("multi: ID %d out of range [0-%d]", id, multiCount-1)
+		}
+
+		var data []byte
+		if multi := multis[id]; multi != nil {
+			data = multi.encode(extended)
+		}
+
+		if err := w.Write(uint32(id), data, 0); err != nil {
+			return fmt.Errorf("multi: failed to write entry %d: %w", id, err)
+		}
+	}
+
+	return w.Pad(multiCount)
+}
+
+// WriteMulti writes every multi of the SDK into a multi.mul/multi.idx pair, replacing
+// the one with the given ID (or adding it, if it did not exist) by the given multi, so
+// that edited houses and boats can be persisted. Entries are written in the 16-byte
+// format read by Multi, and an error is returned if any existing multi cannot be read.
+func (s *SDK) WriteMulti(multiMul, multiIdx io.Writer, id int, multi *Multi) error {
+	file, err := s.loadMulti()
+	if err != nil {
+		return err
+	}
+
+	multis := map[int]*Multi{id: multi}
+	for key := range file.Entries() {
+		if int(key) == id {
+			continue
+		}
+
+		existing, err := s.Multi(int(key))
+		if err != nil {
+			return fmt.Errorf("multi: failed to read entry %d: %w", key, err)
+		}
+		multis[int(key)] = existing
+	}
+
+	return WriteMultis(multiMul, multiIdx, multis, true)
+}
+
 // Multi returns a Multi structure by id, loading from multi.mul/multi.idx
 func (s *SDK) Multi(id int) (*Multi, error) {
 	file, err := s.loadMulti()
@@ -193,7 +330,7 @@ func (s *SDK) Multi(id int) (*Multi, error) {
 	}
 
 	// Assume UOAHS format for now
-	const entrySize = 16
+	const entrySize = multiEntrySizeUOAH
 
 	// Parse multi data
 	var items []MultiItem
@@ -210,6 +347,7 @@ func (s *SDK) Multi(id int) (*Multi, error) {
 
 	return &Multi{
 		sdk:   s,
+		ID:    id,
 		Items: items,
 	}, nil
 }
@@ -0,0 +1,275 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+)
+
+const (
+	designPlaneCount  = 9     // Ground, four floors and four levels of walls
+	designPlaneSize   = 0x400 // Maximum size of the tiles of a plane, in bytes
+	designStairsLimit = 750   // Maximum number of items in a buffer of stairs
+)
+
+// designLevels are the elevations of the ground and of the floors of a custom house
+var designLevels = [5]int16{0, 7, 27, 47, 67}
+
+// designPlane describes how the tiles of a plane map onto the foundation
+type designPlane struct {
+	offset        image.Point // Offset of the first tile of the plane
+	width, height int         // Number of tiles of the plane along X and Y
+	z             int16       // Elevation of the tiles of the plane
+}
+
+// planeOf returns the layout of the plane with the given index within the bounds of the
+// foundation: the ground covers the whole foundation, the floors exclude its borders
+// and the walls exclude its southern row
+func planeOf(index int, bounds image.Rectangle) designPlane {
+	w, h := bounds.Dx(), bounds.Dy()
+	switch {
+	case index == 0:
+		return designPlane{offset: bounds.Min, width: w, height: h}
+	case index < 5:
+		return designPlane{offset: bounds.Min.Add(image.Pt(1, 1)), width: w - 1, height: h - 2, z: designLevels[index]}
+	default:
+		return designPlane{offset: bounds.Min, width: w, height: h - 1, z: designLevels[index-4]}
+	}
+}
+
+// ToDesign encodes the multi in the custom house design format, as sent by the server
+// to describe a customizable house: the plane count followed by the zlib-compressed
+// planes (the payload of the 0xD8 packet after its header). The bounds are the offsets
+// covered by the foundation, with an exclusive maximum. Items at the ground or floor
+// levels are laid out on planes of tiles, while stairs, items at other elevations and
+// items outside of the planes are listed individually.
+func (m *Multi) ToDesign(bounds image.Rectangle) ([]byte, error) {
+	if bounds.Dx() < 1 || bounds.Dy() < 2 || bounds.Dx()*bounds.Dy()*2 > designPlaneSize {
+		return nil, fmt.Errorf("multi: invalid design bounds %v", bounds)
+	}
+
+	var items []ItemInfo
+	if m.sdk != nil {
+		if index, err := m.sdk.itemIndex(); err == nil {
+			items = index.items
+		}
+	}
+
+	var planes [designPlaneCount][]byte
+	var stairs []byte
+	for _, item := range m.Items {
+		if plane, offset, ok := designIndex(item, items, bounds); ok {
+			if planes[plane] == nil {
+				layout := planeOf(plane, bounds)
+				planes[plane] = make([]byte, layout.width*layout.height*2)
+			}
+
+			binary.BigEndian.PutUint16(planes[plane][offset:], item.Item)
+			continue
+		}
+
+		if item.X != int16(int8(item.X)) || item.Y != int16(int8(item.Y)) || item.Z != int16(int8(item.Z)) {
+			return nil, fmt.Errorf("multi: item %#x at (%d,%d,%d) cannot be encoded in a design", item.Item, item.X, item.Y, item.Z)
+		}
+
+		stairs = binary.BigEndian.AppendUint16(stairs, item.Item)
+		stairs = append(stairs, byte(item.X), byte(item.Y), byte(item.Z))
+	}
+
+	var out bytes.Buffer
+	out.WriteByte(0) // Plane count, updated once known
+
+	count := 0
+	for index, plane := range planes {
+		if plane != nil {
+			if err := writeDesignPlane(&out, byte(0x20|index), plane); err != nil {
+				return nil, err
+			}
+			count++
+		}
+	}
+
+	for i := 0; i*designStairsLimit*5 < len(stairs); i++ {
+		if designPlaneCount+i > 0x0F {
+			return nil, fmt.Errorf("multi: too many stairs for a design")
+		}
+
+		chunk := stairs[i*designStairsLimit*5 : min(len(stairs), (i+1)*designStairsLimit*5)]
+		if err := writeDesignPlane(&out, byte(designPlaneCount+i), chunk); err != nil {
+			return nil, err
+		}
+		count++
+	}
+
+	data := out.Bytes()
+	data[0] = byte(count)
+	return data, nil
+}
+
+// designIndex returns the plane and the offset within the plane of the item, if it can
+// be laid out on a plane; floors (items without height) go on the floor planes, and
+// other items on the wall planes
+func designIndex(item MultiItem, items []ItemInfo, bounds image.Rectangle) (plane, offset int, ok bool) {
+	level := -1
+	for i, z := range designLevels {
+		if item.Z == z {
+			level = i
+		}
+	}
+
+	switch {
+	case level < 0:
+		return 0, 0, false
+	case level == 0:
+		plane = 0
+	case int(item.Item) < len(items) && items[item.Item].Height == 0:
+		plane = level
+	default:
+		plane = level + 4
+	}
+
+	layout := planeOf(plane, bounds)
+	x, y := int(item.X)-layout.offset.X, int(item.Y)-layout.offset.Y
+	if x < 0 || y < 0 || x >= layout.width || y >= layout.height {
+		return 0, 0, false
+	}
+
+	return plane, (x*layout.height + y) * 2, true
+}
+
+// writeDesignPlane compresses the plane and writes it with its 4-byte header: the kind
+// of plane, then the 12-bit sizes of the uncompressed and compressed data
+func writeDesignPlane(dst *bytes.Buffer, kind byte, plane []byte) error {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	if _, err := w.Write(plane); err != nil {
+		return fmt.Errorf("multi: failed to compress design plane: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("multi: failed to compress design plane: %w", err)
+	}
+
+	size, packed := len(plane), compressed.Len()
+	if size > 0xFFF || packed > 0xFFF {
+		return fmt.Errorf("multi: design plane is too large (%d bytes)", size)
+	}
+
+	dst.Write([]byte{kind, byte(size), byte(packed), byte((size>>4)&0xF0 | (packed>>8)&0x0F)})
+	dst.Write(compressed.Bytes())
+	return nil
+}
+
+// MultiFromDesign decodes a custom house design, as produced by ToDesign or sent by the
+// server in the 0xD8 packet (from the plane count onwards), into a Multi structure.
+// The bounds are the offsets covered by the foundation, with an exclusive maximum.
+func (s *SDK) MultiFromDesign(data []byte, bounds image.Rectangle) (*Multi, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("multi: design data is empty")
+	}
+
+	var items []MultiItem
+	count, data := int(data[0]), data[1:]
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("multi: design plane %d is truncated", i)
+		}
+
+		header := binary.BigEndian.Uint32(data)
+		mode, kind := int(header>>28), int(header>>24)&0x0F
+		size := int((header>>16)&0xFF | (header&0xF0)<<4)
+		packed := int((header>>8)&0xFF | (header&0x0F)<<8)
+		if len(data) < 4+packed {
+			return nil, fmt.Errorf("multi: design plane %d is truncated", i)
+		}
+
+		plane, err := inflateDesignPlane(data[4:4+packed], size)
+		if err != nil {
+			return nil, fmt.Errorf("multi: design plane %d: %w", i, err)
+		}
+		data = data[4+packed:]
+
+		switch mode {
+		case 0: // Individual items, with their offsets and elevation
+			for j := 0; j+5 <= len(plane); j += 5 {
+				if id := binary.BigEndian.Uint16(plane[j:]); id != 0 {
+					items = append(items, MultiItem{
+						Item:  id,
+						X:     int16(int8(plane[j+2])),
+						Y:     int16(int8(plane[j+3])),
+						Z:     int16(int8(plane[j+4])),
+						Flags: 1,
+					})
+				}
+			}
+
+		case 1: // Items of a level, with their offsets
+			var z int16
+			if kind > 0 {
+				z = designLevels[(kind-1)%4+1]
+			}
+
+			for j := 0; j+4 <= len(plane); j += 4 {
+				if id := binary.BigEndian.Uint16(plane[j:]); id != 0 {
+					items = append(items, MultiItem{
+						Item:  id,
+						X:     int16(int8(plane[j+2])),
+						Y:     int16(int8(plane[j+3])),
+						Z:     z,
+						Flags: 1,
+					})
+				}
+			}
+
+		case 2: // Tiles of a plane, column by column
+			if kind >= designPlaneCount {
+				return nil, fmt.Errorf("multi: design plane %d has an invalid level %d", i, kind)
+			}
+
+			layout := planeOf(kind, bounds)
+			if layout.height <= 0 {
+				return nil, fmt.Errorf("multi: invalid design bounds %v", bounds)
+			}
+
+			for j := 0; j+2 <= len(plane); j += 2 {
+				if id := binary.BigEndian.Uint16(plane[j:]); id != 0 {
+					items = append(items, MultiItem{
+						Item:  id,
+						X:     int16(layout.offset.X + (j/2)/layout.height),
+						Y:     int16(layout.offset.Y + (j/2)%layout.height),
+						Z:     layout.z,
+						Flags: 1,
+					})
+				}
+			}
+
+		default:
+			return nil, fmt.Errorf("multi: design plane %d has an unsupported mode %d", i, mode)
+		}
+	}
+
+	return &Multi{
+		sdk:   s,
+		Items: items,
+	}, nil
+}
+
+// inflateDesignPlane decompresses the data of a plane of the given size
+func inflateDesignPlane(data []byte, size int) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out := make([]byte, size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
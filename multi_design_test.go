@@ -0,0 +1,64 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"cmp"
+	"image"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulti_Design_RoundTrip(t *testing.T) {
+	m := openTestWorld(t, nil, nil, map[int]ItemInfo{
+		0x10: {Name: "floor"},
+		0x11: {Name: "wall", Height: 20},
+	})
+
+	bounds := image.Rect(-3, -3, 4, 5) // 7x8 foundation
+	multi := &Multi{sdk: m.sdk, Items: []MultiItem{
+		{Item: 0x11, X: -3, Y: -3, Z: 0, Flags: 1},  // Ground, corner of the foundation
+		{Item: 0x11, X: 3, Y: 4, Z: 0, Flags: 1},    // Ground, opposite corner
+		{Item: 0x10, X: 0, Y: 0, Z: 7, Flags: 1},    // Floor of the first level
+		{Item: 0x11, X: -3, Y: 3, Z: 7, Flags: 1},   // Wall of the first level
+		{Item: 0x10, X: 2, Y: 2, Z: 67, Flags: 1},   // Floor of the top level
+		{Item: 0x10, X: -3, Y: -3, Z: 27, Flags: 1}, // Floor on the border, listed individually
+		{Item: 0x11, X: 1, Y: -1, Z: 12, Flags: 1},  // Stairs, listed individually
+	}}
+
+	data, err := multi.ToDesign(bounds)
+	require.NoError(t, err)
+	assert.Equal(t, byte(5), data[0], "ground, two floors, one level of walls and the individual items")
+
+	decoded, err := m.sdk.MultiFromDesign(data, bounds)
+	require.NoError(t, err)
+
+	sortItems := func(items []MultiItem) []MultiItem {
+		items = slices.Clone(items)
+		slices.SortFunc(items, func(a, b MultiItem) int {
+			return cmp.Or(cmp.Compare(a.Z, b.Z), cmp.Compare(a.X, b.X), cmp.Compare(a.Y, b.Y))
+		})
+		return items
+	}
+	assert.Equal(t, sortItems(multi.Items), sortItems(decoded.Items))
+}
+
+func TestMulti_Design_Invalid(t *testing.T) {
+	multi := &Multi{Items: []MultiItem{{Item: 0x11, X: 200, Y: 0, Z: 12}}}
+	_, err := multi.ToDesign(image.Rect(0, 0, 8, 8))
+	assert.Error(t, err, "offsets do not fit in a stairs entry")
+
+	_, err = multi.ToDesign(image.Rect(0, 0, 40, 40))
+	assert.Error(t, err, "foundation too large")
+
+	sdk := &SDK{}
+	_, err = sdk.MultiFromDesign(nil, image.Rect(0, 0, 8, 8))
+	assert.Error(t, err)
+
+	_, err = sdk.MultiFromDesign([]byte{1, 0x20, 0x10, 0x05, 0x00, 1, 2}, image.Rect(0, 0, 8, 8))
+	assert.Error(t, err, "truncated plane")
+}
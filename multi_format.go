@@ -0,0 +1,285 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kelindar/ultima-sdk/internal/uofile"
+)
+
+// multiJSON is the JSON representation of a multi, with its metadata
+type multiJSON struct {
+	ID    int             `json:"id"`
+	Name  string          `json:"name,omitempty"`
+	Items []multiItemJSON `json:"items"`
+}
+
+// multiItemJSON is the JSON representation of an item of a multi
+type multiItemJSON struct {
+	Item   uint16 `json:"item"`
+	X      int16  `json:"x"`
+	Y      int16  `json:"y"`
+	Z      int16  `json:"z"`
+	Flags  uint32 `json:"flags"`
+	Cliloc uint32 `json:"cliloc,omitempty"`
+	Name   string `json:"name,omitempty"` // Localized name of the cliloc, informative only
+}
+
+// Name returns the name of the multi (such as "Small Boat [north]"), looked up by its ID
+// in the list of the client's multis embedded in the SDK, or an empty string if the ID
+// is not listed. The client has no cliloc for the multis themselves, only their items
+// have one (see ItemNames). The name follows the ID alone: multis parsed from JSON, CSV
+// or UOA files, or derived from another one, keep whatever ID they were given and may
+// no longer match the listed multi.
+func (m *Multi) Name() string {
+	return uofile.MultiNameByID(m.ID)
+}
+
+// ItemNames returns a human-readable name for every item of the multi, in the order of
+// Items: the localized text of its cliloc if it has one, or the name of the item in the
+// tile data otherwise. Names which cannot be resolved are left empty.
+func (m *Multi) ItemNames() []string {
+	names := make([]string, len(m.Items))
+	if m.sdk == nil {
+		return names
+	}
+
+	index, _ := m.sdk.itemIndex()
+	for i, item := range m.Items {
+		if name := m.clilocText(item.Cliloc); name != "" {
+			names[i] = name
+			continue
+		}
+
+		if index != nil && int(item.Item) < len(index.items) {
+			names[i] = index.items[item.Item].Name
+		}
+	}
+	return names
+}
+
+// ToJSON exports the multi to JSON, with its ID, name and items. Items with a cliloc
+// also carry its localized text, if the multi is attached to an SDK with cliloc files.
+func (m *Multi) ToJSON() ([]byte, error) {
+	out := multiJSON{
+		ID:    m.ID,
+		Name:  m.Name(),
+		Items: make([]multiItemJSON, 0, len(m.Items)),
+	}
+
+	for _, item := range m.Items {
+		out.Items = append(out.Items, multiItemJSON{
+			Item:   item.Item,
+			X:      item.X,
+			Y:      item.Y,
+			Z:      item.Z,
+			Flags:  item.Flags,
+			Cliloc: item.Cliloc,
+			Name:   m.clilocText(item.Cliloc),
+		})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("multi: failed to encode JSON: %w", err)
+	}
+	return data, nil
+}
+
+// MultiFromJSON parses JSON data produced by ToJSON and returns a Multi structure.
+// The names are informative only and are ignored.
+func (s *SDK) MultiFromJSON(data []byte) (*Multi, error) {
+	var in multiJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("multi: failed to parse JSON: %w", err)
+	}
+
+	items := make([]MultiItem, 0, len(in.Items))
+	for _, item := range in.Items {
+		items = append(items, MultiItem{
+			Item:   item.Item,
+			X:      item.X,
+			Y:      item.Y,
+			Z:      item.Z,
+			Flags:  item.Flags,
+			Cliloc: item.Cliloc,
+		})
+	}
+
+	return &Multi{
+		sdk:   s,
+		ID:    in.ID,
+		Items: items,
+	}, nil
+}
+
+// clilocText returns the localized text of the cliloc, or an empty string if it
+// cannot be resolved
+func (m *Multi) clilocText(cliloc uint32) string {
+	if m.sdk == nil || cliloc == 0 {
+		return ""
+	}
+
+	entry, err := m.sdk.StringEntry(int(cliloc), "enu")
+	if err != nil || len(entry) < 5 {
+		return ""
+	}
+	return entry.Text()
+}
+
+// ToUOA exports all MultiItems in the UO Architect text format (.uoa/.txt), as
+// produced by house editors: a header with the version, template ID, item version
+// and number of components, followed by one "item x y z flags" line per item.
+func (m *Multi) ToUOA() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "6 version\n%d template id\n-1 item version\n%d num components\n", m.ID, len(m.Items))
+	for _, item := range m.Items {
+		fmt.Fprintf(&buf, "%d %d %d %d %d\n", item.Item, item.X, item.Y, item.Z, item.Flags)
+	}
+	return buf.Bytes(), nil
+}
+
+// MultiFromUOA parses data in the UO Architect text format (.uoa/.txt) and returns a
+// Multi structure. Header lines (a number followed by a description) are skipped and
+// every other line is expected to hold the item, x, y, z and flags of a component.
+// The template ID of the header, if any, becomes the ID of the multi.
+func (s *SDK) MultiFromUOA(data []byte) (*Multi, error) {
+	var id int
+	var items []MultiItem
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) > 1 && !isNumber(fields[1]):
+			if strings.EqualFold(fields[1], "template") {
+				id, _ = strconv.Atoi(fields[0])
+			}
+			continue // Header line, such as "6 version"
+		case len(fields) < 5:
+			return nil, fmt.Errorf("multi: invalid UOA line %d, expected 5 fields (item x y z flags), got %d", line, len(fields))
+		}
+
+		itemID, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("multi: invalid item in line %d: %w", line, err)
+		}
+
+		var offsets [3]int64
+		for i := range offsets {
+			if offsets[i], err = strconv.ParseInt(fields[i+1], 10, 16); err != nil {
+				return nil, fmt.Errorf("multi: invalid offset in line %d: %w", line, err)
+			}
+		}
+
+		flags, err := strconv.ParseUint(fields[4], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("multi: invalid flags in line %d: %w", line, err)
+		}
+
+		items = append(items, MultiItem{
+			Item:  uint16(itemID),
+			X:     int16(offsets[0]),
+			Y:     int16(offsets[1]),
+			Z:     int16(offsets[2]),
+			Flags: uint32(flags),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("multi: failed to read UOA data: %w", err)
+	}
+
+	return &Multi{
+		sdk:   s,
+		ID:    id,
+		Items: items,
+	}, nil
+}
+
+// ToWSC exports all MultiItems in the WorldForge world save format (.wsc), with one
+// WORLDITEM section per item, positioned at its offset from the center of the multi.
+func (m *Multi) ToWSC() ([]byte, error) {
+	var buf bytes.Buffer
+	for i, item := range m.Items {
+		fmt.Fprintf(&buf, "SECTION WORLDITEM %d\n{\n", i)
+		fmt.Fprintf(&buf, "SERIAL %d\nNAME #\nID %d\n", i, item.Item)
+		fmt.Fprintf(&buf, "X %d\nY %d\nZ %d\n", item.X, item.Y, item.Z)
+		fmt.Fprint(&buf, "COLOR 0\nCONT -1\nTYPE 0\nAMOUNT 1\nWEIGHT 255\nOWNER -1\nSPAWN -1\nVALUE 1\n}\n\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// MultiFromWSC parses data in the WorldForge world save format (.wsc) and returns a
+// Multi structure, with one visible item per WORLDITEM section. Keys other than the
+// ID and the X, Y and Z offsets are ignored.
+func (s *SDK) MultiFromWSC(data []byte) (*Multi, error) {
+	var items []MultiItem
+	var item *MultiItem
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		key, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(key) {
+		case "SECTION":
+			if item != nil {
+				items = append(items, *item)
+			}
+
+			item = nil
+			if strings.HasPrefix(strings.ToUpper(value), "WORLDITEM") {
+				item = &MultiItem{Flags: 1}
+			}
+			continue
+		case "ID", "X", "Y", "Z":
+			if item == nil {
+				continue
+			}
+		default:
+			continue
+		}
+
+		v, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("multi: invalid %s in line %d: %w", key, line, err)
+		}
+
+		switch strings.ToUpper(key) {
+		case "ID":
+			item.Item = uint16(v)
+		case "X":
+			item.X = int16(v)
+		case "Y":
+			item.Y = int16(v)
+		case "Z":
+			item.Z = int16(v)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("multi: failed to read WSC data: %w", err)
+	}
+
+	if item != nil {
+		items = append(items, *item)
+	}
+
+	return &Multi{
+		sdk:   s,
+		Items: items,
+	}, nil
+}
+
+// isNumber returns whether the text is a base 10 integer
+func isNumber(text string) bool {
+	_, err := strconv.ParseInt(text, 10, 64)
+	return err == nil
+}
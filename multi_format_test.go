@@ -0,0 +1,123 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulti_JSON_RoundTrip(t *testing.T) {
+	original := &Multi{ID: 0, Items: []MultiItem{
+		{Item: 100, X: -10, Y: 5, Z: 0, Flags: 1, Cliloc: 1020000},
+		{Item: 200, X: 0, Y: 0, Z: 10, Flags: 0},
+	}}
+
+	data, err := original.ToJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"name": "Small Boat [north]"`)
+	assert.Contains(t, string(data), `"cliloc": 1020000`)
+
+	restored, err := (&SDK{}).MultiFromJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, original.ID, restored.ID)
+	assert.Equal(t, original.Items, restored.Items)
+
+	_, err = (&SDK{}).MultiFromJSON([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestMulti_Name(t *testing.T) {
+	assert.Equal(t, "Small Boat [east]", (&Multi{ID: 1}).Name())
+	assert.Equal(t, "", (&Multi{ID: -1}).Name())
+}
+
+func TestMulti_UOA_RoundTrip(t *testing.T) {
+	original := &Multi{ID: 42, Items: []MultiItem{
+		{Item: 100, X: -10, Y: 5, Z: 0, Flags: 1},
+		{Item: 200, X: 0, Y: 0, Z: 10, Flags: 0},
+	}}
+
+	data, err := original.ToUOA()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "2 num components\n100 -10 5 0 1\n")
+
+	restored, err := (&SDK{}).MultiFromUOA(data)
+	assert.NoError(t, err)
+	assert.Equal(t, original.ID, restored.ID)
+	assert.Equal(t, original.Items, restored.Items)
+}
+
+func TestSDK_MultiFromUOA(t *testing.T) {
+	sdk := &SDK{}
+	multi, err := sdk.MultiFromUOA([]byte("6 version\r\n1 template id\r\n-1 item version\r\n1 num components\r\n1313 -2 3 7 1\r\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []MultiItem{{Item: 1313, X: -2, Y: 3, Z: 7, Flags: 1}}, multi.Items)
+	assert.Equal(t, 1, multi.ID)
+
+	_, err = sdk.MultiFromUOA([]byte("1313 -2 3\n"))
+	assert.Error(t, err)
+
+	_, err = sdk.MultiFromUOA([]byte("1313 -2 x 7 1\n"))
+	assert.Error(t, err)
+}
+
+func TestMulti_WSC_RoundTrip(t *testing.T) {
+	original := &Multi{Items: []MultiItem{
+		{Item: 100, X: -10, Y: 5, Z: 0, Flags: 1},
+		{Item: 200, X: 0, Y: 0, Z: 10, Flags: 1},
+	}}
+
+	data, err := original.ToWSC()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "SECTION WORLDITEM 1\n{\nSERIAL 1\nNAME #\nID 200\n")
+
+	restored, err := (&SDK{}).MultiFromWSC(data)
+	assert.NoError(t, err)
+	assert.Equal(t, original.Items, restored.Items)
+}
+
+func TestSDK_MultiFromWSC(t *testing.T) {
+	sdk := &SDK{}
+	multi, err := sdk.MultiFromWSC([]byte("SECTION WORLDITEM 0\n{\nID 1313\nX -2\nY 3\nZ 7\nCOLOR 0\n}\nSECTION CHARACTER 1\n{\nID 400\n}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []MultiItem{{Item: 1313, X: -2, Y: 3, Z: 7, Flags: 1}}, multi.Items)
+
+	_, err = sdk.MultiFromWSC([]byte("SECTION WORLDITEM 0\n{\nID abc\n}\n"))
+	assert.Error(t, err)
+}
+
+func TestMulti_ItemNames(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTiledata(t, dir, nil, map[int]ItemInfo{
+		0x10: {Name: "wall"},
+		0x11: {Name: "door"},
+	})
+
+	cliloc := []byte{2, 0, 0, 0, 0, 0}
+	cliloc = binary.LittleEndian.AppendUint32(cliloc, 1020000)
+	cliloc = append(cliloc, 0)
+	cliloc = binary.LittleEndian.AppendUint16(cliloc, uint16(len("Stone Wall")))
+	cliloc = append(cliloc, "Stone Wall"...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cliloc.enu"), cliloc, 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	multi := &Multi{sdk: sdk, Items: []MultiItem{
+		{Item: 0x10, Cliloc: 1020000}, // Resolved through the cliloc
+		{Item: 0x11},                  // Resolved through the tile data
+		{Item: 0x10, Cliloc: 1020001}, // Unknown cliloc, falls back to the tile data
+		{Item: 0x3FFF},                // Unknown item
+	}}
+
+	assert.Equal(t, []string{"Stone Wall", "door", "wall", ""}, multi.ItemNames())
+	assert.Equal(t, []string{""}, (&Multi{Items: []MultiItem{{Item: 0x10}}}).ItemNames())
+}
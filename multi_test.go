@@ -5,14 +5,19 @@ package ultima
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/csv"
 	"fmt"
 	"image"
 	"image/png"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMulti_Load(t *testing.T) {
@@ -293,3 +298,160 @@ func savePng(img image.Image, name string) error {
 	defer file.Close()
 	return png.Encode(file, img)
 }
+
+func TestMulti_Write(t *testing.T) {
+	multi := &Multi{Items: []MultiItem{
+		{Item: 0x0064, X: -1, Y: 2, Z: 7, Flags: 1, Cliloc: 1020000},
+		{Item: 0x0065, X: 3, Y: -4, Z: -5, Flags: 0},
+	}}
+
+	var legacy, extended bytes.Buffer
+	assert.NoError(t, multi.Write(&legacy, false))
+	assert.NoError(t, multi.Write(&extended, true))
+	assert.Equal(t, 2*multiEntrySize, legacy.Len())
+	assert.Equal(t, 2*multiEntrySizeUOAH, extended.Len())
+	assert.Equal(t, legacy.Bytes()[:multiEntrySize], extended.Bytes()[:multiEntrySize])
+}
+
+func TestSDK_WriteMulti(t *testing.T) {
+	writeFiles := func(dir string, fn func(mul, idx *bytes.Buffer) error) {
+		var mul, idx bytes.Buffer
+		assert.NoError(t, fn(&mul, &idx))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "multi.mul"), mul.Bytes(), 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "multi.idx"), idx.Bytes(), 0644))
+	}
+
+	house := &Multi{Items: []MultiItem{{Item: 0x0064, X: -1, Y: 2, Z: 7, Flags: 1, Cliloc: 1020000}}}
+	boat := &Multi{Items: []MultiItem{{Item: 0x3E4E, X: 0, Y: 0, Z: 0, Flags: 1}}}
+
+	// Write the original set of multis and load them back
+	source := t.TempDir()
+	writeFiles(source, func(mul, idx *bytes.Buffer) error {
+		return WriteMultis(mul, idx, map[int]*Multi{0: house, 5: boat}, true)
+	})
+
+	sdk, err := Open(source)
+	assert.NoError(t, err)
+	defer sdk.Close()
+
+	loaded, err := sdk.Multi(0)
+	assert.NoError(t, err)
+	assert.Equal(t, house.Items, loaded.Items)
+
+	// Replace the house and add a new multi, keeping the boat
+	edited := &Multi{Items: append(slices.Clone(house.Items), MultiItem{Item: 0x0065, X: 1, Y: 1, Z: 0, Flags: 1})}
+	target := t.TempDir()
+	writeFiles(target, func(mul, idx *bytes.Buffer) error {
+		return sdk.WriteMulti(mul, idx, 0, edited)
+	})
+
+	out, err := Open(target)
+	assert.NoError(t, err)
+	defer out.Close()
+
+	loaded, err = out.Multi(0)
+	assert.NoError(t, err)
+	assert.Equal(t, edited.Items, loaded.Items)
+
+	loaded, err = out.Multi(5)
+	assert.NoError(t, err)
+	assert.Equal(t, boat.Items, loaded.Items)
+
+	_, err = out.Multi(1)
+	assert.Error(t, err)
+
+	// Multis which cannot be read are reported rather than dropped
+	corrupt := t.TempDir()
+	writeFiles(corrupt, func(mul, idx *bytes.Buffer) error {
+		if err := WriteMultis(mul, idx, map[int]*Multi{0: house, 5: boat}, true); err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(idx.Bytes()[5*12:], 0x10000) // Beyond the end of multi.mul
+		return nil
+	})
+
+	broken, err := Open(corrupt)
+	assert.NoError(t, err)
+	defer broken.Close()
+
+	err = broken.WriteMulti(&bytes.Buffer{}, &bytes.Buffer{}, 0, edited)
+	assert.ErrorContains(t, err, "entry 5")
+}
+
+func TestMulti_ImageWith(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTiledata(t, dir, nil, map[int]ItemInfo{
+		0x10: {Name: "wall"},
+		0x11: {Name: "roof", Flags: TileFlagRoof},
+	})
+
+	fill := func(value uint16) image.Image {
+		img := bitmap.NewARGB1555(image.Rect(0, 0, 10, 10))
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				img.Set(x, y, bitmap.ARGB1555Color(value))
+			}
+		}
+		return img
+	}
+
+	var artMul, artIdx bytes.Buffer
+	require.NoError(t, WriteArt(&artMul, &artIdx, []Art{
+		{ID: 0x4010, Image: fill(0xFC00)}, // Red
+		{ID: 0x4011, Image: fill(0x801F)}, // Blue
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "art.mul"), artMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "artidx.mul"), artIdx.Bytes(), 0644))
+
+	// A single block of hues, where hue 1 turns every color into green
+	hues := make([]byte, 708)
+	for i := 0; i < 32; i++ {
+		binary.LittleEndian.PutUint16(hues[4+88+i*2:], 0x03E0)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hues.mul"), hues, 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	multi := &Multi{sdk: sdk, Items: []MultiItem{
+		{Item: 0x10, X: 0, Y: 0, Z: 0, Flags: 1},
+		{Item: 0x11, X: 0, Y: 0, Z: 1, Flags: 1},
+	}}
+
+	pixel := func(img image.Image, x, y int) uint16 {
+		return uint16(img.At(x, y).(bitmap.ARGB1555Color))
+	}
+
+	// The roof is drawn 4 pixels above the wall, covering it
+	img, err := multi.Image()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 10, 14), img.Bounds())
+	assert.Equal(t, uint16(0x801F), pixel(img, 5, 5))
+	assert.Equal(t, uint16(0xFC00), pixel(img, 5, 12))
+
+	// Only the floor below the cutoff is drawn
+	img, err = multi.ImageWith(MultiImageOptions{MaxZ: 1})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), img.Bounds())
+
+	// The roof is blended with the wall beneath it
+	img, err = multi.ImageWith(MultiImageOptions{TransparentRoofs: true})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x801F), pixel(img, 5, 0))
+	assert.Equal(t, uint16(0xBC0F), pixel(img, 5, 5))
+
+	// Items are hued individually
+	img, err = multi.ImageWith(MultiImageOptions{Hue: func(item MultiItem) uint16 {
+		if item.Item == 0x10 {
+			return 1
+		}
+		return 0
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x801F), pixel(img, 5, 5))
+	assert.Equal(t, uint16(0x83E0), pixel(img, 5, 12))
+
+	_, err = multi.ImageWith(MultiImageOptions{MaxZ: -1})
+	assert.Error(t, err)
+}
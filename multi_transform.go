@@ -0,0 +1,132 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"slices"
+)
+
+// Rotate90 returns a copy of the multi rotated by 90 degrees clockwise, as seen on
+// screen, around its center. Walls facing east and south are swapped where their
+// counterpart can be found in the tile data (see Mirror).
+func (m *Multi) Rotate90() *Multi {
+	return m.transform(func(x, y int16) (int16, int16) {
+		return -y, x
+	})
+}
+
+// Mirror returns a copy of the multi flipped horizontally, as seen on screen, by
+// swapping the X and Y offsets of its items. Walls facing east and south are swapped
+// where their counterpart can be found in the tile data: a wall is paired with the
+// adjacent item ID which has the same name, flags and height, if there is exactly one.
+// Other items keep their tile ID.
+func (m *Multi) Mirror() *Multi {
+	return m.transform(func(x, y int16) (int16, int16) {
+		return y, x
+	})
+}
+
+// Merge returns a new multi with the items of both multis, the items of the other one
+// being moved by the given offset. Items of the other multi identical to an item
+// already present at the same location (same tile ID, offsets and elevation) are
+// dropped, so that modules sharing walls can be assembled into larger structures. The
+// new multi keeps the ID of this one.
+func (m *Multi) Merge(other *Multi, dx, dy, dz int16) *Multi {
+	type key struct {
+		item    uint16
+		x, y, z int16
+	}
+
+	out := &Multi{sdk: m.sdk, ID: m.ID, Items: make([]MultiItem, 0, len(m.Items)+len(other.Items))}
+	seen := make(map[key]struct{}, cap(out.Items))
+	add := func(item MultiItem) {
+		k := key{item.Item, item.X, item.Y, item.Z}
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			out.Items = append(out.Items, item)
+		}
+	}
+
+	for _, item := range m.Items {
+		add(item)
+	}
+
+	for _, item := range other.Items {
+		item.X += dx
+		item.Y += dy
+		item.Z += dz
+		add(item)
+	}
+	return out
+}
+
+// Extract returns a new multi with the items whose offsets lie within the rectangle,
+// keeping their offsets unchanged, so that a part of a structure can be reused on its
+// own or merged elsewhere. As with the other transformations, the multi keeps its ID.
+func (m *Multi) Extract(r image.Rectangle) *Multi {
+	out := &Multi{sdk: m.sdk, ID: m.ID}
+	for _, item := range m.Items {
+		if image.Pt(int(item.X), int(item.Y)).In(r) {
+			out.Items = append(out.Items, item)
+		}
+	}
+	return out
+}
+
+// transform returns a copy of the multi with the offsets of the items remapped, and the
+// directional walls swapped with their counterpart
+func (m *Multi) transform(fn func(x, y int16) (int16, int16)) *Multi {
+	var items []ItemInfo
+	if m.sdk != nil {
+		if index, err := m.sdk.itemIndex(); err == nil {
+			items = index.items
+		}
+	}
+
+	out := &Multi{sdk: m.sdk, ID: m.ID, Items: slices.Clone(m.Items)}
+	for i, item := range out.Items {
+		out.Items[i].X, out.Items[i].Y = fn(item.X, item.Y)
+		out.Items[i].Item = counterpartWall(items, item.Item)
+	}
+	return out
+}
+
+// counterpartWall returns the item ID of the same wall facing the other direction, or
+// the ID itself if the item is not a wall or has no unambiguous counterpart. Pairs are
+// only accepted if mutual, so that the swap can always be undone.
+func counterpartWall(items []ItemInfo, id uint16) uint16 {
+	if other, ok := adjacentWall(items, int(id)); ok {
+		if back, ok := adjacentWall(items, other); ok && back == int(id) {
+			return uint16(other)
+		}
+	}
+	return id
+}
+
+// adjacentWall returns the single adjacent item ID which looks like the same wall
+func adjacentWall(items []ItemInfo, id int) (int, bool) {
+	if id >= len(items) || items[id].Flags&TileFlagWall == 0 {
+		return 0, false
+	}
+
+	same := func(other int) bool {
+		if other < 0 || other >= len(items) {
+			return false
+		}
+
+		a, b := items[id], items[other]
+		return a.Name == b.Name && a.Flags == b.Flags && a.Height == b.Height
+	}
+
+	prev, next := same(id-1), same(id+1)
+	switch {
+	case prev && !next:
+		return id - 1, true
+	case next && !prev:
+		return id + 1, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulti_Rotate90(t *testing.T) {
+	multi := &Multi{ID: 7, Items: []MultiItem{
+		{Item: 0x10, X: 1, Y: 2, Z: 3, Flags: 1},
+		{Item: 0x20, X: -4, Y: 0, Z: 0},
+	}}
+
+	rotated := multi.Rotate90()
+	assert.Equal(t, 7, rotated.ID)
+	assert.Equal(t, []MultiItem{
+		{Item: 0x10, X: -2, Y: 1, Z: 3, Flags: 1},
+		{Item: 0x20, X: 0, Y: -4, Z: 0},
+	}, rotated.Items)
+	assert.Equal(t, int16(1), multi.Items[0].X, "the source multi is left unchanged")
+
+	// Four rotations bring the multi back to its original layout
+	assert.Equal(t, multi.Items, rotated.Rotate90().Rotate90().Rotate90().Items)
+}
+
+func TestMulti_Mirror(t *testing.T) {
+	m := openTestWorld(t, nil, nil, map[int]ItemInfo{
+		0x10: {Name: "stone wall", Flags: TileFlagWall | TileFlagImpassable, Height: 20}, // Facing south
+		0x11: {Name: "stone wall", Flags: TileFlagWall | TileFlagImpassable, Height: 20}, // Facing east
+		0x12: {Name: "stone post", Flags: TileFlagWall | TileFlagImpassable, Height: 20},
+		0x20: {Name: "plaster wall", Flags: TileFlagWall, Height: 20}, // Ambiguous, part of a run of three
+		0x21: {Name: "plaster wall", Flags: TileFlagWall, Height: 20},
+		0x22: {Name: "plaster wall", Flags: TileFlagWall, Height: 20},
+		0x30: {Name: "floor", Flags: TileFlagSurface},
+		0x31: {Name: "floor", Flags: TileFlagSurface},
+	})
+
+	multi := &Multi{sdk: m.sdk, Items: []MultiItem{
+		{Item: 0x10, X: 1, Y: 2},
+		{Item: 0x11, X: 1, Y: 2},
+		{Item: 0x12, X: 0, Y: 0},
+		{Item: 0x20, X: 0, Y: 0},
+		{Item: 0x21, X: 0, Y: 0},
+		{Item: 0x30, X: 3, Y: -1},
+	}}
+
+	mirrored := multi.Mirror()
+	require.Len(t, mirrored.Items, len(multi.Items))
+	assert.Equal(t, []uint16{0x11, 0x10, 0x12, 0x20, 0x21, 0x30}, []uint16{
+		mirrored.Items[0].Item, mirrored.Items[1].Item, mirrored.Items[2].Item,
+		mirrored.Items[3].Item, mirrored.Items[4].Item, mirrored.Items[5].Item,
+	})
+	assert.Equal(t, int16(2), mirrored.Items[0].X)
+	assert.Equal(t, int16(1), mirrored.Items[0].Y)
+	assert.Equal(t, int16(-1), mirrored.Items[5].X)
+	assert.Equal(t, int16(3), mirrored.Items[5].Y)
+
+	// Mirroring twice restores the multi
+	assert.Equal(t, multi.Items, mirrored.Mirror().Items)
+}
+
+func TestMulti_Merge(t *testing.T) {
+	base := &Multi{ID: 1, Items: []MultiItem{
+		{Item: 0x10, X: 0, Y: 0, Z: 0},
+		{Item: 0x11, X: 1, Y: 0, Z: 0},
+	}}
+
+	module := &Multi{Items: []MultiItem{
+		{Item: 0x11, X: 0, Y: 0, Z: 0}, // Shared wall, lands on the existing one
+		{Item: 0x12, X: 1, Y: 0, Z: 0},
+		{Item: 0x10, X: 0, Y: 0, Z: 20}, // Same tile on another floor
+	}}
+
+	merged := base.Merge(module, 1, 0, 0)
+	assert.Equal(t, 1, merged.ID)
+	assert.Equal(t, []MultiItem{
+		{Item: 0x10, X: 0, Y: 0, Z: 0},
+		{Item: 0x11, X: 1, Y: 0, Z: 0},
+		{Item: 0x12, X: 2, Y: 0, Z: 0},
+		{Item: 0x10, X: 1, Y: 0, Z: 20},
+	}, merged.Items)
+	assert.Len(t, base.Items, 2, "the source multi is left unchanged")
+}
+
+func TestMulti_Extract(t *testing.T) {
+	multi := &Multi{ID: 1, Items: []MultiItem{
+		{Item: 0x10, X: -2, Y: -2},
+		{Item: 0x11, X: 0, Y: 0},
+		{Item: 0x12, X: 1, Y: 1, Z: 20},
+		{Item: 0x13, X: 2, Y: 0},
+	}}
+
+	part := multi.Extract(image.Rect(-1, -1, 2, 2))
+	assert.Equal(t, 1, part.ID)
+	assert.Equal(t, []MultiItem{
+		{Item: 0x11, X: 0, Y: 0},
+		{Item: 0x12, X: 1, Y: 1, Z: 20},
+	}, part.Items)
+
+	assert.Empty(t, multi.Extract(image.Rect(10, 10, 20, 20)).Items)
+}
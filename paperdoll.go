@@ -0,0 +1,168 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+)
+
+const (
+	paperdollMale   = 50000 // Offset of the gumps of equipment worn by male bodies
+	paperdollFemale = 60000 // Offset of the gumps of equipment worn by female bodies
+)
+
+// paperdollBodies maps the bodies of playable races to the gump of their paperdoll and
+// whether they wear the female gumps of equipment
+var paperdollBodies = map[int]struct {
+	gump   int
+	female bool
+}{
+	400: {0x000C, false}, // Human male
+	401: {0x000D, true},  // Human female
+	402: {0x000C, false}, // Human male ghost
+	403: {0x000D, true},  // Human female ghost
+	605: {0x000E, false}, // Elf male
+	606: {0x000F, true},  // Elf female
+	666: {0x029A, false}, // Gargoyle male
+	667: {0x0299, true},  // Gargoyle female
+}
+
+// paperdollLayers is the order in which the layers of equipment are drawn on top of the
+// body, as the client does. Layers which are not listed (such as the backpack or the
+// mount) are not drawn on the paperdoll.
+var paperdollLayers = []byte{
+	20, // Cloak
+	5,  // Shirt
+	4,  // Pants
+	3,  // Shoes
+	24, // Legs
+	19, // Arms
+	13, // Torso
+	17, // Tunic
+	8,  // Ring
+	14, // Bracelet
+	15, // Face
+	7,  // Gloves
+	23, // Skirt
+	22, // Robe
+	12, // Waist
+	10, // Necklace
+	11, // Hair
+	16, // Beard
+	18, // Earrings
+	6,  // Helmet
+	1,  // One handed
+	2,  // Two handed
+	9,  // Talisman
+}
+
+// PaperdollItem is a piece of equipment worn on a paperdoll
+type PaperdollItem struct {
+	ID  int // Item ID of the equipment, whose tile data gives its gump and layer
+	Hue int // Hue of the equipment, as given to Animation
+}
+
+// Paperdoll renders the paperdoll of a body (such as 400 and 401 for human males and
+// females) with its skin hue, wearing the items. The body gump is drawn first, followed
+// by the gump of each item in the order of their layers in tiledata.mul, each one being
+// recolored with its hue. Items which are not worn on the paperdoll, or have no gump,
+// are skipped.
+func (s *SDK) Paperdoll(body, hue int, items ...PaperdollItem) (image.Image, error) {
+	doll, ok := paperdollBodies[body]
+	if !ok {
+		return nil, fmt.Errorf("Paperdoll: body %d has no paperdoll", body)
+	}
+
+	base, err := s.paperdollGump(doll.gump, hue, false)
+	if err != nil {
+		return nil, fmt.Errorf("Paperdoll: body %d: %w", body, err)
+	}
+
+	// Find the layer of every item, so they can be drawn in order
+	type worn struct {
+		item PaperdollItem
+		info *ItemInfo
+	}
+
+	layered := make(map[byte][]worn, len(items))
+	for _, item := range items {
+		info, err := s.staticInfo(item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Paperdoll: item %d: %w", item.ID, err)
+		}
+
+		if layer, ok := info.IsWearable(); ok && info.AnimationID > 0 {
+			layered[layer] = append(layered[layer], worn{item, info})
+		}
+	}
+
+	gumps := []image.Image{base}
+	for _, layer := range paperdollLayers {
+		for _, w := range layered[layer] {
+			if img := s.paperdollItem(w.info, w.item.Hue, doll.female); img != nil {
+				gumps = append(gumps, img)
+			}
+		}
+	}
+
+	return composeGumps(gumps), nil
+}
+
+// paperdollItem returns the gump of the item worn on a paperdoll, preferring the female
+// gump if requested and the client has one, or nil if the item has no gump
+func (s *SDK) paperdollItem(info *ItemInfo, hue int, female bool) image.Image {
+	// Items of the PartialHue flag only recolor their grayscale pixels
+	partial := info.Flags&TileFlagPartialHue != 0
+	if female {
+		if img, err := s.paperdollGump(paperdollFemale+int(info.AnimationID), hue, partial); err == nil {
+			return img
+		}
+	}
+
+	if img, err := s.paperdollGump(paperdollMale+int(info.AnimationID), hue, partial); err == nil {
+		return img
+	}
+	return nil
+}
+
+// paperdollGump returns the image of the gump, recolored with the hue as given to
+// Animation, where 0x8000 only recolors the grayscale pixels
+func (s *SDK) paperdollGump(id, hue int, partial bool) (image.Image, error) {
+	gump, err := s.GumpWithHue(id, hue&0x3FFF, partial || hue&0x8000 != 0)
+	if err != nil {
+		return nil, err
+	}
+	return gump.Image, nil
+}
+
+// composeGumps draws the opaque pixels of the gumps on top of each other, all of them
+// being anchored at their top-left corner
+func composeGumps(gumps []image.Image) *bitmap.ARGB1555 {
+	var canvas image.Rectangle
+	for _, img := range gumps {
+		canvas = canvas.Union(image.Rectangle{Max: img.Bounds().Size()})
+	}
+
+	dst := bitmap.NewARGB1555(canvas)
+	for _, img := range gumps {
+		bounds := img.Bounds()
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				value, opaque := encodeARGB1555(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+				if !opaque {
+					continue
+				}
+
+				offset := dst.PixOffset(x, y)
+				value |= 0x8000
+				dst.Pix[offset] = byte(value)
+				dst.Pix[offset+1] = byte(value >> 8)
+			}
+		}
+	}
+	return dst
+}
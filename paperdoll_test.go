@@ -0,0 +1,79 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kelindar/ultima-sdk/internal/bitmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGump returns a gump of the given size, filled with the color within the rectangle
+func newTestGump(width, height int, fill image.Rectangle, color uint16) *bitmap.ARGB1555 {
+	img := bitmap.NewARGB1555(image.Rect(0, 0, width, height))
+	for y := fill.Min.Y; y < fill.Max.Y; y++ {
+		for x := fill.Min.X; x < fill.Max.X; x++ {
+			img.Set(x, y, bitmap.ARGB1555Color(color))
+		}
+	}
+	return img
+}
+
+func TestSDK_Paperdoll(t *testing.T) {
+	cloak := newTestGump(5, 6, image.Rect(4, 0, 5, 6), 0xC210)
+	cloak.Set(4, 5, bitmap.ARGB1555Color(0xFC00))
+
+	dir := t.TempDir()
+	writeTestTiledata(t, dir, nil, map[int]ItemInfo{
+		0x100: {Flags: TileFlagWearable, Quality: 22, AnimationID: 1},                      // Robe
+		0x101: {Flags: TileFlagWearable, Quality: 6, AnimationID: 2},                       // Helmet
+		0x102: {Flags: TileFlagWearable | TileFlagPartialHue, Quality: 20, AnimationID: 3}, // Cloak
+		0x103: {Flags: TileFlagWearable, Quality: 21, AnimationID: 4},                      // Backpack
+		0x104: {Flags: TileFlagWearable, Quality: 5, AnimationID: 5},                       // Shirt without a gump
+		0x105: {Name: "not worn"},
+	})
+	writeTestGumps(t, dir, map[int]*bitmap.ARGB1555{
+		0x000C:              newTestGump(4, 6, image.Rect(1, 0, 3, 6), 0xFFFF),
+		0x000D:              newTestGump(4, 6, image.Rect(1, 0, 3, 6), 0xFFFF),
+		paperdollMale + 1:   newTestGump(4, 4, image.Rect(0, 2, 4, 4), 0xFC00),
+		paperdollMale + 2:   newTestGump(4, 3, image.Rect(0, 0, 4, 3), 0x83E0),
+		paperdollMale + 3:   cloak,
+		paperdollMale + 4:   newTestGump(4, 6, image.Rect(0, 0, 4, 6), 0x801F),
+		paperdollFemale + 1: newTestGump(4, 4, image.Rect(0, 3, 4, 4), 0x801F),
+	})
+	writeTestHues(t, dir, map[int]uint16{33: 0x03E0})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	// Items are drawn by layer, the helmet on top of the robe on top of the cloak
+	img, err := sdk.Paperdoll(400, 0,
+		PaperdollItem{ID: 0x101}, PaperdollItem{ID: 0x100}, PaperdollItem{ID: 0x102, Hue: 33},
+		PaperdollItem{ID: 0x103}, PaperdollItem{ID: 0x104}, PaperdollItem{ID: 0x105},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 5, 6), img.Bounds())
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), img.At(1, 2), "helmet")
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), img.At(0, 3), "robe")
+	assert.Equal(t, bitmap.ARGB1555Color(0xFFFF), img.At(1, 5), "body")
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), img.At(4, 0), "cloak, gray pixels are hued")
+	assert.Equal(t, bitmap.ARGB1555Color(0xFC00), img.At(4, 5), "cloak, colored pixels are not")
+	assert.Equal(t, bitmap.ARGB1555Color(0), img.At(0, 5))
+
+	// Female bodies wear the female gumps, if any, and the skin is hued
+	img, err = sdk.Paperdoll(401, 33, PaperdollItem{ID: 0x100}, PaperdollItem{ID: 0x101})
+	require.NoError(t, err)
+	assert.Equal(t, bitmap.ARGB1555Color(0x801F), img.At(1, 3), "female robe")
+	assert.Equal(t, bitmap.ARGB1555Color(0x83E0), img.At(2, 5), "hued skin")
+	assert.Equal(t, bitmap.ARGB1555Color(0), img.At(0, 5))
+
+	_, err = sdk.Paperdoll(1, 0)
+	assert.Error(t, err, "not a playable body")
+	_, err = sdk.Paperdoll(400, 0, PaperdollItem{ID: -1})
+	assert.Error(t, err)
+}
@@ -129,3 +129,31 @@ func (s *SDK) RadarColors() iter.Seq[RadarColor] {
 		}
 	}
 }
+
+// radarTable holds the color of every land and static tile, indexed like radarcol.mul
+type radarTable [totalRadarColors]uint16
+
+// radarTable returns the whole radar color table, loading it on first use
+func (s *SDK) radarTable() (*radarTable, error) {
+	if table := s.radar.Load(); table != nil {
+		return table, nil
+	}
+
+	file, err := s.loadRadarcol()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load radar colors: %w", err)
+	}
+
+	data, err := file.ReadFull(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read radar colors: %w", err)
+	}
+
+	table := new(radarTable)
+	for i := 0; i < min(len(data)/2, totalRadarColors); i++ {
+		table[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+
+	s.radar.CompareAndSwap(nil, table)
+	return s.radar.Load(), nil
+}
@@ -4,7 +4,10 @@
 package ultima
 
 import (
+	"encoding/binary"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/kelindar/ultima-sdk/internal/bitmap"
@@ -130,3 +133,25 @@ func TestRadarColorBitpacking(t *testing.T) {
 		})
 	}
 }
+
+func TestRadarTable(t *testing.T) {
+	dir := t.TempDir()
+	data := make([]byte, totalRadarColors*2)
+	binary.LittleEndian.PutUint16(data[2:], 0x1ca4)
+	binary.LittleEndian.PutUint16(data[0x4001*2:], 0x7FFF)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "radarcol.mul"), data, 0644))
+
+	sdk, err := Open(dir)
+	assert.NoError(t, err)
+	defer sdk.Close()
+
+	table, err := sdk.radarTable()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x1ca4), table[1])
+	assert.Equal(t, uint16(0x7FFF), table[0x4001])
+
+	// The table is loaded only once
+	again, err := sdk.radarTable()
+	assert.NoError(t, err)
+	assert.Same(t, table, again)
+}
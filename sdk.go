@@ -7,14 +7,25 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
 // SDK represents the main entry point for accessing Ultima Online game files.
 // It holds the necessary state, such as the base path to the game files and
 // a cache of opened file handles.
 type SDK struct {
-	basePath string   // Path to the Ultima Online client directory
-	files    sync.Map // Lazily loaded file handles (cacheKey to *uofile.File)
+	basePath string                     // Path to the Ultima Online client directory
+	files    sync.Map                   // Lazily loaded file handles (cacheKey to *uofile.File)
+	items    atomic.Pointer[itemIndex]  // Lazily built index over the static tile data
+	radar    atomic.Pointer[radarTable] // Lazily loaded radar color table
+	hues     atomic.Pointer[hueTable]   // Lazily decoded hue table
+	clilocs  sync.Map                   // Lazily built search indices over cliloc files (language to *clilocIndex)
+	fallback atomic.Pointer[[]string]   // Languages to fall back to for missing strings, "enu" if nil
+	lazyText atomic.Bool                // Whether cliloc files are loaded in index-only mode
+	lazyKeys atomic.Bool                // Whether speech.mul is loaded in index-only mode
+	speech   atomic.Pointer[speechNode] // Lazily built trie of the speech keywords
+	icons    sync.Map                   // Gumps of the skills set with SetSkillGumps (skill ID to []int)
+	maps     sync.Map                   // Custom facets registered with RegisterMap (map ID to *mapDefinition)
 }
 
 // Open initializes a new SDK instance for the specified Ultima Online client directory.
@@ -44,6 +55,11 @@ func Open(directory string) (*SDK, error) {
 // Close releases any resources held by the SDK instance.
 func (s *SDK) Close() error {
 	s.closeAllFiles()
+	s.items.Store(nil)
+	s.radar.Store(nil)
+	s.hues.Store(nil)
+	s.clilocs.Clear()
+	s.speech.Store(nil)
 	s.basePath = ""
 	return nil
 }
@@ -4,8 +4,13 @@
 package ultima
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"codeberg.org/go-mmap/mmap"
+	"github.com/kelindar/ultima-sdk/internal/mul"
 	"github.com/kelindar/ultima-sdk/internal/uofile"
 )
 
@@ -32,16 +37,26 @@ func (s *SDK) loadSkillGroups() (*uofile.File, error) {
 	return s.load([]string{"skillgrp.mul"}, 0)
 }
 
-// loadCliloc loads the client localization file for a specific language
+// loadCliloc loads the client localization file for a specific language, returning an
+// error if the language is not installed in the client directory
 func (s *SDK) loadCliloc(language string) (*uofile.File, error) {
-	return s.load([]string{
-		fmt.Sprintf("cliloc.%s", language),
-	}, 0, uofile.WithDecodeMUL(decodeClilocFile))
+	name := fmt.Sprintf("cliloc.%s", language)
+	if _, err := os.Stat(filepath.Join(s.basePath, name)); err != nil {
+		return nil, err
+	}
+
+	indexOnly := s.lazyText.Load()
+	return s.load([]string{name}, 0, uofile.WithDecodeMUL(func(file *mmap.File, add mul.AddFn) error {
+		return decodeClilocFile(file, add, indexOnly)
+	}))
 }
 
 // loadSpeech loads the speech.mul file
 func (s *SDK) loadSpeech() (*uofile.File, error) {
-	return s.load([]string{"speech.mul"}, 0, uofile.WithDecodeMUL(decodeSpeechFile))
+	indexOnly := s.lazyKeys.Load()
+	return s.load([]string{"speech.mul"}, 0, uofile.WithDecodeMUL(func(file *mmap.File, add mul.AddFn) error {
+		return decodeSpeechFile(file, add, indexOnly)
+	}))
 }
 
 // loadTiledata loads the tiledata file
@@ -68,6 +83,16 @@ func (s *SDK) loadArt() (*uofile.File, error) {
 	}, 0x14000, uofile.WithExtension(".tga"), uofile.WithIndexLength(0x13FDC))
 }
 
+// loadDef loads an optional .def remapping file (e.g. art.def), returning an error
+// if the file is not present in the client directory
+func (s *SDK) loadDef(name string) (*uofile.File, error) {
+	if _, err := os.Stat(filepath.Join(s.basePath, name)); err != nil {
+		return nil, err
+	}
+
+	return s.load([]string{name}, 0, uofile.WithDecodeMUL(decodeDefFile))
+}
+
 // loadGumpart loads the gump files (gumpart.mul or UOP equivalent)
 func (s *SDK) loadGump() (*uofile.File, error) {
 	return s.load([]string{
@@ -94,48 +119,160 @@ func (s *SDK) loadTextures() (*uofile.File, error) {
 	}, 0x4000, uofile.WithIndexLength(12))
 }
 
-// loadMap loads a specific map file (mapX.mul, where X is the map ID)
+// loadMap loads a specific map file (mapX.mul, where X is the map ID), or the land of
+// the Enhanced Client's facet file if the client has no other map file
 func (s *SDK) loadMap(mapID int) (*uofile.File, error) {
-	return s.load([]string{
-		fmt.Sprintf("map%dLegacyMUL.uop", mapID),
-		fmt.Sprintf("map%d.mul", mapID),
-	}, 0, uofile.WithStrict())
+	switch facet, err := s.loadFacet(mapID); {
+	case err != nil:
+		return nil, err
+	case facet != nil:
+		return facet.land(), nil
+	}
+
+	fileNames, _ := s.mapFileNames(mapID)
+	return s.load(fileNames, 0, uofile.WithStrict(), uofile.WithDecodeMUL(decodeMapFile))
 }
 
-// loadStatics loads the statics files for a specific map ID
+// loadStatics loads the statics files for a specific map ID, or the statics of the
+// Enhanced Client's facet file if the client has no other map file
 func (s *SDK) loadStatics(mapID int) (*uofile.File, error) {
-	return s.load([]string{
-		fmt.Sprintf("statics%dLegacyMUL.uop", mapID),
-		fmt.Sprintf("statics%d.mul", mapID),
-		fmt.Sprintf("staidx%d.mul", mapID),
-	}, 0,
+	switch facet, err := s.loadFacet(mapID); {
+	case err != nil:
+		return nil, err
+	case facet != nil:
+		return facet.statics(), nil
+	}
+
+	_, fileNames := s.mapFileNames(mapID)
+	return s.load(fileNames, 0,
 		uofile.WithIndexLength(12),
 		uofile.WithExtra(),
 	)
 }
 
+// mapFileNames returns the names of the land and statics files of a map, either those
+// registered with RegisterMap or the ones of the client
+func (s *SDK) mapFileNames(mapID int) (land, statics []string) {
+	if def, ok := s.mapDefinition(mapID); ok {
+		return []string{def.mapFile}, def.staticsFiles
+	}
+
+	land = []string{
+		fmt.Sprintf("map%dLegacyMUL.uop", mapID),
+		fmt.Sprintf("map%d.mul", mapID),
+	}
+	statics = []string{
+		fmt.Sprintf("statics%dLegacyMUL.uop", mapID),
+		fmt.Sprintf("statics%d.mul", mapID),
+		fmt.Sprintf("staidx%d.mul", mapID),
+	}
+	return land, statics
+}
+
+// loadFacet loads the Enhanced Client's facet file of the map (facetX.uop), returning nil
+// if the map is registered, if the client has any of the classic land files of the map,
+// which are preferred, or if it has no facet file
+func (s *SDK) loadFacet(mapID int) (*facet, error) {
+	if _, ok := s.mapDefinition(mapID); ok {
+		return nil, nil
+	}
+
+	land, _ := s.mapFileNames(mapID)
+	for _, name := range land {
+		if _, err := os.Stat(filepath.Join(s.basePath, name)); err == nil {
+			return nil, nil
+		}
+	}
+
+	name := facetFileName(mapID)
+	path := filepath.Join(s.basePath, name)
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+
+	file, err := s.load([]string{name}, 0, uofile.WithNames(facetSectorName(mapID)))
+	if err != nil {
+		return nil, err
+	}
+	return newFacet(mapID, path, file), nil
+}
+
+// loadMapPatch loads a difference file of the map, or its data and index files, returning
+// nil if any of them is missing or if the last one is empty, as the clients ship empty
+// difference files for the maps without patches
+func (s *SDK) loadMapPatch(fileNames []string, options ...uofile.Option) (*uofile.File, error) {
+	for i, name := range fileNames {
+		info, err := os.Stat(filepath.Join(s.basePath, name))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return nil, nil
+		case err != nil:
+			return nil, err
+		case i == len(fileNames)-1 && info.Size() == 0:
+			return nil, nil
+		}
+	}
+
+	return s.load(fileNames, 0, options...)
+}
+
 // loadMulti loads the multi files
 func (s *SDK) loadMulti() (*uofile.File, error) {
 	return s.load([]string{
 		"housing.bin", // UOP format
 		"multi.mul",   // MUL format
 		"multi.idx",
-	}, 0x2200, uofile.WithIndexLength(14))
+	}, multiCount, uofile.WithIndexLength(14))
 }
 
 // loadAnim loads the animation files for a specific file type
-// fileType can be 0 for anim.mul, 1 for anim2.mul, etc.
+// fileType is 1 for anim.mul, 2 for anim2.mul, up to 5 for anim5.mul
 func (s *SDK) loadAnim(fileType int) (*uofile.File, error) {
-	var files []string
-	if fileType == 0 {
-		files = []string{"anim.mul", "anim.idx"}
-	} else {
-		files = []string{
-			fmt.Sprintf("anim%d.mul", fileType),
-			fmt.Sprintf("anim%d.idx", fileType),
-		}
+	return s.load(animFileNames(fileType), 0, uofile.WithIndexLength(12))
+}
+
+// animFileNames returns the names of the data and index files of the animation file type
+func animFileNames(fileType int) []string {
+	if fileType <= 1 {
+		return []string{"anim.mul", "anim.idx"}
 	}
-	return s.load(files, 0, uofile.WithIndexLength(12))
+
+	return []string{
+		fmt.Sprintf("anim%d.mul", fileType),
+		fmt.Sprintf("anim%d.idx", fileType),
+	}
+}
+
+// loadAnimFrames loads the optional AnimationFrameN.uop file of modern clients, returning
+// an error if the file is not present in the client directory. Entries are indexed by
+// body and action, as in animFrameIndex.
+func (s *SDK) loadAnimFrames(n int) (*uofile.File, error) {
+	name := fmt.Sprintf("AnimationFrame%d.uop", n)
+	if _, err := os.Stat(filepath.Join(s.basePath, name)); err != nil {
+		return nil, err
+	}
+
+	return s.load([]string{name}, animFrameBodies*animFrameActions, uofile.WithNames(animFrameName))
+}
+
+// loadBodyconv loads the optional bodyconv.def file, returning an error if the file is
+// not present in the client directory
+func (s *SDK) loadBodyconv() (*uofile.File, error) {
+	if _, err := os.Stat(filepath.Join(s.basePath, "bodyconv.def")); err != nil {
+		return nil, err
+	}
+
+	return s.load([]string{"bodyconv.def"}, 0, uofile.WithDecodeMUL(decodeBodyconvFile))
+}
+
+// loadMobtypes loads the optional mobtypes.txt file, returning an error if the file is
+// not present in the client directory
+func (s *SDK) loadMobtypes() (*uofile.File, error) {
+	if _, err := os.Stat(filepath.Join(s.basePath, "mobtypes.txt")); err != nil {
+		return nil, err
+	}
+
+	return s.load([]string{"mobtypes.txt"}, 0, uofile.WithDecodeMUL(decodeMobtypesFile))
 }
 
 // loadFont loads the ASCII fonts file
@@ -175,7 +312,10 @@ func (s *SDK) load(fileNames []string, length int, options ...uofile.Option) (*u
 	}
 
 	// Not in cache, create new file
-	file := uofile.New(s.basePath, fileNames, length, options...)
+	file, err := uofile.Open(s.basePath, fileNames, length, options...)
+	if err != nil {
+		return nil, err
+	}
 
 	// Store in cache (use LoadOrStore to handle potential race conditions)
 	actual, loaded := s.files.LoadOrStore(key, file)
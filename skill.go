@@ -10,7 +10,10 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"slices"
 	"strings"
+
+	"github.com/kelindar/ultima-sdk/internal/mul"
 )
 
 var (
@@ -18,6 +21,8 @@ var (
 	ErrInvalidSkillIndex = errors.New("invalid skill index")
 	// ErrInvalidSkillGroupIndex is returned when an invalid skill group index is requested
 	ErrInvalidSkillGroupIndex = errors.New("invalid skill group index")
+	// ErrNoSkillGumps is returned when no gump is associated with a skill
+	ErrNoSkillGumps = errors.New("no gumps for skill")
 )
 
 const (
@@ -27,16 +32,17 @@ const (
 
 // Skill defines a single character skill in the game
 type Skill struct {
-	ID       int    // ID of the skill
-	Name     string // Name of the skill
-	IsAction bool   // True if the skill is an action (button), false if passive
+	ID       int    `json:"id"`              // ID of the skill
+	Name     string `json:"name"`            // Name of the skill
+	IsAction bool   `json:"action"`          // True if the skill is an action (button), false if passive
+	Extra    uint32 `json:"extra,omitempty"` // Extra data of the skills.idx entry, zero unless set by the client or a tool
 }
 
 // SkillGroup defines a group of related skills
 type SkillGroup struct {
-	ID     int    // ID of the skill group
-	Name   string // Name of the skill group
-	Skills []int  // IDs of skills that belong to this group
+	ID     int    `json:"id"`     // ID of the skill group
+	Name   string `json:"name"`   // Name of the skill group
+	Skills []int  `json:"skills"` // IDs of skills that belong to this group
 }
 
 // Skill retrieves a specific skill by its ID
@@ -51,9 +57,14 @@ func (s *SDK) Skill(id int) (*Skill, error) {
 		return nil, fmt.Errorf("%w: %d", ErrInvalidSkillIndex, id)
 	}
 
-	// Read the skill data
-	data, err := file.ReadFull(uint32(id))
-	if err != nil {
+	// Read the skill data, along with the extra data of its index entry
+	entry, err := file.Entry(uint32(id))
+	if err != nil || entry == nil {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSkillIndex, id)
+	}
+
+	data := make([]byte, entry.Len())
+	if _, err := entry.ReadAt(data, 0); err != nil {
 		return nil, fmt.Errorf("%w: %d", ErrInvalidSkillIndex, id)
 	}
 
@@ -93,6 +104,7 @@ func (s *SDK) Skill(id int) (*Skill, error) {
 		ID:       id,
 		Name:     name,
 		IsAction: isAction != 0,
+		Extra:    uint32(entry.Extra()),
 	}, nil
 }
 
@@ -119,6 +131,45 @@ func (s *SDK) Skills() iter.Seq[*Skill] {
 	}
 }
 
+// WriteSkills encodes the skills and writes them as a skills.mul/skills.idx pair, each
+// entry holding the action flag followed by the null-terminated name, and the index entry
+// holding the Extra field of the skill. Skills may be given in any order, missing IDs are
+// written as empty index entries.
+func WriteSkills(skillsMul, skillsIdx io.Writer, skills []*Skill) error {
+	sorted := slices.Clone(skills)
+	slices.SortFunc(sorted, func(a, b *Skill) int {
+		return a.ID - b.ID
+	})
+
+	w := mul.NewWriter(skillsMul, skillsIdx)
+	for i, skill := range sorted {
+		switch {
+		case skill.ID < 0:
+			return fmt.Errorf("%w: %d", ErrInvalidSkillIndex, skill.ID)
+		case i > 0 && sorted[i-1].ID == skill.ID:
+			return fmt.Errorf("%w: duplicate skill %d", ErrInvalidSkillIndex, skill.ID)
+		}
+
+		if err := w.Write(uint32(skill.ID), encodeSkill(skill), skill.Extra); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeSkill encodes a skill as its action flag followed by its null-terminated name
+func encodeSkill(skill *Skill) []byte {
+	data := make([]byte, 0, len(skill.Name)+2)
+	if skill.IsAction {
+		data = append(data, 1)
+	} else {
+		data = append(data, 0)
+	}
+
+	data = append(data, skill.Name...)
+	return append(data, 0)
+}
+
 // SkillGroup retrieves a specific skill group by its ID
 func (s *SDK) SkillGroup(id int) (*SkillGroup, error) {
 	// Get all skill groups
@@ -175,6 +226,99 @@ func (s *SDK) SkillGroups() iter.Seq[*SkillGroup] {
 	}
 }
 
+// WriteSkillGroups encodes the skill groups into the skillgrp.mul layout: the number of
+// groups, the names of every group but "Misc" (group 0) in fixed slots of 17 characters,
+// then the group of every skill in order of their IDs. Skills which are not listed in any
+// group belong to group 0. Names are written as ASCII unless unicode is set, in which
+// case the file is flagged and names are written as UTF-16 characters.
+func WriteSkillGroups(dst io.Writer, groups []*SkillGroup, unicode bool) error {
+	count, skillCount := 1, 0
+	for _, group := range groups {
+		if group.ID < 0 {
+			return fmt.Errorf("%w: %d", ErrInvalidSkillGroupIndex, group.ID)
+		}
+
+		count = max(count, group.ID+1)
+		for _, skill := range group.Skills {
+			if skill < 0 {
+				return fmt.Errorf("%w: %d in skill group %d", ErrInvalidSkillIndex, skill, group.ID)
+			}
+			skillCount = max(skillCount, skill+1)
+		}
+	}
+
+	names := make([]string, count)
+	membership := make([]int32, skillCount)
+	assigned := make([]bool, skillCount)
+	seen := make([]bool, count)
+	for _, group := range groups {
+		if seen[group.ID] {
+			return fmt.Errorf("%w: duplicate skill group %d", ErrInvalidSkillGroupIndex, group.ID)
+		}
+
+		seen[group.ID] = true
+		names[group.ID] = group.Name
+		for _, skill := range group.Skills {
+			if assigned[skill] {
+				return fmt.Errorf("%w: skill %d belongs to several groups", ErrInvalidSkillIndex, skill)
+			}
+
+			assigned[skill] = true
+			membership[skill] = int32(group.ID)
+		}
+	}
+
+	var out []byte
+	slotSize := 17
+	if unicode {
+		flag := int32(skillUnicodeFlag)
+		out = binary.LittleEndian.AppendUint32(out, uint32(flag))
+		slotSize *= 2
+	}
+
+	// The name of group 0 is not stored, as it is always "Misc"
+	out = binary.LittleEndian.AppendUint32(out, uint32(count))
+	for id := 1; id < count; id++ {
+		slot, err := encodeSkillGroupName(names[id], unicode)
+		if err != nil {
+			return fmt.Errorf("%w: skill group %d: %v", ErrInvalidSkillGroupIndex, id, err)
+		}
+
+		out = append(out, slot...)
+		out = append(out, make([]byte, slotSize-len(slot))...)
+	}
+
+	for _, group := range membership {
+		out = binary.LittleEndian.AppendUint32(out, uint32(group))
+	}
+
+	if _, err := dst.Write(out); err != nil {
+		return fmt.Errorf("failed to write skillgrp.mul: %w", err)
+	}
+	return nil
+}
+
+// encodeSkillGroupName encodes the name of a skill group, which must fit in its slot of
+// 17 characters
+func encodeSkillGroupName(name string, unicode bool) ([]byte, error) {
+	var out []byte
+	for i, r := range []rune(name) {
+		switch {
+		case i >= 17:
+			return nil, fmt.Errorf("name %q is longer than 17 characters", name)
+		case unicode && r > 0xFFFF:
+			return nil, fmt.Errorf("name %q has a character outside of UTF-16", name)
+		case unicode:
+			out = binary.LittleEndian.AppendUint16(out, uint16(r))
+		case r > 0x7F:
+			return nil, fmt.Errorf("name %q has a character outside of ASCII", name)
+		default:
+			out = append(out, byte(r))
+		}
+	}
+	return out, nil
+}
+
 // loadSkillGroupData loads all skill group data from skillgrp.mul
 func (s *SDK) loadSkillGroupData() (groups []string, skillMap map[int]int, err error) {
 	file, err := s.loadSkillGroups()
@@ -0,0 +1,185 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// SkillSet holds the skills along with their groups, as they are written by WriteSkills
+// and WriteSkillGroups
+type SkillSet struct {
+	Skills []*Skill      `json:"skills"`
+	Groups []*SkillGroup `json:"groups"`
+}
+
+// SkillsToJSON exports the skills and skill groups as an indented JSON object, the skills
+// of every group being sorted by ID. The result can be read back with SkillsFromJSON.
+func (s *SDK) SkillsToJSON() ([]byte, error) {
+	set, err := s.skillSet()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("skills: failed to encode JSON: %w", err)
+	}
+	return data, nil
+}
+
+// SkillsFromJSON parses the skills and skill groups, as exported by SkillsToJSON
+func SkillsFromJSON(data []byte) (*SkillSet, error) {
+	set := new(SkillSet)
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("skills: failed to parse JSON: %w", err)
+	}
+	return set, nil
+}
+
+// SkillsToCSV exports the skills to CSV format, sorted by ID, with headers: id, name,
+// action, extra, group, group_name. Skills which belong to no group are listed in group 0. The
+// result can be read back with SkillsFromCSV.
+func (s *SDK) SkillsToCSV() ([]byte, error) {
+	set, err := s.skillSet()
+	if err != nil {
+		return nil, err
+	}
+
+	membership := make(map[int]*SkillGroup)
+	for _, group := range set.Groups {
+		for _, skill := range group.Skills {
+			membership[skill] = group
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"id", "name", "action", "extra", "group", "group_name"}); err != nil {
+		return nil, fmt.Errorf("skills: failed to write CSV header: %w", err)
+	}
+
+	for _, skill := range set.Skills {
+		group := membership[skill.ID]
+		if group == nil {
+			group = &SkillGroup{Name: miscGroupName}
+		}
+
+		record := []string{
+			strconv.Itoa(skill.ID),
+			skill.Name,
+			strconv.FormatBool(skill.IsAction),
+			strconv.FormatUint(uint64(skill.Extra), 10),
+			strconv.Itoa(group.ID),
+			group.Name,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("skills: failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("skills: failed to flush CSV writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SkillsFromCSV parses CSV data with columns id, name, action, extra, group and
+// group_name, as exported by SkillsToCSV, into skills and the groups they belong to,
+// sorted by ID. The first row is assumed to be a header and is skipped.
+func SkillsFromCSV(data []byte) (*SkillSet, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("skills: failed to parse CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("skills: CSV data is empty")
+	}
+
+	set := &SkillSet{Skills: make([]*Skill, 0, len(records)-1)}
+	groups := make(map[int]*SkillGroup)
+	for rowNum, record := range records[1:] {
+		if len(record) != 6 {
+			return nil, fmt.Errorf("skills: invalid CSV row %d, expected 6 columns (id,name,action,extra,group,group_name), got %d", rowNum+2, len(record))
+		}
+
+		id, err := strconv.ParseUint(record[0], 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("skills: invalid id in row %d: %w", rowNum+2, err)
+		}
+
+		action, err := strconv.ParseBool(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("skills: invalid action in row %d: %w", rowNum+2, err)
+		}
+
+		extra, err := strconv.ParseUint(record[3], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("skills: invalid extra in row %d: %w", rowNum+2, err)
+		}
+
+		groupID, err := strconv.ParseUint(record[4], 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("skills: invalid group in row %d: %w", rowNum+2, err)
+		}
+
+		group := groups[int(groupID)]
+		if group == nil {
+			group = &SkillGroup{ID: int(groupID), Name: record[5]}
+			groups[group.ID] = group
+		}
+
+		group.Skills = append(group.Skills, int(id))
+		set.Skills = append(set.Skills, &Skill{
+			ID:       int(id),
+			Name:     record[1],
+			IsAction: action,
+			Extra:    uint32(extra),
+		})
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(groups)) {
+		set.Groups = append(set.Groups, groups[id])
+	}
+	return set, nil
+}
+
+// skillSet returns every skill and skill group, sorted by ID
+func (s *SDK) skillSet() (*SkillSet, error) {
+	if _, err := s.loadSkills(); err != nil {
+		return nil, fmt.Errorf("skills: %w", err)
+	}
+
+	names, skillMap, err := s.loadSkillGroupData()
+	if err != nil {
+		return nil, fmt.Errorf("skills: %w", err)
+	}
+
+	set := &SkillSet{
+		Skills: slices.SortedFunc(s.Skills(), func(a, b *Skill) int {
+			return a.ID - b.ID
+		}),
+		Groups: make([]*SkillGroup, 0, len(names)),
+	}
+
+	for id, name := range names {
+		set.Groups = append(set.Groups, &SkillGroup{ID: id, Name: name})
+	}
+
+	for _, skill := range slices.Sorted(maps.Keys(skillMap)) {
+		if group := skillMap[skill]; group >= 0 && group < len(set.Groups) {
+			set.Groups[group].Skills = append(set.Groups[group].Skills, skill)
+		}
+	}
+	return set, nil
+}
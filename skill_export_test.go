@@ -0,0 +1,74 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestSkills writes the skills and skill groups into skills.mul, skills.idx and
+// skillgrp.mul files in the directory
+func writeTestSkills(t *testing.T, dir string, set *SkillSet) {
+	var skillsMul, skillsIdx, skillGrp bytes.Buffer
+	require.NoError(t, WriteSkills(&skillsMul, &skillsIdx, set.Skills))
+	require.NoError(t, WriteSkillGroups(&skillGrp, set.Groups, false))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills.mul"), skillsMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills.idx"), skillsIdx.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skillgrp.mul"), skillGrp.Bytes(), 0644))
+}
+
+func TestSDK_SkillsExport(t *testing.T) {
+	dir := t.TempDir()
+	expect := &SkillSet{
+		Skills: []*Skill{
+			{ID: 0, Name: "Alchemy", IsAction: false},
+			{ID: 1, Name: "Anatomy, Healing", IsAction: true},
+			{ID: 2, Name: "Animal Lore", IsAction: true, Extra: 7},
+		},
+		Groups: []*SkillGroup{
+			{ID: 0, Name: miscGroupName, Skills: []int{1}},
+			{ID: 1, Name: "Crafting", Skills: []int{0, 2}},
+		},
+	}
+
+	writeTestSkills(t, dir, expect)
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	t.Run("json", func(t *testing.T) {
+		data, err := sdk.SkillsToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"action": true`)
+		assert.Contains(t, string(data), `"extra": 7`)
+
+		set, err := SkillsFromJSON(data)
+		require.NoError(t, err)
+		assert.Equal(t, expect, set)
+
+		_, err = SkillsFromJSON([]byte("{"))
+		assert.Error(t, err)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		data, err := sdk.SkillsToCSV()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "1,\"Anatomy, Healing\",true,0,0,Misc\n")
+
+		set, err := SkillsFromCSV(data)
+		require.NoError(t, err)
+		assert.Equal(t, expect, set)
+
+		_, err = SkillsFromCSV([]byte("id,name,action,extra,group,group_name\n1,Anatomy,maybe,0,0,Misc\n"))
+		assert.Error(t, err)
+		_, err = SkillsFromCSV(nil)
+		assert.Error(t, err)
+	})
+}
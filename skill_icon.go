@@ -0,0 +1,58 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"fmt"
+	"slices"
+)
+
+// skillButton is the gump of the button used to activate an action skill in the skills
+// window, followed by its pressed state
+var skillButton = []int{0x0837, 0x0838}
+
+// skillIcons maps the skills of the spell schools to the icon of their first spell, as
+// drawn in the title of their spellbook
+var skillIcons = map[int][]int{
+	25: {0x08C0}, // Magery
+	49: {0x5000}, // Necromancy
+	51: {0x5100}, // Chivalry
+	52: {0x5420}, // Bushido
+	53: {0x5320}, // Ninjitsu
+	54: {0x59D8}, // Spellweaving
+	55: {0x5DC0}, // Mysticism
+}
+
+// SkillGumps returns the IDs of the gumps conventionally drawn for a skill, which can be
+// loaded with Gump. Gumps set with SetSkillGumps take precedence; otherwise the skills of
+// the spell schools have the icon of their first spell, and action skills the button
+// which activates them (normal and pressed). The mapping is partial, as the client has
+// no icon for the other skills: ErrNoSkillGumps is returned for them, unless set with
+// SetSkillGumps.
+func (s *SDK) SkillGumps(id int) ([]int, error) {
+	if gumps, ok := s.icons.Load(id); ok {
+		return slices.Clone(gumps.([]int)), nil
+	}
+
+	if gumps, ok := skillIcons[id]; ok {
+		return slices.Clone(gumps), nil
+	}
+
+	if skill, err := s.Skill(id); err == nil && skill.IsAction {
+		return slices.Clone(skillButton), nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrNoSkillGumps, id)
+}
+
+// SetSkillGumps overrides the gumps returned by SkillGumps for a skill, such as for
+// custom skills or shards with their own icons. Calling it without gumps restores the
+// default ones.
+func (s *SDK) SetSkillGumps(id int, gumps ...int) {
+	if len(gumps) == 0 {
+		s.icons.Delete(id)
+		return
+	}
+
+	s.icons.Store(id, slices.Clone(gumps))
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDK_SkillGumps(t *testing.T) {
+	dir := t.TempDir()
+	var skillsMul, skillsIdx bytes.Buffer
+	require.NoError(t, WriteSkills(&skillsMul, &skillsIdx, []*Skill{
+		{ID: 0, Name: "Alchemy"},
+		{ID: 1, Name: "Anatomy", IsAction: true},
+		{ID: 25, Name: "Magery"},
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills.mul"), skillsMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills.idx"), skillsIdx.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	gumps := func(id int) []int {
+		out, err := sdk.SkillGumps(id)
+		require.NoError(t, err)
+		return out
+	}
+
+	_, err = sdk.SkillGumps(0)
+	assert.ErrorIs(t, err, ErrNoSkillGumps)
+	assert.Equal(t, []int{0x0837, 0x0838}, gumps(1))
+	assert.Equal(t, []int{0x08C0}, gumps(25))
+	assert.Equal(t, []int{0x5DC0}, gumps(55))
+
+	// Overrides take precedence until they are removed
+	sdk.SetSkillGumps(25, 0x1234, 0x1235)
+	sdk.SetSkillGumps(0, 0x4321)
+	assert.Equal(t, []int{0x1234, 0x1235}, gumps(25))
+	assert.Equal(t, []int{0x4321}, gumps(0))
+
+	sdk.SetSkillGumps(25)
+	sdk.SetSkillGumps(0)
+	assert.Equal(t, []int{0x08C0}, gumps(25))
+	_, err = sdk.SkillGumps(0)
+	assert.ErrorIs(t, err, ErrNoSkillGumps)
+}
@@ -4,6 +4,10 @@
 package ultima
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -148,3 +152,66 @@ func TestSkillFileManipulation(t *testing.T) {
 		assert.Equal(t, miscGroupName, groups[0])
 	})
 }
+
+func TestSDK_WriteSkills(t *testing.T) {
+	dir := t.TempDir()
+	skills := []*Skill{
+		{ID: 2, Name: "Tracking", IsAction: true, Extra: 0x00010002},
+		{ID: 0, Name: "Alchemy"},
+	}
+
+	var skillsMul, skillsIdx bytes.Buffer
+	require.NoError(t, WriteSkills(&skillsMul, &skillsIdx, skills))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills.mul"), skillsMul.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills.idx"), skillsIdx.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	assert.Equal(t, []*Skill{skills[1], skills[0]}, slices.Collect(sdk.Skills()))
+	_, err = sdk.Skill(1)
+	assert.ErrorIs(t, err, ErrInvalidSkillIndex)
+
+	// Duplicates and negative IDs are rejected
+	assert.Error(t, WriteSkills(&skillsMul, &skillsIdx, []*Skill{{ID: 1}, {ID: 1}}))
+	assert.Error(t, WriteSkills(&skillsMul, &skillsIdx, []*Skill{{ID: -1}}))
+}
+
+func TestSDK_WriteSkillGroups(t *testing.T) {
+	groups := []*SkillGroup{
+		{ID: 2, Name: "Magie", Skills: []int{4, 1}},
+		{ID: 1, Name: "Combat", Skills: []int{0}},
+	}
+
+	for _, unicode := range []bool{false, true} {
+		dir := t.TempDir()
+		var buffer bytes.Buffer
+		require.NoError(t, WriteSkillGroups(&buffer, groups, unicode))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "skillgrp.mul"), buffer.Bytes(), 0644))
+
+		sdk, err := Open(dir)
+		require.NoError(t, err)
+
+		// Skills which are not listed belong to the "Misc" group
+		var names []string
+		members := make(map[int][]int)
+		for group := range sdk.SkillGroups() {
+			slices.Sort(group.Skills)
+			names = append(names, group.Name)
+			members[group.ID] = group.Skills
+		}
+
+		assert.Equal(t, []string{miscGroupName, "Combat", "Magie"}, names)
+		assert.Equal(t, map[int][]int{0: {2, 3}, 1: {0}, 2: {1, 4}}, members)
+		sdk.Close()
+	}
+
+	// Names which do not fit, duplicates and skills of several groups are rejected
+	var buffer bytes.Buffer
+	assert.Error(t, WriteSkillGroups(&buffer, []*SkillGroup{{ID: 1, Name: "A name far too long"}}, false))
+	assert.Error(t, WriteSkillGroups(&buffer, []*SkillGroup{{ID: 1, Name: "Magie Élémentaire"}}, false))
+	assert.NoError(t, WriteSkillGroups(&buffer, []*SkillGroup{{ID: 1, Name: "Magie Élémentaire"}}, true))
+	assert.Error(t, WriteSkillGroups(&buffer, []*SkillGroup{{ID: 1}, {ID: 1}}, false))
+	assert.Error(t, WriteSkillGroups(&buffer, []*SkillGroup{{ID: 1, Skills: []int{0}}, {ID: 2, Skills: []int{0}}}, false))
+}
@@ -4,15 +4,22 @@
 package ultima
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
-
 	"iter"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
 
 	"codeberg.org/go-mmap/mmap"
 	"github.com/kelindar/ultima-sdk/internal/mul"
+	"github.com/kelindar/ultima-sdk/internal/uofile"
 )
 
 var (
@@ -20,6 +27,10 @@ var (
 	ErrInvalidSpeechID = errors.New("invalid speech ID")
 )
 
+// speechRecord marks, in their extra field, the entries which point to their record in
+// speech.mul: ID (2), length (2) and keyword
+const speechRecord = 1
+
 // Speech represents a single speech entry from speech.mul
 type Speech []byte
 
@@ -33,6 +44,21 @@ func (s Speech) Text() string {
 	return string(s[2:])
 }
 
+// SpeechGroup defines the keywords of a speech entry, all of which share its ID. Servers
+// react to the ID, whichever of its keywords (in any of the languages) is said.
+type SpeechGroup struct {
+	ID       int      // ID of the speech entry
+	Keywords []string // Keywords of the entry, in the order of speech.mul
+}
+
+// NewSpeech creates a speech entry with the ID of its keyword group and its keyword, such
+// as to be written with WriteSpeech
+func NewSpeech(id int, text string) Speech {
+	entry := make(Speech, 2, 2+len(text))
+	binary.BigEndian.PutUint16(entry[0:2], uint16(id))
+	return append(entry, text...)
+}
+
 // SpeechEntry retrieves a predefined speech entry by its ID
 func (s *SDK) SpeechEntry(id int) (Speech, error) {
 	file, err := s.loadSpeech()
@@ -40,12 +66,15 @@ func (s *SDK) SpeechEntry(id int) (Speech, error) {
 		return Speech{}, err
 	}
 
-	data, err := file.ReadFull(uint32(id))
-	if err != nil {
+	entry, err := file.Entry(uint32(id))
+	switch {
+	case err != nil:
 		return Speech{}, err
+	case entry == nil:
+		return Speech{}, fmt.Errorf("%w: %d", ErrInvalidSpeechID, id)
 	}
 
-	return Speech(data), nil
+	return readSpeech(entry)
 }
 
 // SpeechEntries returns an iterator over all defined speech entries
@@ -57,28 +86,149 @@ func (s *SDK) SpeechEntries() iter.Seq[Speech] {
 
 	return func(yield func(Speech) bool) {
 		for index := range file.Entries() {
-			data, err := file.ReadFull(index)
+			entry, err := file.Entry(index)
+			if err != nil || entry == nil {
+				continue
+			}
+
+			data, err := readSpeech(entry)
 			if err != nil {
 				continue
 			}
 
-			if !yield(Speech(data)) {
+			if !yield(data) {
+				break
+			}
+		}
+	}
+}
+
+// SetSpeechIndexOnly sets whether speech.mul, if loaded from now on, only indexes its
+// entries, reading each keyword from the memory-mapped file when accessed rather than
+// copying every entry into memory up front.
+func (s *SDK) SetSpeechIndexOnly(enabled bool) {
+	s.lazyKeys.Store(enabled)
+}
+
+// readSpeech reads a speech entry, removing the length of the keyword from the entries
+// which point to their record in speech.mul
+func readSpeech(entry uofile.Entry) (Speech, error) {
+	data := make([]byte, entry.Len())
+	if _, err := entry.ReadAt(data, 0); err != nil {
+		return nil, err
+	}
+
+	if entry.Extra() == speechRecord && len(data) >= 4 {
+		data = append(data[:2], data[4:]...)
+	}
+	return Speech(data), nil
+}
+
+// SpeechGroup retrieves the keywords of a speech entry by its ID
+func (s *SDK) SpeechGroup(id int) (*SpeechGroup, error) {
+	group := &SpeechGroup{ID: id}
+	for entry := range s.SpeechEntries() {
+		if entry.ID() == id {
+			group.Keywords = append(group.Keywords, entry.Text())
+		}
+	}
+
+	if len(group.Keywords) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpeechID, id)
+	}
+	return group, nil
+}
+
+// SpeechGroups returns an iterator over the keywords of all speech entries, grouped by
+// their ID in ascending order
+func (s *SDK) SpeechGroups() iter.Seq[*SpeechGroup] {
+	return func(yield func(*SpeechGroup) bool) {
+		groups := make(map[int]*SpeechGroup)
+		for entry := range s.SpeechEntries() {
+			group := groups[entry.ID()]
+			if group == nil {
+				group = &SpeechGroup{ID: entry.ID()}
+				groups[entry.ID()] = group
+			}
+			group.Keywords = append(group.Keywords, entry.Text())
+		}
+
+		for _, id := range slices.Sorted(maps.Keys(groups)) {
+			if !yield(groups[id]) {
 				break
 			}
 		}
 	}
 }
 
-// decodeSpeechFile loads all speech entries from speech.mul into mul.Entry3D
+// SpeechToCSV exports all speech entries to CSV format, in the order of speech.mul, with
+// headers: id, keyword. The result can be read back with SpeechFromCSV.
+func (s *SDK) SpeechToCSV() ([]byte, error) {
+	if _, err := s.loadSpeech(); err != nil {
+		return nil, fmt.Errorf("speech: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"id", "keyword"}); err != nil {
+		return nil, fmt.Errorf("speech: failed to write CSV header: %w", err)
+	}
+
+	for entry := range s.SpeechEntries() {
+		if err := writer.Write([]string{strconv.Itoa(entry.ID()), entry.Text()}); err != nil {
+			return nil, fmt.Errorf("speech: failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("speech: failed to flush CSV writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SpeechFromCSV parses CSV data with columns id and keyword, as exported by SpeechToCSV,
+// into speech entries which can be written with WriteSpeech. The first row is assumed to
+// be a header and is skipped.
+func SpeechFromCSV(data []byte) ([]Speech, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("speech: failed to parse CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("speech: CSV data is empty")
+	}
+
+	entries := make([]Speech, 0, len(records)-1)
+	for rowNum, record := range records[1:] {
+		if len(record) != 2 {
+			return nil, fmt.Errorf("speech: invalid CSV row %d, expected 2 columns (id,keyword), got %d", rowNum+2, len(record))
+		}
+
+		id, err := strconv.ParseUint(record[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("speech: invalid id in row %d: %w", rowNum+2, err)
+		}
+
+		entries = append(entries, NewSpeech(int(id), record[1]))
+	}
+	return entries, nil
+}
+
+// decodeSpeechFile loads all speech entries from speech.mul into mul.Entry3D. When
+// indexOnly is set, the entries only point to their record within the file.
 //
 // The speech.mul file format:
 // For each entry:
 //   - ID (int16, BigEndian)
 //   - Length (int16, BigEndian)
 //   - Text (bytes[Length], UTF-8 encoded)
-func decodeSpeechFile(reader *mmap.File, add mul.AddFn) error {
-	const maxlen = 128
-	buffer := make([]byte, maxlen)
+func decodeSpeechFile(reader *mmap.File, add mul.AddFn, indexOnly bool) error {
+	if indexOnly {
+		return indexSpeechFile(reader, add)
+	}
+
 	for index := uint32(0); ; index++ {
 		head := struct {
 			ID  int16
@@ -94,21 +244,67 @@ func decodeSpeechFile(reader *mmap.File, add mul.AddFn) error {
 			return fmt.Errorf("failed to read speech ID: %w", err)
 		}
 
-		if head.Len = min(maxlen, head.Len); head.Len > 0 {
-			n, err := io.ReadFull(reader, buffer[:head.Len])
-			if err != nil && n != int(head.Len) {
-				return fmt.Errorf("failed to read text for speech ID %d: %w", head.ID, err)
-			}
+		if head.Len < 0 {
+			return fmt.Errorf("invalid (negative) text length %d for speech ID %d", head.Len, head.ID)
 		}
 
-		// Pack the text into a string
-		entry := make([]byte, head.Len+2)
+		// Pack the ID and the text into a single entry
+		entry := make([]byte, int(head.Len)+2)
 		binary.BigEndian.PutUint16(entry[0:2], uint16(head.ID))
-		copy(entry[2:], buffer[:head.Len])
+		if _, err := io.ReadFull(reader, entry[2:]); err != nil {
+			return fmt.Errorf("failed to read text for speech ID %d: %w", head.ID, err)
+		}
 
 		// Add the entry to the index
-		add(index, uint32(head.ID), uint32(head.Len), 0, entry)
+		add(index, uint32(head.ID), uint32(len(entry)), 0, entry)
 	}
 
 	return nil
 }
+
+// indexSpeechFile adds an entry for every record of speech.mul, pointing to the record
+// within the file rather than holding a copy of it
+func indexSpeechFile(file *mmap.File, add mul.AddFn) error {
+	var header [4]byte // ID (2), length (2)
+	offset := 0
+	for index := uint32(0); offset < file.Len(); index++ {
+		if _, err := file.ReadAt(header[:], int64(offset)); err != nil {
+			return fmt.Errorf("failed to read speech entry %d: %w", index, err)
+		}
+
+		length := int16(binary.BigEndian.Uint16(header[2:4]))
+		if length < 0 || offset+4+int(length) > file.Len() {
+			return fmt.Errorf("invalid text length %d for speech entry %d", length, index)
+		}
+
+		add(index, uint32(offset), uint32(4+int(length)), speechRecord, nil)
+		offset += 4 + int(length)
+	}
+	return nil
+}
+
+// WriteSpeech encodes the speech entries into the speech.mul format, in the given order:
+// for each entry, its ID and the length of its keyword (as big-endian 16-bit integers),
+// followed by the keyword itself.
+func WriteSpeech(dst io.Writer, entries []Speech) error {
+	w := bufio.NewWriter(dst)
+	for _, entry := range entries {
+		if len(entry) < 2 {
+			return fmt.Errorf("%w: entry of %d bytes is too short", ErrInvalidSpeechID, len(entry))
+		}
+
+		text := entry[2:]
+		if len(text) > math.MaxInt16 {
+			return fmt.Errorf("speech %d is too long (%d bytes)", entry.ID(), len(text))
+		}
+
+		w.Write(entry[0:2])
+		binary.Write(w, binary.BigEndian, uint16(len(text)))
+		w.Write(text)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write speech: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchSpeech returns the IDs of the speech entries whose keywords are found in the
+// sentence, in ascending order, as servers do to trigger the keywords of NPCs. Matching
+// is case-insensitive and on word boundaries. A keyword starting with "*" may appear
+// after other words, and one ending with "*" may be followed by other words; otherwise
+// the keyword must be at the start or the end of the sentence. For example, "*buy*"
+// matches "I wish to buy", while "hail*" only matches sentences starting with "hail".
+func (s *SDK) MatchSpeech(sentence string) []int {
+	trie := s.speechTrie()
+	input := strings.ToLower(strings.TrimSpace(sentence))
+
+	var ids []int
+	for start := range input {
+		if !isWordBoundary(input, start) {
+			continue
+		}
+
+		// Walk the trie along the sentence, collecting the keywords which end on a word
+		node := trie
+		for i := start; i < len(input) && node != nil; i++ {
+			if node = node.next[input[i]]; node == nil {
+				break
+			}
+
+			end := i + 1
+			if len(node.keywords) == 0 || !isWordBoundary(input, end) {
+				continue
+			}
+
+			for _, keyword := range node.keywords {
+				if (keyword.anyStart || start == 0) && (keyword.anyEnd || end == len(input)) {
+					ids = append(ids, keyword.id)
+				}
+			}
+		}
+	}
+
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// isWordBoundary checks whether the position in the text is not between two letters
+func isWordBoundary(text string, i int) bool {
+	if i <= 0 || i >= len(text) {
+		return true
+	}
+
+	before, _ := utf8.DecodeLastRuneInString(text[:i])
+	after, _ := utf8.DecodeRuneInString(text[i:])
+	return !unicode.IsLetter(before) || !unicode.IsLetter(after)
+}
+
+// speechKeyword is a keyword of a speech entry, without its wildcards
+type speechKeyword struct {
+	id       int  // ID of the speech entry
+	anyStart bool // Whether the keyword may appear after other words
+	anyEnd   bool // Whether the keyword may be followed by other words
+}
+
+// speechNode is a node of the trie of speech keywords, keyed by their bytes
+type speechNode struct {
+	next     map[byte]*speechNode // Children of the node
+	keywords []speechKeyword      // Keywords ending at this node
+}
+
+// insert adds the lower-cased keyword to the trie
+func (n *speechNode) insert(text string, keyword speechKeyword) {
+	node := n
+	for i := 0; i < len(text); i++ {
+		child := node.next[text[i]]
+		if child == nil {
+			child = &speechNode{next: make(map[byte]*speechNode)}
+			node.next[text[i]] = child
+		}
+		node = child
+	}
+	node.keywords = append(node.keywords, keyword)
+}
+
+// speechTrie returns the trie of all speech keywords, building it on first use
+func (s *SDK) speechTrie() *speechNode {
+	if trie := s.speech.Load(); trie != nil {
+		return trie
+	}
+
+	trie := &speechNode{next: make(map[byte]*speechNode)}
+	if _, err := s.loadSpeech(); err == nil {
+		for entry := range s.SpeechEntries() {
+			text := strings.ToLower(strings.TrimSpace(entry.Text()))
+			keyword := speechKeyword{
+				id:       entry.ID(),
+				anyStart: strings.HasPrefix(text, "*"),
+				anyEnd:   strings.HasSuffix(text, "*"),
+			}
+
+			// Keywords with wildcards in the middle match any of their parts
+			for _, part := range strings.Split(strings.Trim(text, "*"), "*") {
+				if part = strings.TrimSpace(part); part != "" {
+					trie.insert(part, keyword)
+				}
+			}
+		}
+	}
+
+	// Another goroutine may have built the trie concurrently, keep the first one
+	s.speech.CompareAndSwap(nil, trie)
+	return s.speech.Load()
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Roman Atachiants and contributors. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for details.
+
+package ultima
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDK_MatchSpeech(t *testing.T) {
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteSpeech(&buffer, []Speech{
+		NewSpeech(0, "*hail*"),
+		NewSpeech(0, "*hello*"),
+		NewSpeech(1, "*vendor buy*"),
+		NewSpeech(2, "*buy*"),
+		NewSpeech(3, "bank"),
+		NewSpeech(4, "guards*"),
+		NewSpeech(5, "*is hier"),
+		NewSpeech(6, "*größe*"),
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "speech.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	tests := []struct {
+		sentence string
+		expect   []int
+	}{
+		{"Hail, traveller!", []int{0}},
+		{"Well hello there", []int{0}},
+		{"hailstorm", nil},
+		{"Vendor BUY", []int{1, 2}},
+		{"I want to buy, vendor", []int{2}},
+		{"buyer", nil},
+		{"bank", []int{3}},
+		{"  Bank  ", []int{3}},
+		{"the bank", nil},
+		{"bank please", nil},
+		{"GUARDS! help!", []int{4}},
+		{"help guards", nil},
+		{"wer is hier", []int{5}},
+		{"wer is hier?", nil},
+		{"is hier jemand", nil},
+		{"welche GRÖSSE", nil},
+		{"welche Größe?", []int{6}},
+		{"", nil},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expect, sdk.MatchSpeech(tc.sentence), tc.sentence)
+	}
+}
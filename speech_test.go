@@ -4,9 +4,14 @@
 package ultima
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSpeech(t *testing.T) {
@@ -46,3 +51,123 @@ func TestSpeech(t *testing.T) {
 		})
 	})
 }
+
+func TestWriteSpeech(t *testing.T) {
+	dir := t.TempDir()
+	entries := []Speech{
+		NewSpeech(0, "*hello*"),
+		NewSpeech(0, "*hail*"),
+		NewSpeech(1, "*vendor buy*"),
+		NewSpeech(2, "a much longer keyword"),
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteSpeech(&buffer, entries))
+	assert.Equal(t, []byte{0, 0, 0, 7, '*', 'h', 'e', 'l', 'l', 'o', '*'}, buffer.Bytes()[:11])
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "speech.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	assert.Equal(t, entries, slices.Collect(sdk.SpeechEntries()))
+	entry, err := sdk.SpeechEntry(2)
+	require.NoError(t, err)
+	assert.Equal(t, entries[2], entry)
+
+	assert.Error(t, WriteSpeech(&buffer, []Speech{{1}}))
+}
+
+func TestSDK_SpeechGroups(t *testing.T) {
+	dir := t.TempDir()
+	var buffer bytes.Buffer
+	require.NoError(t, WriteSpeech(&buffer, []Speech{
+		NewSpeech(3, "*bank*"),
+		NewSpeech(0, "*hail*"),
+		NewSpeech(0, "*hallo*"),
+		NewSpeech(3, "*banco*"),
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "speech.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	groups := slices.Collect(sdk.SpeechGroups())
+	assert.Equal(t, []*SpeechGroup{
+		{ID: 0, Keywords: []string{"*hail*", "*hallo*"}},
+		{ID: 3, Keywords: []string{"*bank*", "*banco*"}},
+	}, groups)
+
+	group, err := sdk.SpeechGroup(3)
+	require.NoError(t, err)
+	assert.Equal(t, groups[1], group)
+
+	_, err = sdk.SpeechGroup(1)
+	assert.Error(t, err)
+}
+
+func TestSDK_SpeechCSV(t *testing.T) {
+	dir := t.TempDir()
+	entries := []Speech{
+		NewSpeech(3, "*bank*"),
+		NewSpeech(0, "*hail, friend*"),
+		NewSpeech(3, "*banco*"),
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteSpeech(&buffer, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "speech.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	data, err := sdk.SpeechToCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "id,keyword\n3,*bank*\n0,\"*hail, friend*\"\n3,*banco*\n", string(data))
+
+	// The imported entries write back the same file
+	imported, err := SpeechFromCSV(data)
+	require.NoError(t, err)
+	assert.Equal(t, entries, imported)
+
+	var output bytes.Buffer
+	require.NoError(t, WriteSpeech(&output, imported))
+	assert.Equal(t, buffer.Bytes(), output.Bytes())
+
+	_, err = SpeechFromCSV([]byte("id,keyword\n70000,*x*\n"))
+	assert.Error(t, err)
+	_, err = SpeechFromCSV([]byte("id,keyword\n1\n"))
+	assert.Error(t, err)
+	_, err = SpeechFromCSV(nil)
+	assert.Error(t, err)
+}
+
+func TestSDK_SpeechIndexOnly(t *testing.T) {
+	dir := t.TempDir()
+	entries := []Speech{
+		NewSpeech(3, "*bank*"),
+		NewSpeech(0, ""),
+		NewSpeech(3, string(bytes.Repeat([]byte("long "), 50))),
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteSpeech(&buffer, entries))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "speech.mul"), buffer.Bytes(), 0644))
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+	sdk.SetSpeechIndexOnly(true)
+
+	assert.Equal(t, entries, slices.Collect(sdk.SpeechEntries()))
+	for index, expect := range entries {
+		entry, err := sdk.SpeechEntry(index)
+		require.NoError(t, err)
+		assert.Equal(t, expect, entry)
+	}
+
+	_, err = sdk.SpeechEntry(3)
+	assert.Error(t, err)
+}
@@ -7,6 +7,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"iter"
+	"math/bits"
+	"strings"
 
 	"codeberg.org/go-mmap/mmap"
 	"github.com/kelindar/ultima-sdk/internal/mul"
@@ -255,6 +258,322 @@ func (s *SDK) staticTileCount() int {
 	return 0x10000
 }
 
+// LandInfos returns an iterator over the tile data of all land tiles, keyed by
+// land tile ID. Unlike Lands(), it only reads tiledata.mul and never decodes art.
+func (s *SDK) LandInfos() iter.Seq2[int, *LandInfo] {
+	return func(yield func(int, *LandInfo) bool) {
+		for id := 0; id < landTileMax; id++ {
+			info, err := s.landInfo(id)
+			if err != nil || info == nil {
+				continue
+			}
+
+			if !yield(id, info) {
+				break
+			}
+		}
+	}
+}
+
+// ItemInfos returns an iterator over the tile data of all static items, keyed by
+// item ID. Unlike Items(), it only reads tiledata.mul and never decodes art.
+func (s *SDK) ItemInfos() iter.Seq2[int, *ItemInfo] {
+	return func(yield func(int, *ItemInfo) bool) {
+		for id := 0; id < s.staticTileCount(); id++ {
+			info, err := s.staticInfo(id)
+			if err != nil || info == nil {
+				continue
+			}
+
+			if !yield(id, info) {
+				break
+			}
+		}
+	}
+}
+
+// FindItems returns the IDs of all static items whose tile data satisfies the
+// given predicate. The tile data is indexed in memory on first use, so repeated
+// queries do not re-read tiledata.mul.
+func (s *SDK) FindItems(fn func(ItemInfo) bool) ([]int, error) {
+	index, err := s.itemIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	return index.find(fn), nil
+}
+
+// ItemsNamed returns the IDs of all static items whose name contains the given
+// text. The comparison is case-insensitive.
+func (s *SDK) ItemsNamed(name string) ([]int, error) {
+	index, err := s.itemIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	return index.named(name), nil
+}
+
+// ItemsWithFlags returns the IDs of all static items that have every one of the
+// specified flags set.
+func (s *SDK) ItemsWithFlags(flags TileFlag) ([]int, error) {
+	index, err := s.itemIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []int
+	for id := range index.withFlags(flags) {
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// ItemsByFlag returns an iterator over the tile data of all static items that have
+// every one of the specified flags set, in the order of their IDs. It is backed by a
+// precomputed bitmap index and never touches art data. The iterator yields nothing if
+// the tile data cannot be loaded; ItemsWithFlags returns the IDs of the same items
+// along with the error.
+func (s *SDK) ItemsByFlag(mask TileFlag) iter.Seq[*ItemInfo] {
+	return func(yield func(*ItemInfo) bool) {
+		index, err := s.itemIndex()
+		if err != nil {
+			return
+		}
+
+		for id := range index.withFlags(mask) {
+			info := index.items[id]
+			if !yield(&info) {
+				break
+			}
+		}
+	}
+}
+
+// TileDiff is a bitmask describing which properties of a tile differ between
+// two versions of the tile data.
+type TileDiff uint8
+
+// Tile difference constants
+const (
+	TileDiffName    TileDiff = 1 << iota // Name has changed
+	TileDiffFlags                        // Flags have changed
+	TileDiffHeight                       // Height has changed (static items only)
+	TileDiffAdded                        // Tile only exists in the newer version
+	TileDiffRemoved                      // Tile only exists in the older version
+)
+
+// TileChange describes a single tile whose data differs between two versions.
+type TileChange struct {
+	ID     int      // Land tile ID, or static item ID if IsLand is false
+	IsLand bool     // Whether the change concerns a land tile
+	Diff   TileDiff // Which properties have changed
+}
+
+// DiffTiledata compares the tile data of two client versions and returns the
+// tiles whose names, flags or heights differ, land tiles first and then static
+// items, both in ascending ID order.
+func DiffTiledata(a, b *SDK) ([]TileChange, error) {
+	lands := make([][]LandInfo, 2)
+	items := make([][]ItemInfo, 2)
+	for i, sdk := range []*SDK{a, b} {
+		for _, info := range sdk.LandInfos() {
+			lands[i] = append(lands[i], *info)
+		}
+
+		index, err := sdk.itemIndex()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = index.items
+	}
+
+	changes := diffLands(lands[0], lands[1])
+	return append(changes, diffItems(items[0], items[1])...), nil
+}
+
+// diffLands compares two sets of land tile data
+func diffLands(a, b []LandInfo) []TileChange {
+	var out []TileChange
+	for id := 0; id < max(len(a), len(b)); id++ {
+		var diff TileDiff
+		switch {
+		case id >= len(a):
+			diff = TileDiffAdded
+		case id >= len(b):
+			diff = TileDiffRemoved
+		default:
+			if a[id].Name != b[id].Name {
+				diff |= TileDiffName
+			}
+			if a[id].Flags != b[id].Flags {
+				diff |= TileDiffFlags
+			}
+		}
+
+		if diff != 0 {
+			out = append(out, TileChange{ID: id, IsLand: true, Diff: diff})
+		}
+	}
+	return out
+}
+
+// diffItems compares two sets of static item data
+func diffItems(a, b []ItemInfo) []TileChange {
+	var out []TileChange
+	for id := 0; id < max(len(a), len(b)); id++ {
+		var diff TileDiff
+		switch {
+		case id >= len(a):
+			diff = TileDiffAdded
+		case id >= len(b):
+			diff = TileDiffRemoved
+		default:
+			if a[id].Name != b[id].Name {
+				diff |= TileDiffName
+			}
+			if a[id].Flags != b[id].Flags {
+				diff |= TileDiffFlags
+			}
+			if a[id].Height != b[id].Height {
+				diff |= TileDiffHeight
+			}
+		}
+
+		if diff != 0 {
+			out = append(out, TileChange{ID: id, Diff: diff})
+		}
+	}
+	return out
+}
+
+// itemIndex is an in-memory index over the static tile data
+type itemIndex struct {
+	items []ItemInfo   // Tile data, indexed by item ID
+	names []string     // Lower-cased item names, indexed by item ID
+	flags [64][]uint64 // Bitmap of item IDs for each flag bit
+}
+
+// newItemIndex builds the name and flag indices over the given tile data
+func newItemIndex(items []ItemInfo) *itemIndex {
+	index := &itemIndex{
+		items: items,
+		names: make([]string, len(items)),
+	}
+
+	words := (len(items) + 63) / 64
+	for id, info := range items {
+		index.names[id] = strings.ToLower(info.Name)
+		for bit := 0; bit < 64; bit++ {
+			if info.Flags&(1<<bit) == 0 {
+				continue
+			}
+
+			if index.flags[bit] == nil {
+				index.flags[bit] = make([]uint64, words)
+			}
+			index.flags[bit][id>>6] |= 1 << (id & 63)
+		}
+	}
+	return index
+}
+
+// itemIndex returns the static tile data index, building it on first use
+func (s *SDK) itemIndex() (*itemIndex, error) {
+	if index := s.items.Load(); index != nil {
+		return index, nil
+	}
+
+	file, err := s.loadTiledata()
+	if err != nil {
+		return nil, err
+	}
+
+	count := s.staticTileCount()
+	items := make([]ItemInfo, 0, count)
+	for id := 0; id < count; id++ {
+		info, err := uofile.Decode(file, uint32(id), decodeStaticInfo)
+		if err != nil || info == nil {
+			break // Older clients have fewer static tiles
+		}
+
+		items = append(items, *info)
+	}
+
+	// Another goroutine may have built the index concurrently, keep the first one
+	s.items.CompareAndSwap(nil, newItemIndex(items))
+	return s.items.Load(), nil
+}
+
+// withFlags returns an iterator over the IDs of all items having every flag of the mask
+func (idx *itemIndex) withFlags(mask TileFlag) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if mask == 0 {
+			for id := range idx.items {
+				if !yield(id) {
+					return
+				}
+			}
+			return
+		}
+
+		// Intersect the bitmaps of every flag in the mask
+		var match []uint64
+		for bit := 0; bit < 64; bit++ {
+			if mask&(1<<bit) == 0 {
+				continue
+			}
+
+			bitmap := idx.flags[bit]
+			if bitmap == nil {
+				return // No item has this flag
+			}
+
+			if match == nil {
+				match = append([]uint64(nil), bitmap...)
+				continue
+			}
+
+			for i := range match {
+				match[i] &= bitmap[i]
+			}
+		}
+
+		for i, word := range match {
+			for ; word != 0; word &= word - 1 {
+				if !yield(i<<6 | bits.TrailingZeros64(word)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// find returns the IDs of all items matching the predicate
+func (idx *itemIndex) find(fn func(ItemInfo) bool) []int {
+	var out []int
+	for id := range idx.items {
+		if fn(idx.items[id]) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// named returns the IDs of all items whose name contains the text
+func (idx *itemIndex) named(name string) []int {
+	name = strings.ToLower(name)
+
+	var out []int
+	for id, n := range idx.names {
+		if strings.Contains(n, name) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
 // decodeTileDataFile loads the tiledata.mul file and populates the internal
 // data structures for land and static tiles
 func decodeTileDataFile(file *mmap.File, add mul.AddFn) error {
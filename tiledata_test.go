@@ -4,9 +4,11 @@
 package ultima
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTileData(t *testing.T) {
@@ -42,7 +44,166 @@ func TestTileData(t *testing.T) {
 			assert.Error(t, err)
 		})
 
+		t.Run("LandInfos", func(t *testing.T) {
+			count := 0
+			for id, info := range sdk.LandInfos() {
+				assert.Less(t, id, 0x4000)
+				assert.NotNil(t, info)
+				count++
+			}
+			assert.Equal(t, 0x4000, count)
+		})
+
+		t.Run("ItemInfos", func(t *testing.T) {
+			count := 0
+			for id, info := range sdk.ItemInfos() {
+				assert.Equal(t, count, id)
+				assert.NotNil(t, info)
+				if count++; count >= 100 {
+					break
+				}
+			}
+			assert.Equal(t, 100, count)
+		})
+
+		t.Run("ItemsNamed", func(t *testing.T) {
+			ids, err := sdk.ItemsNamed("CHAIR")
+			assert.NoError(t, err)
+			assert.NotEmpty(t, ids)
+
+			for _, id := range ids {
+				info, err := sdk.staticInfo(id)
+				assert.NoError(t, err)
+				assert.Contains(t, strings.ToLower(info.Name), "chair")
+			}
+		})
+
+		t.Run("ItemsWithFlags", func(t *testing.T) {
+			ids, err := sdk.ItemsWithFlags(TileFlagContainer)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, ids)
+
+			for _, id := range ids {
+				info, err := sdk.staticInfo(id)
+				assert.NoError(t, err)
+				assert.True(t, info.IsContainer())
+			}
+		})
+
+		t.Run("ItemsByFlag", func(t *testing.T) {
+			count := 0
+			for info := range sdk.ItemsByFlag(TileFlagWearable) {
+				_, ok := info.IsWearable()
+				assert.True(t, ok, "item %q should be wearable", info.Name)
+				count++
+			}
+			assert.Greater(t, count, 0)
+		})
+
+		t.Run("FindItems", func(t *testing.T) {
+			ids, err := sdk.FindItems(func(info ItemInfo) bool {
+				return info.Height > 20
+			})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, ids)
+		})
+
+	})
+}
+
+func TestItemIndex(t *testing.T) {
+	index := newItemIndex([]ItemInfo{
+		{Name: "Wooden Chair", Flags: TileFlagSurface},
+		{Name: "chest", Flags: TileFlagContainer | TileFlagSurface},
+		{Name: "Chair Cushion", Flags: TileFlagNone},
+		{Name: "lantern", Flags: TileFlagLightSource | TileFlagUseNewArt},
 	})
+
+	assert.Equal(t, []int{0, 2}, index.named("Chair"))
+	assert.Empty(t, index.named("table"))
+	assert.Equal(t, []int{0, 1}, index.find(func(info ItemInfo) bool {
+		return info.Surface()
+	}))
+
+	collect := func(mask TileFlag) (out []int) {
+		for id := range index.withFlags(mask) {
+			out = append(out, id)
+		}
+		return
+	}
+
+	assert.Equal(t, []int{0, 1}, collect(TileFlagSurface))
+	assert.Equal(t, []int{1}, collect(TileFlagSurface|TileFlagContainer))
+	assert.Equal(t, []int{3}, collect(TileFlagUseNewArt))
+	assert.Equal(t, []int{0, 1, 2, 3}, collect(TileFlagNone))
+	assert.Empty(t, collect(TileFlagWeapon))
+	assert.Empty(t, collect(TileFlagContainer|TileFlagLightSource))
+}
+
+func TestSDK_ItemsByFlag(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTiledata(t, dir, nil, map[int]ItemInfo{
+		0x10: {Name: "chest", Flags: TileFlagContainer},
+		0x11: {Name: "lantern", Flags: TileFlagLightSource},
+		0x12: {Name: "crate", Flags: TileFlagContainer | TileFlagSurface},
+	})
+
+	sdk, err := Open(dir)
+	require.NoError(t, err)
+	defer sdk.Close()
+
+	var names []string
+	for info := range sdk.ItemsByFlag(TileFlagContainer) {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{"chest", "crate"}, names)
+
+	// Without tile data, the iterator is empty and the error is reported by ItemsWithFlags
+	empty, err := Open(t.TempDir())
+	require.NoError(t, err)
+	defer empty.Close()
+
+	for range empty.ItemsByFlag(TileFlagContainer) {
+		assert.Fail(t, "no item expected")
+	}
+	_, err = empty.ItemsWithFlags(TileFlagContainer)
+	assert.Error(t, err)
+}
+
+func TestDiffTiledata(t *testing.T) {
+	runWith(t, func(sdk *SDK) {
+		changes, err := DiffTiledata(sdk, sdk)
+		assert.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestDiffItems(t *testing.T) {
+	a := []ItemInfo{
+		{Name: "chair", Flags: TileFlagSurface, Height: 2},
+		{Name: "table", Flags: TileFlagSurface, Height: 6},
+		{Name: "lamp", Flags: TileFlagLightSource, Height: 4},
+	}
+	b := []ItemInfo{
+		{Name: "chair", Flags: TileFlagSurface, Height: 2},
+		{Name: "desk", Flags: TileFlagSurface, Height: 8},
+		{Name: "lamp", Flags: TileFlagNone, Height: 4},
+		{Name: "rug"},
+	}
+
+	assert.Equal(t, []TileChange{
+		{ID: 1, Diff: TileDiffName | TileDiffHeight},
+		{ID: 2, Diff: TileDiffFlags},
+		{ID: 3, Diff: TileDiffAdded},
+	}, diffItems(a, b))
+
+	assert.Equal(t, []TileChange{
+		{ID: 0, IsLand: true, Diff: TileDiffName},
+		{ID: 1, IsLand: true, Diff: TileDiffRemoved},
+	}, diffLands(
+		[]LandInfo{{Name: "grass"}, {Name: "dirt"}},
+		[]LandInfo{{Name: "jungle"}},
+	))
 }
 
 // Test for the helper functions